@@ -0,0 +1,10 @@
+// Package client holds the server's page templates and static files,
+// embedded so the binary doesn't depend on its working directory.
+package client
+
+import "embed"
+
+// Files is base.html, a template per page and static/.
+//
+//go:embed *.html static
+var Files embed.FS
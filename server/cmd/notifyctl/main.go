@@ -0,0 +1,468 @@
+// Command notifyctl manages users, notifications and subscriptions without
+// going through mysql by hand.  it takes the server's settings, flags, NOTIFY_*
+// env or -config, and runs against the same store through the controllers, so
+// it's validated like the api is:
+//
+//	notifyctl -config notify.yaml subscriptions list +15555550100
+//
+// a scheduled send twilio fails is kept as a dead letter, dead-letters list
+// shows them and dead-letters replay sends them again.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	gpb "github.com/golang/protobuf/ptypes/empty"
+	"github.com/mikerjacobi/notify-app/server/controllers"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const usage = `usage: notifyctl [settings] <command>
+
+commands:
+  users create -phone <number> -name <name> -birthday <YYYY-MM-DD>
+      the password is read from stdin
+  users verify <number>
+  notifications list
+  notifications create -type prompt|reminder -name <name> -template <text> [-default-tag <tag>]
+  notifications edit -id <notification id> [-name <name>] [-template <text>] [-default-tag <tag>]
+  subscriptions list <number>
+  subscriptions add|edit -phone <number> -notification <id> -next <time> [-frequency <1d>]
+  subscriptions delete|restore -phone <number> -notification <id>
+  trigger <number>
+  dead-letters list [number]
+  dead-letters replay <comms id>...
+  send-test <number> [message]
+  export -phone <number> [-format archive] [-group-by month] [-after <date>] [-before <date>] [-o file]
+  encrypt
+      rewraps data keys after a master key rotation, and seals and indexes
+      what's left from before enc:v2; safe to stop and run again
+  migrate up | down [steps] | status | baseline <version>
+
+settings are the server's, run notifyctl -h to list them.`
+
+// command runs one of the commands against the server.
+type command func(ctx context.Context, c *controllers.NotifyAppServer, args []string) error
+
+var commands = map[string]command{
+	"users create":          createUser,
+	"users verify":          verifyUser,
+	"notifications list":    listNotifications,
+	"notifications create":  createNotification,
+	"notifications edit":    editNotification,
+	"subscriptions list":    listSubscriptions,
+	"subscriptions add":     addSubscription,
+	"subscriptions edit":    editSubscription,
+	"subscriptions delete":  deleteSubscription,
+	"subscriptions restore": restoreSubscription,
+	"trigger":               trigger,
+	"dead-letters list":     listDeadLetters,
+	"dead-letters replay":   replayDeadLetters,
+	"send-test":             sendTest,
+	"export":                export,
+	"encrypt":               encrypt,
+}
+
+func main() {
+	config, args, err := controllers.LoadConfiguration(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	} else if err != nil {
+		logrus.Fatalf("failed to load configuration: %s", err)
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	//migrate only needs the store, it mustn't depend on the rest being set up
+	if args[0] == "migrate" {
+		if err := controllers.Migrate(config, args[1:], os.Stdout); err != nil {
+			logrus.Fatalf("failed to migrate: %+v", err)
+		}
+		return
+	}
+
+	name, cmd, rest := lookup(args)
+	if cmd == nil {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	c, err := controllers.NewNotifyAppServer(config)
+	if err != nil {
+		logrus.Fatalf("failed to initialize notify service: %+v", err)
+	}
+	ctx := controllers.AdminContext(controllers.WithRequestID(context.Background(), "notifyctl-"+uuid.NewV4().String()))
+	err = cmd(ctx, c, rest)
+	if shutdownErr := c.Shutdown(ctx); shutdownErr != nil {
+		logrus.Errorf("failed to shut down notify service: %s", shutdownErr)
+	}
+	if err != nil {
+		logrus.Fatalf("failed to %s: %s", name, err)
+	}
+}
+
+// lookup finds the command args start with, one word or a noun and a verb,
+// and returns the args after it.
+func lookup(args []string) (string, command, []string) {
+	if len(args) > 1 {
+		name := args[0] + " " + args[1]
+		if cmd, ok := commands[name]; ok {
+			return name, cmd, args[2:]
+		}
+	}
+	cmd := commands[args[0]]
+	return args[0], cmd, args[1:]
+}
+
+// parse parses a command's flags, and returns its positional args, which must
+// number want.
+func parse(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	fs.SetOutput(ioutil.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != want {
+		return nil, fmt.Errorf("expected %d arguments, got %d, see notifyctl's usage", want, fs.NArg())
+	}
+	return fs.Args(), nil
+}
+
+func table(header string, rows func(w *tabwriter.Writer)) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, header)
+	rows(w)
+	return w.Flush()
+}
+
+func createUser(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	fs := flag.NewFlagSet("users create", flag.ContinueOnError)
+	user := &pb.User{}
+	fs.StringVar(&user.PhoneNumber, "phone", "", "")
+	fs.StringVar(&user.Name, "name", "", "")
+	fs.StringVar(&user.Birthday, "birthday", "", "")
+	region := fs.String("region", "", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	//read from stdin so the password isn't left in shell history or ps
+	fmt.Fprint(os.Stderr, "password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return errors.Wrap(err, "failed to read password")
+	}
+	user.Password = strings.TrimRight(password, "\r\n")
+
+	if err := c.CreateUser(ctx, user, *region); err != nil {
+		return err
+	}
+	fmt.Printf("created %s, verify them with: notifyctl users verify %s\n", user.PhoneNumber, user.PhoneNumber)
+	return nil
+}
+
+func verifyUser(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	args, err := parse(flag.NewFlagSet("users verify", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	if err := c.VerifyUser(ctx, args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("verified %s\n", args[0])
+	return nil
+}
+
+func listNotifications(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	if _, err := parse(flag.NewFlagSet("notifications list", flag.ContinueOnError), args, 0); err != nil {
+		return err
+	}
+	resp, err := c.ListNotifications(ctx, &gpb.Empty{})
+	if err != nil {
+		return err
+	}
+	return table("ID\tTYPE\tNAME\tDEFAULT TAG\tTEMPLATE", func(w *tabwriter.Writer) {
+		for _, n := range resp.Notifications {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%q\n", n.NotificationId, n.Type, n.Name, n.DefaultTag, n.Template)
+		}
+	})
+}
+
+func createNotification(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	fs := flag.NewFlagSet("notifications create", flag.ContinueOnError)
+	n := &pb.Notification{}
+	fs.StringVar(&n.Type, "type", "", "")
+	fs.StringVar(&n.Name, "name", "", "")
+	fs.StringVar(&n.Template, "template", "", "")
+	fs.StringVar(&n.DefaultTag, "default-tag", "", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	n, err := c.CreateNotification(ctx, n)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created %s %s\n", n.Type, n.NotificationId)
+	return nil
+}
+
+// editNotification changes only the fields given, UpdateNotification saves
+// them all.
+func editNotification(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	fs := flag.NewFlagSet("notifications edit", flag.ContinueOnError)
+	id := fs.String("id", "", "")
+	name := fs.String("name", "", "")
+	template := fs.String("template", "", "")
+	defaultTag := fs.String("default-tag", "", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	resp, err := c.ListNotifications(ctx, &gpb.Empty{})
+	if err != nil {
+		return err
+	}
+	var n *pb.Notification
+	for _, existing := range resp.Notifications {
+		if existing.NotificationId == *id {
+			n = existing
+		}
+	}
+	if n == nil {
+		return fmt.Errorf("notification '%s' not found", *id)
+	}
+	if set["name"] {
+		n.Name = *name
+	}
+	if set["template"] {
+		n.Template = *template
+	}
+	if set["default-tag"] {
+		n.DefaultTag = *defaultTag
+	}
+	if _, err := c.UpdateNotification(ctx, n); err != nil {
+		return err
+	}
+	fmt.Printf("updated %s\n", n.NotificationId)
+	return nil
+}
+
+func listSubscriptions(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	args, err := parse(flag.NewFlagSet("subscriptions list", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	resp, err := c.ListUserNotifications(ctx, &pb.ListUserNotificationsReq{PhoneNumber: args[0]})
+	if err != nil {
+		return err
+	}
+	return table("NOTIFICATION\tNAME\tNEXT\tFREQUENCY", func(w *tabwriter.Writer) {
+		for _, up := range resp.UserNotifications {
+			name := ""
+			if up.Notification != nil {
+				name = up.Notification.Name
+			}
+			frequency := up.Frequency
+			if frequency == "" {
+				frequency = "once"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", up.NotificationId, name, up.NextNotificationTime, frequency)
+		}
+	})
+}
+
+// subscriptionFlags parses the flags naming a user notification, and its
+// schedule if schedule is set.
+func subscriptionFlags(name string, args []string, schedule bool) (*pb.UserNotification, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	up := &pb.UserNotification{}
+	fs.StringVar(&up.PhoneNumber, "phone", "", "")
+	fs.StringVar(&up.NotificationId, "notification", "", "")
+	if schedule {
+		fs.StringVar(&up.NextNotificationTime, "next", "", "")
+		fs.StringVar(&up.Frequency, "frequency", "", "")
+	}
+	if _, err := parse(fs, args, 0); err != nil {
+		return nil, err
+	}
+	//the admin has no number of its own to fall back on
+	if up.PhoneNumber == "" || up.NotificationId == "" {
+		return nil, fmt.Errorf("-phone and -notification are required")
+	}
+	return up, nil
+}
+
+func addSubscription(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	up, err := subscriptionFlags("subscriptions add", args, true)
+	if err != nil {
+		return err
+	}
+	if _, err := c.AddUserNotification(ctx, up); err != nil {
+		return err
+	}
+	fmt.Printf("subscribed %s to %s\n", up.PhoneNumber, up.NotificationId)
+	return nil
+}
+
+func editSubscription(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	up, err := subscriptionFlags("subscriptions edit", args, true)
+	if err != nil {
+		return err
+	}
+	if up, err = c.UpdateUserNotification(ctx, up); err != nil {
+		return err
+	}
+	fmt.Printf("%s next sends %s at %s\n", up.PhoneNumber, up.NotificationId, up.NextNotificationTime)
+	return nil
+}
+
+func deleteSubscription(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	up, err := subscriptionFlags("subscriptions delete", args, false)
+	if err != nil {
+		return err
+	}
+	if _, err := c.DeleteUserNotification(ctx, up); err != nil {
+		return err
+	}
+	fmt.Printf("moved %s's %s to the trash\n", up.PhoneNumber, up.NotificationId)
+	return nil
+}
+
+func restoreSubscription(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	up, err := subscriptionFlags("subscriptions restore", args, false)
+	if err != nil {
+		return err
+	}
+	if _, err := c.RestoreUserNotification(ctx, up); err != nil {
+		return err
+	}
+	fmt.Printf("restored %s's %s\n", up.PhoneNumber, up.NotificationId)
+	return nil
+}
+
+func trigger(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	args, err := parse(flag.NewFlagSet("trigger", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	sent, err := c.TriggerUserNotifications(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("sent %d due notifications to %s\n", sent, args[0])
+	return nil
+}
+
+func listDeadLetters(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("expected at most a number, see notifyctl's usage")
+	}
+	phoneNumber := ""
+	if len(args) == 1 {
+		phoneNumber = args[0]
+	}
+	dead, err := c.ListDeadLetters(ctx, phoneNumber)
+	if err != nil {
+		return err
+	}
+	return table("COMMS ID\tTO\tNOTIFICATION\tCREATED\tERROR", func(w *tabwriter.Writer) {
+		for _, d := range dead {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.CommsId, d.To, d.NotificationId, d.Created, d.Error)
+		}
+	})
+}
+
+func replayDeadLetters(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected the comms ids to replay, see notifyctl's usage")
+	}
+	failed := 0
+	for _, commsID := range args {
+		if err := c.ReplayDeadLetter(ctx, commsID); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", commsID, err)
+			failed++
+			continue
+		}
+		fmt.Printf("replayed %s\n", commsID)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d dead letters weren't replayed", failed, len(args))
+	}
+	return nil
+}
+
+func sendTest(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("expected a number and optionally a message, see notifyctl's usage")
+	}
+	msg := ""
+	if len(args) == 2 {
+		msg = args[1]
+	}
+	if err := c.SendTestMessage(ctx, args[0], msg); err != nil {
+		return err
+	}
+	fmt.Printf("sent a test message to %s\n", args[0])
+	return nil
+}
+
+func export(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	req := &pb.ExportReq{}
+	fs.StringVar(&req.PhoneNumber, "phone", "", "")
+	fs.StringVar(&req.Format, "format", "archive", "")
+	fs.StringVar(&req.GroupBy, "group-by", "", "")
+	fs.StringVar(&req.CreatedAfter, "after", "", "")
+	fs.StringVar(&req.CreatedBefore, "before", "", "")
+	out := fs.String("o", "", "")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if req.PhoneNumber == "" {
+		return fmt.Errorf("-phone is required")
+	}
+
+	//written to a temp file, the name comes with the export
+	f, err := ioutil.TempFile(".", ".export-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create export")
+	}
+	defer os.Remove(f.Name())
+	filename, err := c.WriteExport(ctx, f, req)
+	if cerr := f.Close(); err == nil {
+		err = errors.Wrap(cerr, "failed to write export")
+	}
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = filename
+	}
+	if err := os.Rename(f.Name(), *out); err != nil {
+		return errors.Wrap(err, "failed to write export")
+	}
+	fmt.Printf("wrote %s\n", *out)
+	return nil
+}
+
+func encrypt(ctx context.Context, c *controllers.NotifyAppServer, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("expected no arguments, see notifyctl's usage")
+	}
+	n, err := c.EncryptExisting(ctx)
+	fmt.Printf("encrypted %d rows\n", n)
+	return err
+}
@@ -2,11 +2,14 @@ package controllers
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
 	"net/http"
+	"strings"
 
-	"github.com/sirupsen/logrus"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/twitchtv/twirp"
 )
 
 type authSession struct {
@@ -18,7 +21,7 @@ func (s *NotifyAppServer) AuthMiddleware(f http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		sessionCookie, err := r.Cookie("session")
 		if err != nil {
-			logrus.Errorf("failed to get session cookie: %s", err)
+			Logger(r.Context()).Errorf("failed to get session cookie: %s", err)
 			http.Redirect(w, r, "/login", http.StatusFound)
 			return
 		} else if sessionCookie == nil {
@@ -29,7 +32,7 @@ func (s *NotifyAppServer) AuthMiddleware(f http.HandlerFunc) http.HandlerFunc {
 
 		sessionValue, err := base64.StdEncoding.DecodeString(sessionCookie.Value)
 		if err != nil {
-			logrus.Errorf("failed to decode session: %s", err)
+			Logger(r.Context()).Errorf("failed to decode session: %s", err)
 			http.Redirect(w, r, "/login", http.StatusFound)
 			return
 		}
@@ -40,20 +43,139 @@ func (s *NotifyAppServer) AuthMiddleware(f http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		ctx := context.Background()
-		user, err := s.getUser(ctx, s.DB, session.PhoneNumber)
+		ctx := r.Context()
+		user, err := s.repo.GetUser(ctx, session.PhoneNumber)
 		if err != nil {
-			logrus.Errorf("failed to get user: %s", err)
+			Logger(ctx).Errorf("failed to get user: %s", err)
 			http.Redirect(w, r, "/login", http.StatusFound)
 			return
 		}
 		if user.SessionId != session.ID {
-			logrus.Errorf("session mismatch: '%s' != '%s'", user.SessionId, session.ID)
+			Logger(ctx).Errorf("session mismatch: '%s' != '%s'", user.SessionId, session.ID)
 			http.Redirect(w, r, "/login", http.StatusFound)
 			return
 		}
 
+		if !Contains(safeMethods, r.Method) && !validCSRFToken(r, user) {
+			Logger(ctx).Errorf("csrf token mismatch for %s %s", r.Method, r.URL.Path)
+			http.Error(w, "invalid csrf token", http.StatusForbidden)
+			return
+		}
+
 		ctx = context.WithValue(ctx, userKey, user)
+		setLogUser(ctx, user.PhoneNumber)
 		f(w, r.WithContext(ctx))
 	}
 }
+
+// safeMethods don't change state, so they skip the csrf check
+var safeMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+
+// validCSRFToken checks the token sent in the X-CSRF-Token header by fetch
+// calls, or in the csrf_token field by forms, against the session's token.
+func validCSRFToken(r *http.Request, user *pb.User) bool {
+	token := r.Header.Get("X-CSRF-Token")
+	if token == "" {
+		token = r.PostFormValue("csrf_token")
+	}
+	if token == "" || user.CsrfToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(user.CsrfToken)) == 1
+}
+
+const adminRole = "admin"
+
+var (
+	// publicMethods may be called without an api token
+	publicMethods = []string{"CreateAccount"}
+	// adminMethods may only be called with an admin's api token
+	adminMethods = []string{"TriggerNotifications", "CreateNotification", "UpdateNotification"}
+)
+
+// ApiTokenMiddleware copies the bearer token from the Authorization header
+// into the request context, where the twirp hooks can see it, along with the
+// url the request reached us at for rpcs that answer with links.
+func (s *NotifyAppServer) ApiTokenMiddleware(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), baseURLKey, s.baseURL(r))
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			f(w, r.WithContext(ctx))
+			return
+		}
+		token := strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+		ctx = context.WithValue(ctx, apiTokenKey, token)
+		f(w, r.WithContext(ctx))
+	}
+}
+
+// TwirpHooks authenticates each twirp call by its api token, and puts the
+// token's user in the context under the same key AuthMiddleware uses.
+func (s *NotifyAppServer) TwirpHooks() *twirp.ServerHooks {
+	return &twirp.ServerHooks{
+		RequestReceived: twirpRequestReceived,
+		RequestRouted: func(ctx context.Context) (context.Context, error) {
+			return s.authenticateTwirp(startTwirpSpan(ctx))
+		},
+		Error: func(ctx context.Context, err twirp.Error) context.Context {
+			twirpSpanError(ctx, err)
+			return twirpError(ctx, err)
+		},
+		ResponseSent: func(ctx context.Context) {
+			twirpResponseSent(ctx)
+			endTwirpSpan(ctx)
+		},
+	}
+}
+
+func (s *NotifyAppServer) authenticateTwirp(ctx context.Context) (context.Context, error) {
+	method, _ := twirp.MethodName(ctx)
+	if Contains(publicMethods, method) {
+		return ctx, nil
+	}
+
+	user, err := s.apiTokenUser(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	if Contains(adminMethods, method) && user.Role != adminRole {
+		return ctx, twirp.NewError(twirp.PermissionDenied, "admin role required")
+	}
+	setLogUser(ctx, user.PhoneNumber)
+	return context.WithValue(ctx, userKey, user), nil
+}
+
+// apiTokenUser returns the owner of the api token ApiTokenMiddleware found.
+func (s *NotifyAppServer) apiTokenUser(ctx context.Context) (*pb.User, error) {
+	token, ok := ctx.Value(apiTokenKey).(string)
+	if !ok || token == "" {
+		return nil, twirp.NewError(twirp.Unauthenticated, "api token required")
+	}
+	user, err := s.repo.GetApiTokenUser(ctx, hashApiToken(token))
+	if err != nil {
+		Logger(ctx).Errorf("failed to get api token user: %s", err)
+		return nil, twirp.NewError(twirp.Unauthenticated, "invalid api token")
+	}
+	return user, nil
+}
+
+// authorizePhoneNumber returns the phone number an rpc should act on.  callers
+// act on their own number unless they are an admin.
+func (s *NotifyAppServer) authorizePhoneNumber(ctx context.Context, phoneNumber string) (string, error) {
+	user, ok := ctx.Value(userKey).(*pb.User)
+	if !ok {
+		return "", twirp.NewError(twirp.Unauthenticated, "api token required")
+	}
+	if phoneNumber == "" {
+		return user.PhoneNumber, nil
+	}
+	phoneNumber, err := normalizePhoneNumber(phoneNumber, s.config.DefaultRegion)
+	if err != nil {
+		return "", twirp.InvalidArgumentError("phone_number", "invalid")
+	}
+	if phoneNumber != user.PhoneNumber && user.Role != adminRole {
+		return "", twirp.NewError(twirp.PermissionDenied, "phone_number belongs to another user")
+	}
+	return phoneNumber, nil
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+)
+
+func TestValidCSRFToken(t *testing.T) {
+	const token = "5f0c3a52-4a0e-4d1b-9d3f-2f1b8c6e7a90"
+	cases := []struct {
+		name   string
+		header string
+		form   string
+		// session is the user's token, "" if they have none
+		session string
+		want    bool
+	}{
+		{name: "missing", session: token},
+		{name: "wrong header", header: "nope", session: token},
+		{name: "wrong form field", form: "nope", session: token},
+		{name: "right header", header: token, session: token, want: true},
+		{name: "right form field", form: token, session: token, want: true},
+		{name: "header wins over form field", header: "nope", form: token, session: token},
+		{name: "no session token", header: token},
+		{name: "both empty"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			form := url.Values{}
+			if c.form != "" {
+				form.Set("csrf_token", c.form)
+			}
+			r := httptest.NewRequest("POST", "/journal", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if c.header != "" {
+				r.Header.Set("X-CSRF-Token", c.header)
+			}
+			if got := validCSRFToken(r, &pb.User{CsrfToken: c.session}); got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mikerjacobi/notify-app/server/repository"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminContext runs rpcs in process as an admin, the way notifyctl calls
+// them.  the admin has no number of its own, so every call names one.
+func AdminContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, userKey, &pb.User{Role: adminRole})
+}
+
+// CreateUser adds an account like CreateAccount does, without texting the
+// register prompt.  it's left unverified until VerifyUser.
+func (s *NotifyAppServer) CreateUser(ctx context.Context, user *pb.User, region string) error {
+	req := &pb.CreateAccountReq{User: user, PasswordRepeat: user.Password, Region: region}
+	if _, err := s.validateCreateAccount(ctx, req); err != nil {
+		return err
+	}
+	return errors.Wrap(s.insertUser(ctx, user), "failed to insert user")
+}
+
+// VerifyUser verifies a user as if they'd texted back to register, which
+// texts them the reg ack.
+func (s *NotifyAppServer) VerifyUser(ctx context.Context, phoneNumber string) error {
+	phoneNumber, err := normalizePhoneNumber(phoneNumber, s.config.DefaultRegion)
+	if err != nil {
+		return err
+	}
+	user, err := s.repo.GetUser(ctx, phoneNumber)
+	if err != nil {
+		return errors.Wrap(err, "failed to get user")
+	}
+	return s.verifyUser(ctx, user)
+}
+
+// TriggerUserNotifications sends one user's due notifications now, the same
+// way the scheduler would, and returns how many were sent.
+func (s *NotifyAppServer) TriggerUserNotifications(ctx context.Context, phoneNumber string) (int, error) {
+	phoneNumber, err := normalizePhoneNumber(phoneNumber, s.config.DefaultRegion)
+	if err != nil {
+		return 0, err
+	}
+	due, err := s.repo.ListDueUserNotifications(ctx, s.now(ctx))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get user notifications")
+	}
+	sent := 0
+	for _, notification := range due {
+		if notification.PhoneNumber != phoneNumber {
+			continue
+		}
+		if err := s.handleUserNotification(ctx, notification); err != nil {
+			Logger(ctx).WithFields(logrus.Fields{
+				"phone_number":    notification.PhoneNumber,
+				"notification_id": notification.NotificationId,
+			}).Warnf("failed to handle user notification: %s", err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// SendTestMessage texts msg to a number, and records it like any other send,
+// to check twilio is set up.
+func (s *NotifyAppServer) SendTestMessage(ctx context.Context, phoneNumber, msg string) error {
+	phoneNumber, err := normalizePhoneNumber(phoneNumber, s.config.DefaultRegion)
+	if err != nil {
+		return err
+	}
+	if msg == "" {
+		msg = "test message from notify, sent " + time.Now().Format(time.RFC1123)
+	}
+	if err := s.sendSMS(ctx, phoneNumber, msg); err != nil {
+		return errors.Wrap(err, "failed to send sms")
+	}
+	comm := &pb.Communication{From: s.config.From, To: phoneNumber, Message: msg}
+	if err := s.insertCommunication(ctx, comm); err != nil {
+		Logger(ctx).Warnf("failed to insert comms: %s", err)
+	}
+	return nil
+}
+
+// ListDeadLetters returns the scheduled sends that failed, to one number if
+// it's set.
+func (s *NotifyAppServer) ListDeadLetters(ctx context.Context, phoneNumber string) ([]*pb.Communication, error) {
+	if phoneNumber != "" {
+		var err error
+		if phoneNumber, err = normalizePhoneNumber(phoneNumber, s.config.DefaultRegion); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.ListDeadLetters(ctx, phoneNumber)
+}
+
+// ReplayDeadLetter sends a failed send again.  it's marked replayed first so
+// it's only sent once, and the new send is recorded, as a dead letter itself
+// if it fails too.
+func (s *NotifyAppServer) ReplayDeadLetter(ctx context.Context, commsID string) error {
+	dead, err := s.repo.ClaimDeadLetter(ctx, commsID)
+	if errors.Cause(err) == errNotFound {
+		return fmt.Errorf("'%s' isn't a dead letter", commsID)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to claim dead letter")
+	}
+
+	comm := &pb.Communication{From: dead.From, To: dead.To, Message: dead.Message, NotificationId: dead.NotificationId}
+	sendErr := s.sendSMS(ctx, dead.To, dead.Message)
+	if sendErr != nil {
+		comm.Status, comm.Error = repository.CommsFailed, sendErr.Error()
+	}
+	if err := s.insertCommunication(ctx, comm); err != nil {
+		if sendErr != nil {
+			return errors.Wrapf(err, "failed to send sms (%s) and to insert its dead letter", sendErr)
+		}
+		Logger(ctx).Warnf("failed to insert comms: %s", err)
+	}
+	return errors.Wrap(sendErr, "failed to send sms")
+}
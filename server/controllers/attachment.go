@@ -0,0 +1,297 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/husobee/vestigo"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// attachmentTypes are the media types we keep, all of them can be
+	// decoded for a thumbnail
+	attachmentTypes = []string{"image/jpeg", "image/png", "image/gif"}
+	// maxAttachmentSize matches twilio's 5MB limit on inbound mms
+	maxAttachmentSize int64 = 5 << 20
+	// twilio sends at most 10 media per message
+	maxMediaPerMessage = 10
+	thumbnailSize      = 200
+	// maxImagePixels caps the width*height we'll decode, an image's header
+	// can claim far more pixels than its bytes hold
+	maxImagePixels int64 = 50 << 20
+	maxMediaJobs         = 4
+)
+
+type inboundMedia struct {
+	URL         string
+	ContentType string
+}
+
+// parseInboundMedia reads twilio's NumMedia and MediaUrlN/MediaContentTypeN
+// fields, which don't fit a schema struct.
+func parseInboundMedia(form url.Values) []inboundMedia {
+	n, err := strconv.Atoi(form.Get("NumMedia"))
+	if err != nil || n <= 0 {
+		return nil
+	}
+	if n > maxMediaPerMessage {
+		n = maxMediaPerMessage
+	}
+	media := []inboundMedia{}
+	for i := 0; i < n; i++ {
+		m := inboundMedia{
+			URL:         form.Get(fmt.Sprintf("MediaUrl%d", i)),
+			ContentType: form.Get(fmt.Sprintf("MediaContentType%d", i)),
+		}
+		if m.URL != "" {
+			media = append(media, m)
+		}
+	}
+	return media
+}
+
+// checkMediaURL makes sure mediaURL is media of our twilio account, over
+// https, since the account's credentials are sent with it.
+func (s *NotifyAppServer) checkMediaURL(mediaURL string) error {
+	u, err := url.Parse(mediaURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse media url")
+	}
+	api, err := url.Parse(s.config.API)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse twilio api url")
+	}
+	if u.Scheme != "https" || u.User != nil || u.Host != api.Host || !strings.HasPrefix(u.Path, strings.TrimSuffix(api.Path, "/")+"/") {
+		return fmt.Errorf("media url '%s' isn't on twilio's api", mediaURL)
+	}
+	return nil
+}
+
+// httpsOnly refuses redirects off https.  twilio redirects media to its
+// cdn, and the client drops our credentials when the host changes.
+func httpsOnly(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to '%s' isn't https", req.URL)
+	}
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// downloadMedia fetches an inbound mms file from twilio, enforcing the size
+// and type limits on what was actually received.
+func (s *NotifyAppServer) downloadMedia(ctx context.Context, m inboundMedia) (data []byte, contentType string, err error) {
+	ctx, span := tracer.Start(ctx, "twilio download media", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() { endSpan(span, err) }()
+
+	if !Contains(attachmentTypes, m.ContentType) {
+		return nil, "", fmt.Errorf("content type '%s' is not allowed", m.ContentType)
+	}
+	if err := s.checkMediaURL(m.URL); err != nil {
+		return nil, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.URL, nil)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to create request")
+	}
+	req.SetBasicAuth(s.config.User, s.config.Password)
+	client := *s.client
+	client.CheckRedirect = httpsOnly
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("received http %d fetching media", resp.StatusCode)
+	}
+
+	data, err = ioutil.ReadAll(io.LimitReader(resp.Body, maxAttachmentSize+1))
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to read media")
+	}
+	if int64(len(data)) > maxAttachmentSize {
+		return nil, "", fmt.Errorf("media is larger than %d bytes", maxAttachmentSize)
+	}
+	contentType = http.DetectContentType(data)
+	if !Contains(attachmentTypes, contentType) {
+		return nil, "", fmt.Errorf("media sniffed as '%s' is not allowed", contentType)
+	}
+	return data, contentType, nil
+}
+
+// thumbnail scales an image down to fit thumbnailSize, averaging the source
+// pixels under each thumbnail pixel, and encodes it as a jpeg.
+func thumbnail(data []byte) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode image header")
+	}
+	if int64(config.Width)*int64(config.Height) > maxImagePixels {
+		return nil, fmt.Errorf("image is %dx%d, more than %d pixels", config.Width, config.Height, maxImagePixels)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode image")
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return nil, errors.New("image is empty")
+	}
+	scale := float64(thumbnailSize) / float64(w)
+	if h > w {
+		scale = float64(thumbnailSize) / float64(h)
+	}
+	if scale > 1 {
+		scale = 1
+	}
+	tw, th := int(float64(w)*scale), int(float64(h)*scale)
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := b.Min.Y+y*h/th, b.Min.Y+(y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := b.Min.X+x*w/tw, b.Min.X+(x+1)*w/tw
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					sr, sg, sb, sa := src.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+uint64(sr), g+uint64(sg), bl+uint64(sb), a+uint64(sa), n+1
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			dst.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), uint16(a / n)})
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := jpeg.Encode(buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, errors.Wrap(err, "failed to encode thumbnail")
+	}
+	return buf.Bytes(), nil
+}
+
+func attachmentKey(attachmentID string) string {
+	return "attachments/" + attachmentID
+}
+
+func thumbnailKey(attachmentID string) string {
+	return "attachments/" + attachmentID + ".thumb.jpg"
+}
+
+// saveAttachment stores data and its thumbnail in the blob store and links
+// them to j.
+func (s *NotifyAppServer) saveAttachment(ctx context.Context, j *pb.Journal, data []byte, contentType string) (*pb.Attachment, error) {
+	thumb, err := thumbnail(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make thumbnail")
+	}
+
+	a := &pb.Attachment{
+		AttachmentId: uuid.NewV4().String(),
+		JournalId:    j.JournalId,
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+	}
+	if err := s.blobs.Put(ctx, attachmentKey(a.AttachmentId), bytes.NewReader(data)); err != nil {
+		return nil, errors.Wrap(err, "failed to store attachment")
+	}
+	if err := s.blobs.Put(ctx, thumbnailKey(a.AttachmentId), bytes.NewReader(thumb)); err != nil {
+		return nil, errors.Wrap(err, "failed to store thumbnail")
+	}
+
+	if err := s.repo.InsertAttachment(ctx, j.PhoneNumber, a); err != nil {
+		return nil, errors.Wrap(err, "failed to insert attachment")
+	}
+	return a, nil
+}
+
+// attachMedia downloads every inbound media of an mms reply onto its journal.
+// bad media is logged and skipped so the text of the reply is still kept.
+// it runs after the webhook has answered, at most maxMediaJobs at a time.
+func (s *NotifyAppServer) attachMedia(ctx context.Context, j *pb.Journal, media []inboundMedia) {
+	select {
+	case s.mediaJobs <- struct{}{}:
+		defer func() { <-s.mediaJobs }()
+	case <-ctx.Done():
+		return
+	}
+	for _, m := range media {
+		data, contentType, err := s.downloadMedia(ctx, m)
+		if err != nil {
+			Logger(ctx).Errorf("failed to download media %s: %s", m.URL, err)
+			continue
+		}
+		if _, err := s.saveAttachment(ctx, j, data, contentType); err != nil {
+			Logger(ctx).Errorf("failed to save media %s: %s", m.URL, err)
+		}
+	}
+}
+
+func (s *NotifyAppServer) serveAttachment(w http.ResponseWriter, r *http.Request, thumb bool) {
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	a, err := s.repo.GetAttachment(r.Context(), user.PhoneNumber, vestigo.Param(r, "attachment_id"))
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to get attachment: %s", err)
+		http.NotFound(w, r)
+		return
+	}
+	key, contentType := attachmentKey(a.AttachmentId), a.ContentType
+	if thumb {
+		key, contentType = thumbnailKey(a.AttachmentId), "image/jpeg"
+	}
+	blob, err := s.blobs.Get(r.Context(), key)
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to get blob: %s", err)
+		http.NotFound(w, r)
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if _, err := io.Copy(w, blob); err != nil {
+		Logger(r.Context()).Errorf("failed to write attachment: %s", err)
+	}
+}
+
+func (s *NotifyAppServer) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	s.serveAttachment(w, r, false)
+}
+
+func (s *NotifyAppServer) GetAttachmentThumbnail(w http.ResponseWriter, r *http.Request) {
+	s.serveAttachment(w, r, true)
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BlobStore holds attachment files.  keys are slash separated paths.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// fileBlobStore is the default BlobStore, it keeps blobs under a directory on
+// the local filesystem.
+type fileBlobStore struct {
+	dir string
+}
+
+func NewFileBlobStore(dir string) (BlobStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create blob dir %s", dir)
+	}
+	return &fileBlobStore{dir: dir}, nil
+}
+
+func (b *fileBlobStore) path(key string) (string, error) {
+	p := filepath.Join(b.dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(p, filepath.Clean(b.dir)+string(filepath.Separator)) {
+		return "", errors.Errorf("blob key '%s' is invalid", key)
+	}
+	return p, nil
+}
+
+func (b *fileBlobStore) Put(ctx context.Context, key string, r io.Reader) error {
+	p, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return errors.Wrap(err, "failed to create dir")
+	}
+
+	//write to a temp file first so a failed upload never leaves half a blob
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".tmp-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create file")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed to write file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), p), "failed to rename file")
+}
+
+func (b *fileBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, errors.Wrapf(errNotFound, "blob '%s'", key)
+	}
+	return f, errors.Wrap(err, "failed to open file")
+}
+
+func (b *fileBlobStore) Delete(ctx context.Context, key string) error {
+	p, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove file")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/mikerjacobi/notify-app/server/repository"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	"github.com/twitchtv/twirp"
+)
+
+// getCommunications returns a page of the messages sent to or received from a
+// user, optionally only those for one notification, and the next cursor.
+func (s *NotifyAppServer) getCommunications(ctx context.Context, phoneNumber string, page *repository.Page, notificationID string) ([]*pb.Communication, string, error) {
+	comms, nextCursor, err := s.repo.ListCommunications(ctx, phoneNumber, page, notificationID)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, c := range comms {
+		//inbound messages are encrypted with the sender's key
+		if c.Message, err = s.decrypt(ctx, c.From, commsField(c.CommsId), c.Message); err != nil {
+			return nil, "", errors.Wrapf(err, "failed to decrypt communication '%s'", c.CommsId)
+		}
+	}
+	return comms, nextCursor, nil
+}
+
+func (s *NotifyAppServer) ListCommunications(ctx context.Context, req *pb.ListCommunicationsReq) (*pb.CommunicationList, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	page, arg, err := newPageQuery(req.Cursor, int(req.PageSize), req.CreatedAfter, req.CreatedBefore)
+	if err != nil {
+		Logger(ctx).Errorf("failed validation: %s", err)
+		return nil, twirp.InvalidArgumentError(arg, "invalid")
+	}
+
+	comms, nextCursor, err := s.getCommunications(ctx, phoneNumber, page, req.NotificationId)
+	if err != nil {
+		Logger(ctx).Errorf("failed to get communications: %s", err)
+		return nil, twirp.InternalError("failed to list communications")
+	}
+	return &pb.CommunicationList{Communications: comms, NextCursor: nextCursor}, nil
+}
@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// configEnvPrefix prefixes the env var for each setting, eg -db-name is
+// NOTIFY_DB_NAME.
+const configEnvPrefix = "NOTIFY_"
+
+// configFlags registers every setting on fs, bound to config and defaulted.
+func configFlags(fs *flag.FlagSet, config *Configuration) {
+	fs.StringVar(&config.Addr, "addr", "0.0.0.0:8080", "host:port the http server listens on")
+	fs.StringVar(&config.MetricsAddr, "metrics-addr", "", "host:port /metrics and /status/scheduler are served on, apart from addr, empty serves neither")
+	fs.StringVar(&config.TrustedProxies, "trusted-proxies", "", "comma separated ips or cidrs of proxies whose X-Forwarded-For and X-Forwarded-Proto are believed")
+	fs.StringVar(&config.PublicURL, "public-url", "", "url we're reached at, eg https://notify.example.com, which twilio signs and export links use, defaults to the request's host")
+	fs.StringVar(&config.TwilioSecretsPath, "twilio-secrets", "/etc/secrets/twilio.json", "path to the twilio secrets")
+	fs.StringVar(&config.DBSecretsPath, "db-secrets", "/etc/secrets/notify-db.json", "path to the mysql secrets")
+	fs.StringVar(&config.SessionSecretsPath, "session-secrets", "/etc/secrets/notify-session.json", "path to the session secrets")
+	fs.StringVar(&config.KeySecretsPath, "key-secrets", "/etc/secrets/notify-keys.json", "path to the master keys")
+	fs.StringVar(&config.DefaultRegion, "region", "US", "region of phone numbers given without a country code")
+	fs.DurationVar(&config.TrashRetention, "trash-retention", 30*24*time.Hour, "how long trashed journals and notifications can be restored")
+	fs.StringVar(&config.BlobDir, "blob-dir", "/var/lib/notify/blobs", "directory attachments are kept in")
+	fs.StringVar(&config.Store, "store", "mysql", "store to use: mysql, sqlite or memory")
+	fs.StringVar(&config.SQLitePath, "sqlite-path", "", "sqlite database file, for the sqlite store")
+	fs.BoolVar(&config.AutoMigrate, "auto-migrate", false, "apply pending schema migrations at startup")
+	fs.StringVar(&config.DBName, "db-name", "notify", "mysql database name")
+	fs.IntVar(&config.DBPool.MaxOpenConns, "db-max-open-conns", 10, "most open connections to mysql")
+	fs.IntVar(&config.DBPool.MaxIdleConns, "db-max-idle-conns", 10, "most idle connections to mysql")
+	fs.DurationVar(&config.DBPool.ConnMaxLifetime, "db-conn-max-lifetime", 10*time.Second, "how long a mysql connection is reused")
+	fs.DurationVar(&config.LoopInterval, "loop-interval", 15*time.Second, "how often due notifications are sent")
+	fs.DurationVar(&config.ShutdownTimeout, "shutdown-timeout", 30*time.Second, "how long in-flight requests and sends have to finish on shutdown")
+	fs.StringVar(&config.TraceExporter, "trace-exporter", "none", "where spans go: otlp, stdout or none")
+	fs.StringVar(&config.TraceEndpoint, "trace-endpoint", "", "otlp http endpoint url, defaults to the OTEL_EXPORTER_OTLP_* env")
+	fs.Float64Var(&config.TraceSampleRatio, "trace-sample-ratio", 1, "fraction of new traces sampled")
+	fs.StringVar(&config.TemplateDir, "template-dir", "", "client directory to serve templates and static files from, instead of the embedded ones")
+	fs.BoolVar(&config.ReloadTemplates, "reload-templates", false, "reparse templates from template-dir when they change, for development")
+}
+
+// LoadConfiguration builds the configuration from args, then NOTIFY_* env
+// vars, then the yaml or json file named by -config or NOTIFY_CONFIG, then
+// the defaults, the first to set a setting wins.  it returns the args left
+// after the flags, eg a migrate subcommand.
+func LoadConfiguration(args []string) (Configuration, []string, error) {
+	config := Configuration{}
+	fs := flag.NewFlagSet("notify", flag.ContinueOnError)
+	configFlags(fs, &config)
+	configPath := fs.String("config", "", "yaml or json file of settings, keyed by flag name")
+	if err := fs.Parse(args); err != nil {
+		return config, nil, err
+	}
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	if *configPath == "" {
+		*configPath = os.Getenv(configEnvPrefix + "CONFIG")
+	}
+
+	if *configPath != "" {
+		settings, err := readConfigFile(*configPath)
+		if err != nil {
+			return config, nil, err
+		}
+		for name, value := range settings {
+			if name == "config" || fs.Lookup(name) == nil {
+				return config, nil, fmt.Errorf("%s: unknown setting '%s'", *configPath, name)
+			}
+			if explicit[name] || os.Getenv(configEnvName(name)) != "" {
+				continue
+			}
+			if err := setConfigFlag(fs, name, value); err != nil {
+				return config, nil, fmt.Errorf("%s: %s", *configPath, err)
+			}
+		}
+	}
+
+	var envErr error
+	fs.VisitAll(func(f *flag.Flag) {
+		value := os.Getenv(configEnvName(f.Name))
+		if envErr != nil || explicit[f.Name] || f.Name == "config" || value == "" {
+			return
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			envErr = fmt.Errorf("%s: invalid value '%s': %s", configEnvName(f.Name), value, err)
+		}
+	})
+	if envErr != nil {
+		return config, nil, envErr
+	}
+
+	return config, fs.Args(), config.Validate()
+}
+
+func configEnvName(flagName string) string {
+	return configEnvPrefix + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// readConfigFile reads a flat map of flag names to values, as json if the
+// file ends in .json and yaml otherwise.
+func readConfigFile(path string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+	settings := map[string]interface{}{}
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(b, &settings)
+	} else {
+		err = yaml.Unmarshal(b, &settings)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	return settings, nil
+}
+
+func setConfigFlag(fs *flag.FlagSet, name string, value interface{}) error {
+	text := ""
+	switch v := value.(type) {
+	case string:
+		text = v
+	case bool, int:
+		text = fmt.Sprint(v)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("%s: must be a string, number or bool", name)
+	}
+	if err := fs.Set(name, text); err != nil {
+		return fmt.Errorf("%s: invalid value '%v': %s", name, value, err)
+	}
+	return nil
+}
+
+// parseProxies parses a comma separated list of ips and cidrs.
+func parseProxies(list string) ([]*net.IPNet, error) {
+	proxies := []*net.IPNet{}
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("'%s' is not an ip or cidr", entry)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+		_, proxy, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not an ip or cidr", entry)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
+
+// Validate checks the settings make sense together, naming every one that
+// doesn't by its flag.
+func (c Configuration) Validate() error {
+	problems := []string{}
+	invalid := func(name, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf("%s: %s", name, fmt.Sprintf(format, args...)))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		invalid("addr", "'%s' is not host:port", c.Addr)
+	}
+	if c.MetricsAddr != "" {
+		if _, _, err := net.SplitHostPort(c.MetricsAddr); err != nil {
+			invalid("metrics-addr", "'%s' is not host:port", c.MetricsAddr)
+		} else if c.MetricsAddr == c.Addr {
+			invalid("metrics-addr", "must differ from addr")
+		}
+	}
+	if _, err := parseProxies(c.TrustedProxies); err != nil {
+		invalid("trusted-proxies", "%s", err)
+	}
+	if c.PublicURL != "" {
+		if u, err := url.Parse(c.PublicURL); err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+			invalid("public-url", "'%s' is not an http or https url", c.PublicURL)
+		}
+	}
+	switch c.Store {
+	case "mysql":
+		if c.DBSecretsPath == "" {
+			invalid("db-secrets", "is required for the mysql store")
+		}
+		if c.DBName == "" {
+			invalid("db-name", "is required for the mysql store")
+		}
+	case "sqlite":
+		if c.SQLitePath == "" {
+			invalid("sqlite-path", "is required for the sqlite store")
+		}
+	case "memory":
+	default:
+		invalid("store", "'%s' must be mysql, sqlite or memory", c.Store)
+	}
+	for name, path := range map[string]string{
+		"twilio-secrets":  c.TwilioSecretsPath,
+		"session-secrets": c.SessionSecretsPath,
+		"key-secrets":     c.KeySecretsPath,
+	} {
+		if path == "" {
+			invalid(name, "is required")
+		}
+	}
+	if c.DefaultRegion == "" {
+		invalid("region", "is required")
+	}
+	if c.BlobDir == "" && c.Blobs == nil {
+		invalid("blob-dir", "is required")
+	}
+	if info, err := os.Stat(c.TemplateDir); c.TemplateDir != "" && (err != nil || !info.IsDir()) {
+		invalid("template-dir", "'%s' is not a directory", c.TemplateDir)
+	}
+	if c.ReloadTemplates && c.TemplateDir == "" {
+		invalid("reload-templates", "needs template-dir, embedded templates don't change")
+	}
+	if c.TrashRetention <= 0 {
+		invalid("trash-retention", "must be positive")
+	}
+	if c.LoopInterval <= 0 {
+		invalid("loop-interval", "must be positive")
+	}
+	switch c.TraceExporter {
+	case "otlp", "stdout", "none":
+	default:
+		invalid("trace-exporter", "'%s' must be otlp, stdout or none", c.TraceExporter)
+	}
+	if c.TraceSampleRatio < 0 || c.TraceSampleRatio > 1 {
+		invalid("trace-sample-ratio", "must be between 0 and 1")
+	}
+	if c.ShutdownTimeout <= 0 {
+		invalid("shutdown-timeout", "must be positive")
+	}
+	if c.DBPool.MaxOpenConns < 1 {
+		invalid("db-max-open-conns", "must be at least 1")
+	}
+	if c.DBPool.MaxIdleConns < 0 || c.DBPool.MaxIdleConns > c.DBPool.MaxOpenConns {
+		invalid("db-max-idle-conns", "must be between 0 and db-max-open-conns")
+	}
+	if c.DBPool.ConnMaxLifetime < 0 {
+		invalid("db-conn-max-lifetime", "can't be negative")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("invalid configuration:\n\t%s", strings.Join(problems, "\n\t"))
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("failed to write %s: %s", name, err)
+	}
+	return path
+}
+
+func TestLoadConfigurationPrecedence(t *testing.T) {
+	yamlFile := writeConfigFile(t, "notify.yaml", "db-name: from-file\nloop-interval: 1m\nauto-migrate: true\n")
+	jsonFile := writeConfigFile(t, "notify.json", `{"db-name": "from-json", "db-max-open-conns": 20, "trace-sample-ratio": 0.5}`)
+
+	cases := []struct {
+		name string
+		args []string
+		env  map[string]string
+		// want is checked against the loaded configuration
+		want func(Configuration) bool
+	}{
+		{
+			name: "default",
+			want: func(c Configuration) bool { return c.DBName == "notify" && c.LoopInterval == 15*time.Second },
+		},
+		{
+			name: "file over default",
+			args: []string{"-config", yamlFile},
+			want: func(c Configuration) bool {
+				return c.DBName == "from-file" && c.LoopInterval == time.Minute && c.AutoMigrate
+			},
+		},
+		{
+			name: "json file",
+			args: []string{"-config", jsonFile},
+			want: func(c Configuration) bool {
+				return c.DBName == "from-json" && c.DBPool.MaxOpenConns == 20 && c.TraceSampleRatio == 0.5
+			},
+		},
+		{
+			name: "file named by env",
+			env:  map[string]string{"NOTIFY_CONFIG": yamlFile},
+			want: func(c Configuration) bool { return c.DBName == "from-file" },
+		},
+		{
+			name: "env over file",
+			args: []string{"-config", yamlFile},
+			env:  map[string]string{"NOTIFY_DB_NAME": "from-env"},
+			want: func(c Configuration) bool { return c.DBName == "from-env" && c.LoopInterval == time.Minute },
+		},
+		{
+			name: "flag over env and file",
+			args: []string{"-config", yamlFile, "-db-name", "from-flag"},
+			env:  map[string]string{"NOTIFY_DB_NAME": "from-env"},
+			want: func(c Configuration) bool { return c.DBName == "from-flag" },
+		},
+		{
+			name: "flag set to its default still wins",
+			args: []string{"-config", yamlFile, "-loop-interval", "15s"},
+			want: func(c Configuration) bool { return c.LoopInterval == 15*time.Second },
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for name, value := range c.env {
+				t.Setenv(name, value)
+			}
+			config, _, err := LoadConfiguration(c.args)
+			if err != nil {
+				t.Fatalf("failed to load: %s", err)
+			}
+			if !c.want(config) {
+				t.Errorf("unexpected configuration %+v", config)
+			}
+		})
+	}
+}
+
+func TestLoadConfigurationErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		file string
+		args []string
+		env  map[string]string
+		// want is in the error
+		want string
+	}{
+		{
+			name: "unknown file key",
+			file: "db-nmae: notify\n",
+			want: "unknown setting 'db-nmae'",
+		},
+		{
+			name: "config in file",
+			file: "config: other.yaml\n",
+			want: "unknown setting 'config'",
+		},
+		{
+			name: "nested file value",
+			file: "db-name:\n  nested: true\n",
+			want: "must be a string, number or bool",
+		},
+		{
+			name: "bad file value",
+			file: "loop-interval: soon\n",
+			want: "invalid value 'soon'",
+		},
+		{
+			name: "bad env value",
+			env:  map[string]string{"NOTIFY_DB_MAX_OPEN_CONNS": "many"},
+			want: "NOTIFY_DB_MAX_OPEN_CONNS: invalid value 'many'",
+		},
+		{
+			name: "invalid together",
+			args: []string{"-store", "sqlite", "-db-max-idle-conns", "20"},
+			want: "db-max-idle-conns: must be between 0 and db-max-open-conns\n\tsqlite-path: is required for the sqlite store",
+		},
+		{
+			name: "bad trusted proxy",
+			args: []string{"-trusted-proxies", "10.0.0.0/8,lb"},
+			want: "trusted-proxies: 'lb' is not an ip or cidr",
+		},
+		{
+			name: "metrics on the public addr",
+			args: []string{"-metrics-addr", "0.0.0.0:8080"},
+			want: "metrics-addr: must differ from addr",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for name, value := range c.env {
+				t.Setenv(name, value)
+			}
+			args := c.args
+			if c.file != "" {
+				args = append([]string{"-config", writeConfigFile(t, "notify.yaml", c.file)}, args...)
+			}
+			_, _, err := LoadConfiguration(args)
+			if err == nil {
+				t.Fatalf("loaded, want an error with %q", c.want)
+			}
+			if !strings.Contains(err.Error(), c.want) {
+				t.Errorf("got error %q, want it to contain %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigurationArgs(t *testing.T) {
+	_, rest, err := LoadConfiguration([]string{"-db-name", "notify", "migrate", "up"})
+	if err != nil {
+		t.Fatalf("failed to load: %s", err)
+	}
+	if strings.Join(rest, " ") != "migrate up" {
+		t.Errorf("got args %v after the flags, want [migrate up]", rest)
+	}
+}
@@ -0,0 +1,498 @@
+package controllers
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	gpb "github.com/golang/protobuf/ptypes/empty"
+	"github.com/mikerjacobi/notify-app/server/repository"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/twitchtv/twirp"
+)
+
+// journals and inbound messages are envelope encrypted: each user has a data
+// key, stored in user_keys wrapped by one of the master keys from the secrets
+// dir.  an encrypted value is ciphertextPrefix, the data key's id, a colon and
+// base64 of nonce+ciphertext, bound to the owner and the table, column and id
+// it's stored at.  legacyCiphertextPrefix values are bound to the owner only,
+// and values without a prefix are plaintext written before encryption; both
+// are read as is until notifyctl encrypt gets to them.  tags are names users
+// chose to list and filter on, they aren't encrypted.
+const (
+	ciphertextPrefix       = "enc:v2:"
+	legacyCiphertextPrefix = "enc:v1:"
+	dataKeySize            = 32
+	encryptBatchSize       = 500
+	// activeKeyTTL is how long a user's current data key is cached.  another
+	// replica rotating it is noticed within this, until then the retired key
+	// is still used and rotation re-encrypts again after it.
+	activeKeyTTL = time.Minute
+)
+
+// KeyConfig is read from KeySecretsPath.  MasterKeys maps a key id to a
+// base64 32 byte key.  to rotate, add a key, make it active and restart, then
+// run notifyctl encrypt to rewrap the data keys; the old key can be removed
+// after.
+// HashKey, also base64 32 bytes, keys the hashes stored beside ciphertext and
+// can't be rotated without rehashing them.
+type KeyConfig struct {
+	MasterKeys      map[string]string `json:"master_keys"`
+	ActiveMasterKey string            `json:"active_master_key"`
+	HashKey         string            `json:"hash_key"`
+}
+
+// keyring's masters and active are fixed once loaded, the mutex guards the
+// caches and is never held over a store call.
+type keyring struct {
+	sync.Mutex
+	masters map[string]cipher.AEAD
+	active  string
+	hashKey []byte
+	// dataKeys caches unwrapped data keys by key id
+	dataKeys map[string]cipher.AEAD
+	// activeKeys caches the id of each user's current data key
+	activeKeys map[string]activeKey
+}
+
+type activeKey struct {
+	keyID   string
+	checked time.Time
+}
+
+func (k *keyring) dataKey(keyID string) (cipher.AEAD, bool) {
+	k.Lock()
+	defer k.Unlock()
+	aead, ok := k.dataKeys[keyID]
+	return aead, ok
+}
+
+func (k *keyring) setDataKey(keyID string, aead cipher.AEAD) {
+	k.Lock()
+	defer k.Unlock()
+	k.dataKeys[keyID] = aead
+}
+
+// activeKeyID returns a user's cached current key id, if it was checked
+// within activeKeyTTL.
+func (k *keyring) activeKeyID(phoneNumber string) (string, bool) {
+	k.Lock()
+	defer k.Unlock()
+	active, ok := k.activeKeys[phoneNumber]
+	if !ok || time.Since(active.checked) > activeKeyTTL {
+		return "", false
+	}
+	return active.keyID, true
+}
+
+func (k *keyring) setActiveKeyID(phoneNumber, keyID string) {
+	k.Lock()
+	defer k.Unlock()
+	k.activeKeys[phoneNumber] = activeKey{keyID: keyID, checked: time.Now()}
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+	return cipher.NewGCM(block)
+}
+
+func newKeyring(config KeyConfig) (*keyring, error) {
+	k := &keyring{
+		masters:    map[string]cipher.AEAD{},
+		active:     config.ActiveMasterKey,
+		dataKeys:   map[string]cipher.AEAD{},
+		activeKeys: map[string]activeKey{},
+	}
+	for id, encoded := range config.MasterKeys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != dataKeySize {
+			return nil, fmt.Errorf("master key '%s' must be %d base64 encoded bytes", id, dataKeySize)
+		}
+		if k.masters[id], err = newAEAD(key); err != nil {
+			return nil, errors.Wrapf(err, "failed to load master key '%s'", id)
+		}
+	}
+	if _, ok := k.masters[k.active]; !ok {
+		return nil, fmt.Errorf("active master key '%s' not found", k.active)
+	}
+	var err error
+	if k.hashKey, err = base64.StdEncoding.DecodeString(config.HashKey); err != nil || len(k.hashKey) != dataKeySize {
+		return nil, fmt.Errorf("hash key must be %d base64 encoded bytes", dataKeySize)
+	}
+	return k, nil
+}
+
+// hash is a keyed hash of value for one user and purpose, so the same value
+// hashes differently for each user and can't be guessed without the hash key.
+func (k *keyring) hash(phoneNumber, purpose, value string) string {
+	userKey := hmac.New(sha256.New, k.hashKey)
+	userKey.Write([]byte(phoneNumber))
+	mac := hmac.New(sha256.New, userKey.Sum(nil))
+	mac.Write([]byte(purpose + "|" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func seal(aead cipher.AEAD, plaintext, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to read nonce")
+	}
+	return aead.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+func open(aead cipher.AEAD, sealed, additional []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additional)
+	return plaintext, errors.Wrap(err, "failed to open")
+}
+
+// wrapping binds a data key to its owner and id, so a wrapped key can't be
+// swapped onto another user's row.
+func keyAdditionalData(phoneNumber, keyID string) []byte {
+	return []byte(phoneNumber + "|" + keyID)
+}
+
+// sealedAt is where a value is stored.  revisions are copies of their
+// journal's entry, so they're sealed at the journal.
+type sealedAt struct {
+	table, column, id string
+}
+
+func journalField(journalID, column string) sealedAt {
+	return sealedAt{table: "journals", column: column, id: journalID}
+}
+
+func commsField(commsID string) sealedAt {
+	return sealedAt{table: "communications", column: "message", id: commsID}
+}
+
+// values are bound to where they're stored, so ciphertext can't be moved to
+// another of the user's rows or columns.
+func valueAdditionalData(phoneNumber string, at sealedAt) []byte {
+	return []byte(phoneNumber + "|" + at.table + "|" + at.column + "|" + at.id)
+}
+
+// newDataKey creates, wraps and stores a new current data key for a user.
+func (s *NotifyAppServer) newDataKey(ctx context.Context, phoneNumber string) (string, cipher.AEAD, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", nil, errors.Wrap(err, "failed to read key")
+	}
+	keyID := uuid.NewV4().String()
+	wrapped, err := seal(s.keys.masters[s.keys.active], key, keyAdditionalData(phoneNumber, keyID))
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to wrap key")
+	}
+
+	k := &repository.UserKey{
+		KeyID:       keyID,
+		PhoneNumber: phoneNumber,
+		MasterKeyID: s.keys.active,
+		WrappedKey:  base64.StdEncoding.EncodeToString(wrapped),
+	}
+	if err := s.repo.InsertUserKey(ctx, k); err != nil {
+		return "", nil, errors.Wrap(err, "failed to insert data key")
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", nil, err
+	}
+	s.keys.setDataKey(keyID, aead)
+	s.keys.setActiveKeyID(phoneNumber, keyID)
+	return keyID, aead, nil
+}
+
+// unwrapDataKey returns a user's data key by id, whether or not it's current.
+func (s *NotifyAppServer) unwrapDataKey(ctx context.Context, phoneNumber, keyID string) (cipher.AEAD, error) {
+	if aead, ok := s.keys.dataKey(keyID); ok {
+		return aead, nil
+	}
+
+	k, err := s.repo.GetUserKey(ctx, phoneNumber, keyID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get data key '%s'", keyID)
+	}
+	master, ok := s.keys.masters[k.MasterKeyID]
+	if !ok {
+		return nil, fmt.Errorf("master key '%s' not loaded", k.MasterKeyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(k.WrappedKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode data key")
+	}
+	key, err := open(master, sealed, keyAdditionalData(phoneNumber, keyID))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unwrap data key")
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	s.keys.setDataKey(keyID, aead)
+	return aead, nil
+}
+
+// activeDataKey returns a user's current data key, creating their first.  two
+// writers can both create a first key, the newer is used from then on and both
+// stay readable.
+func (s *NotifyAppServer) activeDataKey(ctx context.Context, phoneNumber string) (string, cipher.AEAD, error) {
+	if keyID, ok := s.keys.activeKeyID(phoneNumber); ok {
+		aead, err := s.unwrapDataKey(ctx, phoneNumber, keyID)
+		return keyID, aead, err
+	}
+
+	keyID, err := s.repo.GetActiveUserKeyID(ctx, phoneNumber)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to get active data key")
+	}
+	if keyID == "" {
+		return s.newDataKey(ctx, phoneNumber)
+	}
+
+	aead, err := s.unwrapDataKey(ctx, phoneNumber, keyID)
+	if err != nil {
+		return "", nil, err
+	}
+	s.keys.setActiveKeyID(phoneNumber, keyID)
+	return keyID, aead, nil
+}
+
+// encrypt seals plaintext, to be stored at at, under phoneNumber's current
+// data key.  empty values are left empty.
+func (s *NotifyAppServer) encrypt(ctx context.Context, phoneNumber string, at sealedAt, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	keyID, aead, err := s.activeDataKey(ctx, phoneNumber)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get data key")
+	}
+	sealed, err := seal(aead, []byte(plaintext), valueAdditionalData(phoneNumber, at))
+	if err != nil {
+		return "", err
+	}
+	return ciphertextPrefix + keyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt opens a value written by encrypt at at, plaintext is returned as is.
+func (s *NotifyAppServer) decrypt(ctx context.Context, phoneNumber string, at sealedAt, value string) (string, error) {
+	additional := valueAdditionalData(phoneNumber, at)
+	if strings.HasPrefix(value, legacyCiphertextPrefix) {
+		value, additional = ciphertextPrefix+strings.TrimPrefix(value, legacyCiphertextPrefix), []byte(phoneNumber)
+	}
+	if !strings.HasPrefix(value, ciphertextPrefix) {
+		return value, nil
+	}
+	parts := strings.SplitN(strings.TrimPrefix(value, ciphertextPrefix), ":", 2)
+	if len(parts) != 2 {
+		return "", errors.New("malformed ciphertext")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode ciphertext")
+	}
+
+	aead, err := s.unwrapDataKey(ctx, phoneNumber, parts[0])
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get data key")
+	}
+	plaintext, err := open(aead, sealed, additional)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func (s *NotifyAppServer) decryptJournal(ctx context.Context, j *pb.Journal) error {
+	var err error
+	if j.Title, err = s.decrypt(ctx, j.PhoneNumber, journalField(j.JournalId, "title"), j.Title); err != nil {
+		return errors.Wrapf(err, "failed to decrypt title of '%s'", j.JournalId)
+	}
+	if j.Entry, err = s.decrypt(ctx, j.PhoneNumber, journalField(j.JournalId, "entry"), j.Entry); err != nil {
+		return errors.Wrapf(err, "failed to decrypt entry of '%s'", j.JournalId)
+	}
+	return nil
+}
+
+// encryptRows (re)encrypts, in batches, every value not yet under a data key
+// with id keyID, or under any data key when keyID is empty.  phoneNumber
+// limits it to one user's rows.  only inbound messages are encrypted, what we
+// send users isn't secret.
+func (s *NotifyAppServer) encryptRows(ctx context.Context, phoneNumber, keyID string) (int, error) {
+	total := 0
+	for {
+		if s.stopping() {
+			//the rest are picked up where this left off next time
+			Logger(ctx).Infof("shutting down after encrypting %d rows", total)
+			return total, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		batch, err := s.repo.ListUnsealedRows(ctx, ciphertextPrefix+keyID, phoneNumber, s.config.From, encryptBatchSize)
+		if err != nil {
+			return total, errors.Wrap(err, "failed to list rows")
+		}
+		if len(batch) == 0 {
+			return total, nil
+		}
+
+		sealedRows := 0
+		for _, row := range batch {
+			plaintexts := make([]string, len(row.Values))
+			sealed := make([]string, len(row.Values))
+			for i, value := range row.Values {
+				at := sealedAt{table: row.SealedTable, column: row.Columns[i], id: row.SealedID}
+				if plaintexts[i], err = s.decrypt(ctx, row.PhoneNumber, at, value); err != nil {
+					return total, errors.Wrapf(err, "failed to decrypt %s '%s'", row.Table, row.ID)
+				}
+				if sealed[i], err = s.encrypt(ctx, row.PhoneNumber, at, plaintexts[i]); err != nil {
+					return total, errors.Wrapf(err, "failed to encrypt %s '%s'", row.Table, row.ID)
+				}
+			}
+			err := s.updateSealedRow(ctx, row, plaintexts, sealed)
+			if errors.Cause(err) == errNotFound {
+				//edited since it was listed, the edit is already encrypted or
+				//the row is listed again
+				continue
+			}
+			if err != nil {
+				return total, errors.Wrapf(err, "failed to update %s '%s'", row.Table, row.ID)
+			}
+			sealedRows++
+		}
+		if sealedRows == 0 {
+			//every row changed under us, rather than relist them forever
+			return total, errors.New("no rows of the batch could be updated")
+		}
+		total += sealedRows
+	}
+}
+
+// updateSealedRow stores a row's resealed values.  journals are rehashed and
+// reindexed too, they were hashed without a key and not indexed before
+// enc:v2; in a transaction the update locks the row until the terms are
+// written, so a concurrent edit's terms land after these.
+func (s *NotifyAppServer) updateSealedRow(ctx context.Context, row *repository.SealedRow, plaintexts, sealed []string) error {
+	if !row.Hashed {
+		return s.repo.UpdateSealedRow(ctx, row, sealed, "")
+	}
+	return s.repo.InTx(ctx, func(tx repository.Repository) error {
+		if err := tx.UpdateSealedRow(ctx, row, sealed, s.contentHash(row.PhoneNumber, plaintexts...)); err != nil {
+			return err
+		}
+		return tx.SetJournalTerms(ctx, row.PhoneNumber, row.ID, s.journalTerms(row.PhoneNumber, plaintexts[0], plaintexts[1]))
+	})
+}
+
+// rewrapDataKeys moves every data key onto the active master key.
+func (s *NotifyAppServer) rewrapDataKeys(ctx context.Context) error {
+	stale, err := s.repo.ListUserKeysWrappedByOthers(ctx, s.keys.active)
+	if err != nil {
+		return errors.Wrap(err, "failed to list data keys")
+	}
+	for _, k := range stale {
+		master, ok := s.keys.masters[k.MasterKeyID]
+		if !ok {
+			return fmt.Errorf("master key '%s' of data key '%s' not loaded", k.MasterKeyID, k.KeyID)
+		}
+		sealed, err := base64.StdEncoding.DecodeString(k.WrappedKey)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode data key")
+		}
+		additional := keyAdditionalData(k.PhoneNumber, k.KeyID)
+		key, err := open(master, sealed, additional)
+		if err != nil {
+			return errors.Wrapf(err, "failed to unwrap data key '%s'", k.KeyID)
+		}
+		rewrapped, err := seal(s.keys.masters[s.keys.active], key, additional)
+		if err != nil {
+			return errors.Wrap(err, "failed to wrap key")
+		}
+		k.MasterKeyID, k.WrappedKey = s.keys.active, base64.StdEncoding.EncodeToString(rewrapped)
+		if err := s.repo.UpdateUserKeyWrap(ctx, k); err != nil {
+			return errors.Wrapf(err, "failed to update data key '%s'", k.KeyID)
+		}
+	}
+	if len(stale) > 0 {
+		Logger(ctx).Infof("rewrapped %d data keys with master key '%s'", len(stale), s.keys.active)
+	}
+	return nil
+}
+
+// rotateUserKey retires a user's data key and re-encrypts everything of
+// theirs under a new one.  other replicas may write under the retired key
+// until their cache expires, so it's done again after activeKeyTTL.
+func (s *NotifyAppServer) rotateUserKey(ctx context.Context, phoneNumber string) error {
+	if err := s.repo.RetireUserKeys(ctx, phoneNumber); err != nil {
+		return errors.Wrap(err, "failed to retire data keys")
+	}
+	keyID, _, err := s.newDataKey(ctx, phoneNumber)
+	if err != nil {
+		return errors.Wrap(err, "failed to create data key")
+	}
+
+	if _, err := s.encryptRows(ctx, phoneNumber, keyID); err != nil {
+		return errors.Wrap(err, "failed to re-encrypt")
+	}
+	s.goBackground(ctx, func(ctx context.Context) {
+		if !s.wait(ctx, activeKeyTTL) {
+			return
+		}
+		//a later rotation does its own
+		if current, err := s.repo.GetActiveUserKeyID(ctx, phoneNumber); err != nil || current != keyID {
+			return
+		}
+		if _, err := s.encryptRows(ctx, phoneNumber, keyID); err != nil {
+			Logger(ctx).Errorf("failed to re-encrypt after rotation: %s", err)
+		}
+	})
+	return nil
+}
+
+// EncryptExisting rewraps data keys under the active master key, and seals
+// and indexes rows written before encryption at rest or under enc:v1.  it's
+// run by notifyctl encrypt, not by every replica at startup, and can be
+// stopped and run again; what's done stays done.
+func (s *NotifyAppServer) EncryptExisting(ctx context.Context) (int, error) {
+	if err := s.rewrapDataKeys(ctx); err != nil {
+		return 0, errors.Wrap(err, "failed to rewrap data keys")
+	}
+	n, err := s.encryptRows(ctx, "", "")
+	if err != nil {
+		return n, errors.Wrap(err, "failed to encrypt existing rows")
+	}
+	return n, nil
+}
+
+func (s *NotifyAppServer) RotateUserKey(ctx context.Context, req *pb.RotateUserKeyReq) (*gpb.Empty, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.rotateUserKey(ctx, phoneNumber); err != nil {
+		Logger(ctx).Errorf("failed to rotate user key: %s", err)
+		return nil, twirp.InternalError("failed to rotate user key")
+	}
+	return &gpb.Empty{}, nil
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestEncryptRoundTrip(t *testing.T) {
+	const phone = "+15555550100"
+	ctx := context.Background()
+	s := newTestServer(t)
+	at := journalField("j1", "entry")
+
+	cases := []string{"coffee with friends", "café ☕\nline two", strings.Repeat("long ", 1000)}
+	for _, plaintext := range cases {
+		sealed, err := s.encrypt(ctx, phone, at, plaintext)
+		if err != nil {
+			t.Fatalf("encrypt: %s", err)
+		}
+		if !strings.HasPrefix(sealed, ciphertextPrefix) || strings.Contains(sealed, "coffee") {
+			t.Errorf("encrypt(%q) = %q, want it sealed", plaintext, sealed)
+		}
+		got, err := s.decrypt(ctx, phone, at, sealed)
+		if err != nil {
+			t.Fatalf("decrypt: %s", err)
+		}
+		if got != plaintext {
+			t.Errorf("decrypt = %q, want %q", got, plaintext)
+		}
+	}
+
+	//a fresh server reads the data key back from the store
+	s2 := newTestServer(t)
+	s2.repo = s.repo
+	sealed, _ := s.encrypt(ctx, phone, at, "coffee")
+	if got, err := s2.decrypt(ctx, phone, at, sealed); err != nil || got != "coffee" {
+		t.Errorf("decrypt with an unwrapped key = %q, %v, want coffee", got, err)
+	}
+}
+
+func TestDecryptPassthrough(t *testing.T) {
+	const phone = "+15555550100"
+	ctx := context.Background()
+	s := newTestServer(t)
+
+	for _, value := range []string{"", "written before encryption"} {
+		if got, err := s.decrypt(ctx, phone, journalField("j1", "entry"), value); err != nil || got != value {
+			t.Errorf("decrypt(%q) = %q, %v, want it as is", value, got, err)
+		}
+	}
+	if got, err := s.encrypt(ctx, phone, journalField("j1", "entry"), ""); err != nil || got != "" {
+		t.Errorf("encrypt(\"\") = %q, %v, want empty", got, err)
+	}
+}
+
+func TestDecryptLegacy(t *testing.T) {
+	const phone = "+15555550100"
+	ctx := context.Background()
+	s := newTestServer(t)
+
+	//v1 values were bound to the owner only
+	keyID, aead, err := s.activeDataKey(ctx, phone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := seal(aead, []byte("coffee"), []byte(phone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := legacyCiphertextPrefix + keyID + ":" + base64.StdEncoding.EncodeToString(sealed)
+
+	for _, at := range []sealedAt{journalField("j1", "entry"), journalField("j2", "title"), commsField("c1")} {
+		if got, err := s.decrypt(ctx, phone, at, legacy); err != nil || got != "coffee" {
+			t.Errorf("decrypt at %v = %q, %v, want coffee", at, got, err)
+		}
+	}
+	if _, err := s.decrypt(ctx, "+15555550101", journalField("j1", "entry"), legacy); err == nil {
+		t.Errorf("decrypt as another user succeeded")
+	}
+}
+
+func TestDecryptMoved(t *testing.T) {
+	const phone = "+15555550100"
+	ctx := context.Background()
+	s := newTestServer(t)
+	at := journalField("j1", "entry")
+
+	sealed, err := s.encrypt(ctx, phone, at, "coffee")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name  string
+		phone string
+		at    sealedAt
+	}{
+		{"another row", phone, journalField("j2", "entry")},
+		{"another column", phone, journalField("j1", "title")},
+		{"another table", phone, commsField("j1")},
+		{"another user", "+15555550101", at},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got, err := s.decrypt(ctx, c.phone, c.at, sealed); err == nil {
+				t.Errorf("decrypt = %q, want an error", got)
+			}
+		})
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := []byte(sealed)
+		tampered[len(tampered)-3] ^= 1
+		if _, err := s.decrypt(ctx, phone, at, string(tampered)); err == nil {
+			t.Errorf("decrypt of tampered ciphertext succeeded")
+		}
+	})
+	t.Run("malformed", func(t *testing.T) {
+		if _, err := s.decrypt(ctx, phone, at, ciphertextPrefix+"nokey"); err == nil {
+			t.Errorf("decrypt of malformed ciphertext succeeded")
+		}
+	})
+}
@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/mikerjacobi/notify-app/server/repository"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	"github.com/twitchtv/twirp"
+)
+
+var exportFormats = []string{"markdown", "jsonl", "csv", "archive"}
+
+type exportOptions struct {
+	Format string
+	// GroupBy is "day" or "month", how markdown is split into files
+	GroupBy string
+	Page    *repository.Page
+}
+
+func newExportOptions(format, groupBy, createdAfter, createdBefore string) (*exportOptions, string, error) {
+	if !Contains(exportFormats, format) {
+		return nil, "format", fmt.Errorf("format '%s' is invalid", format)
+	}
+	if groupBy == "" {
+		groupBy = "month"
+	}
+	if groupBy != "day" && groupBy != "month" {
+		return nil, "group_by", fmt.Errorf("group_by '%s' is invalid", groupBy)
+	}
+	page, arg, err := newPageQuery("", maxPageSize, createdAfter, createdBefore)
+	if err != nil {
+		return nil, arg, err
+	}
+	return &exportOptions{Format: format, GroupBy: groupBy, Page: page}, "", nil
+}
+
+// contentType returns the mime type and file name of the export.  markdown is
+// one file per day or month, so it is zipped like the archive.
+func (o *exportOptions) contentType() (string, string) {
+	switch o.Format {
+	case "jsonl":
+		return "application/x-ndjson", "journals.jsonl"
+	case "csv":
+		return "text/csv", "journals.csv"
+	case "markdown":
+		return "application/zip", "journals-markdown.zip"
+	default:
+		return "application/zip", "notify-archive.zip"
+	}
+}
+
+func (s *NotifyAppServer) writeExport(ctx context.Context, w io.Writer, phoneNumber string, opts *exportOptions) error {
+	switch opts.Format {
+	case "jsonl":
+		return s.eachJournal(ctx, phoneNumber, opts.Page, jsonlWriter(w))
+	case "csv":
+		return s.writeJournalsCSV(ctx, w, phoneNumber, opts.Page)
+	case "markdown":
+		zw := zip.NewWriter(w)
+		if err := s.writeJournalsMarkdown(ctx, zw, phoneNumber, opts); err != nil {
+			return err
+		}
+		return errors.Wrap(zw.Close(), "failed to close zip")
+	default:
+		return s.writeArchive(ctx, w, phoneNumber, opts)
+	}
+}
+
+// eachJournal calls fn with every journal in the page's date range, newest
+// first, a page at a time.
+func (s *NotifyAppServer) eachJournal(ctx context.Context, phoneNumber string, page *repository.Page, fn func(proto.Message) error) error {
+	page = &repository.Page{Limit: page.Limit, CreatedAfter: page.CreatedAfter, CreatedBefore: page.CreatedBefore}
+	for {
+		entries, nextCursor, err := s.getJournalEntries(ctx, phoneNumber, page, "", "")
+		if err != nil {
+			return errors.Wrap(err, "failed to get entries")
+		}
+		for _, j := range entries {
+			if err := fn(j); err != nil {
+				return err
+			}
+		}
+		if nextCursor == "" {
+			return nil
+		}
+		page.Cursor = nextCursor
+	}
+}
+
+func (s *NotifyAppServer) eachCommunication(ctx context.Context, phoneNumber string, page *repository.Page, fn func(proto.Message) error) error {
+	page = &repository.Page{Limit: page.Limit, CreatedAfter: page.CreatedAfter, CreatedBefore: page.CreatedBefore}
+	for {
+		comms, nextCursor, err := s.getCommunications(ctx, phoneNumber, page, "")
+		if err != nil {
+			return errors.Wrap(err, "failed to get communications")
+		}
+		for _, c := range comms {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+		if nextCursor == "" {
+			return nil
+		}
+		page.Cursor = nextCursor
+	}
+}
+
+func jsonlWriter(w io.Writer) func(proto.Message) error {
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	return func(m proto.Message) error {
+		if err := marshaler.Marshal(w, m); err != nil {
+			return errors.Wrap(err, "failed to marshal")
+		}
+		_, err := io.WriteString(w, "\n")
+		return errors.Wrap(err, "failed to write")
+	}
+}
+
+func (s *NotifyAppServer) writeJournalsCSV(ctx context.Context, w io.Writer, phoneNumber string, page *repository.Page) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"journal_id", "created", "updated", "title", "entry", "comms_id"}); err != nil {
+		return errors.Wrap(err, "failed to write header")
+	}
+	err := s.eachJournal(ctx, phoneNumber, page, func(m proto.Message) error {
+		j := m.(*pb.Journal)
+		return errors.Wrap(cw.Write([]string{j.JournalId, j.Created, j.Updated, j.Title, j.Entry, j.CommsId}), "failed to write row")
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "failed to flush")
+}
+
+// writeJournalsMarkdown writes one markdown file per day or month, with the
+// entries in each file oldest first.
+func (s *NotifyAppServer) writeJournalsMarkdown(ctx context.Context, zw *zip.Writer, phoneNumber string, opts *exportOptions) error {
+	keyLen := len("2006-01")
+	if opts.GroupBy == "day" {
+		keyLen = len("2006-01-02")
+	}
+
+	group, groupKey := []*pb.Journal{}, ""
+	flush := func() error {
+		if len(group) == 0 {
+			return nil
+		}
+		f, err := zw.Create("journals/" + groupKey + ".md")
+		if err != nil {
+			return errors.Wrap(err, "failed to create zip entry")
+		}
+		fmt.Fprintf(f, "# %s\n", groupKey)
+		for i := len(group) - 1; i >= 0; i-- {
+			j := group[i]
+			fmt.Fprintf(f, "\n## %s", j.Created[:len(timeFormat)])
+			if j.Title != "" {
+				fmt.Fprintf(f, " - %s", j.Title)
+			}
+			if _, err := fmt.Fprintf(f, "\n\n%s\n", strings.TrimSpace(j.Entry)); err != nil {
+				return errors.Wrap(err, "failed to write entry")
+			}
+		}
+		group = group[:0]
+		return nil
+	}
+
+	err := s.eachJournal(ctx, phoneNumber, opts.Page, func(m proto.Message) error {
+		j := m.(*pb.Journal)
+		key := j.Created[:keyLen]
+		if key != groupKey {
+			if err := flush(); err != nil {
+				return err
+			}
+			groupKey = key
+		}
+		group = append(group, j)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// writeArchive zips everything we have for a user: journals as jsonl and
+// markdown, communications history and notification settings.
+func (s *NotifyAppServer) writeArchive(ctx context.Context, w io.Writer, phoneNumber string, opts *exportOptions) error {
+	zw := zip.NewWriter(w)
+	f, err := zw.Create("journals.jsonl")
+	if err != nil {
+		return errors.Wrap(err, "failed to create zip entry")
+	}
+	if err := s.eachJournal(ctx, phoneNumber, opts.Page, jsonlWriter(f)); err != nil {
+		return err
+	}
+
+	if err := s.writeJournalsMarkdown(ctx, zw, phoneNumber, opts); err != nil {
+		return err
+	}
+
+	f, err = zw.Create("communications.jsonl")
+	if err != nil {
+		return errors.Wrap(err, "failed to create zip entry")
+	}
+	if err := s.eachCommunication(ctx, phoneNumber, opts.Page, jsonlWriter(f)); err != nil {
+		return err
+	}
+
+	userNotifications, err := s.repo.ListUserNotifications(ctx, phoneNumber)
+	if err != nil {
+		return errors.Wrap(err, "failed to get user notifications")
+	}
+	f, err = zw.Create("user_notifications.jsonl")
+	if err != nil {
+		return errors.Wrap(err, "failed to create zip entry")
+	}
+	write := jsonlWriter(f)
+	for _, up := range userNotifications {
+		if err := write(up); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(zw.Close(), "failed to close zip")
+}
+
+// exportPath streams exports to api token holders.  the rpc only validates
+// the request and says where to get it, twirp would hold it all in memory.
+const exportPath = "/api/export"
+
+func (s *NotifyAppServer) ExportJournals(ctx context.Context, req *pb.ExportReq) (*pb.ExportResp, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	opts, arg, err := newExportOptions(req.Format, req.GroupBy, req.CreatedAfter, req.CreatedBefore)
+	if err != nil {
+		Logger(ctx).Errorf("failed validation: %s", err)
+		return nil, twirp.InvalidArgumentError(arg, "invalid")
+	}
+
+	q := url.Values{"phone_number": {phoneNumber}, "format": {opts.Format}, "group_by": {opts.GroupBy}}
+	if req.CreatedAfter != "" {
+		q.Set("after", req.CreatedAfter)
+	}
+	if req.CreatedBefore != "" {
+		q.Set("before", req.CreatedBefore)
+	}
+	base, ok := ctx.Value(baseURLKey).(string)
+	if !ok {
+		base = strings.TrimSuffix(s.config.PublicURL, "/")
+	}
+	contentType, filename := opts.contentType()
+	return &pb.ExportResp{
+		Filename:    filename,
+		ContentType: contentType,
+		DownloadUrl: base + exportPath + "?" + q.Encode(),
+	}, nil
+}
+
+// WriteExport writes an export to w as it's read, for notifyctl, and returns
+// its file name.
+func (s *NotifyAppServer) WriteExport(ctx context.Context, w io.Writer, req *pb.ExportReq) (string, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return "", err
+	}
+	opts, _, err := newExportOptions(req.Format, req.GroupBy, req.CreatedAfter, req.CreatedBefore)
+	if err != nil {
+		return "", err
+	}
+	_, filename := opts.contentType()
+	return filename, s.writeExport(ctx, w, phoneNumber, opts)
+}
+
+// GetExport streams the export straight to the response.
+func (s *NotifyAppServer) GetExport(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	q := r.URL.Query()
+	opts, _, err := newExportOptions(q.Get("format"), q.Get("group_by"), q.Get("after"), q.Get("before"))
+	if err != nil {
+		Logger(r.Context()).Errorf("invalid export options: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	s.streamExport(w, r, user.PhoneNumber, opts)
+}
+
+// GetApiExport streams an export to an api token holder, from the url
+// ExportJournals returns.
+func (s *NotifyAppServer) GetApiExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := s.apiTokenUser(ctx)
+	if err != nil {
+		http.Error(w, err.(twirp.Error).Msg(), http.StatusUnauthorized)
+		return
+	}
+	setLogUser(ctx, user.PhoneNumber)
+	ctx = context.WithValue(ctx, userKey, user)
+
+	q := r.URL.Query()
+	phoneNumber, err := s.authorizePhoneNumber(ctx, q.Get("phone_number"))
+	if err != nil {
+		http.Error(w, err.(twirp.Error).Msg(), http.StatusForbidden)
+		return
+	}
+	opts, _, err := newExportOptions(q.Get("format"), q.Get("group_by"), q.Get("after"), q.Get("before"))
+	if err != nil {
+		Logger(ctx).Errorf("invalid export options: %s", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.streamExport(w, r.WithContext(ctx), phoneNumber, opts)
+}
+
+func (s *NotifyAppServer) streamExport(w http.ResponseWriter, r *http.Request, phoneNumber string, opts *exportOptions) {
+	contentType, filename := opts.contentType()
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := s.writeExport(r.Context(), w, phoneNumber, opts); err != nil {
+		//headers are already sent, all we can do is cut the download short
+		Logger(r.Context()).Errorf("failed to export: %s", err)
+	}
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Version, Commit and BuildTime describe the build, they're set with
+// -ldflags "-X github.com/mikerjacobi/notify-app/server/controllers.Version=..."
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// readyTimeout bounds each readiness check, so a hung database fails the
+// probe instead of hanging it.
+var readyTimeout = 2 * time.Second
+
+func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		logrus.Errorf("failed to encode json: %s", err)
+	}
+}
+
+// GetHealthz reports the process is up.
+func (s *NotifyAppServer) GetHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok\n"))
+}
+
+// GetReadyz reports whether the server can take traffic: the store answers,
+// the templates are loaded and twilio is configured.  it's 503 when any check
+// fails, naming the failures.
+func (s *NotifyAppServer) GetReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	checks := map[string]string{}
+	ready := true
+	check := func(name string, err error) {
+		checks[name] = "ok"
+		if err != nil {
+			Logger(ctx).Warnf("readiness check %s failed: %s", name, err)
+			checks[name] = err.Error()
+			ready = false
+		}
+	}
+	check("store", s.repo.Ping(ctx))
+	check("templates", s.checkTemplates())
+	check("twilio", s.checkTwilioConfig())
+
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, struct {
+		Ready  bool              `json:"ready"`
+		Checks map[string]string `json:"checks"`
+	}{ready, checks})
+}
+
+func (s *NotifyAppServer) checkTemplates() error {
+	_, err := s.templates.page("error")
+	return err
+}
+
+func (s *NotifyAppServer) checkTwilioConfig() error {
+	missing := []string{}
+	for _, setting := range []struct{ name, value string }{
+		{"sms_api", s.config.API},
+		{"from_number", s.config.From},
+		{"username", s.config.User},
+		{"password", s.config.Password},
+	} {
+		if setting.value == "" {
+			missing = append(missing, setting.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s not configured", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// GetVersion reports the build.
+func (s *NotifyAppServer) GetVersion(w http.ResponseWriter, r *http.Request) {
+	commit := Commit
+	if info, ok := debug.ReadBuildInfo(); ok && commit == "" {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				commit = setting.Value
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Version   string `json:"version"`
+		Commit    string `json:"commit"`
+		BuildTime string `json:"build_time"`
+		GoVersion string `json:"go_version"`
+	}{Version, commit, BuildTime, runtime.Version()})
+}
+
+// SchedulerStatus is how the notification scheduler is keeping up.
+type SchedulerStatus struct {
+	// LastRun is when triggerNotifications last succeeded, "" if it hasn't
+	LastRun string `json:"last_run"`
+	// Due is how many user notifications are waiting to be sent
+	Due int `json:"due"`
+	// LagSeconds is how long the oldest due notification has waited
+	LagSeconds float64 `json:"lag_seconds"`
+}
+
+// recordTriggerRun notes a successful triggerNotifications run.
+func (s *NotifyAppServer) recordTriggerRun(at time.Time) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	s.lastTrigger = at
+	schedulerLastRun.Set(float64(at.Unix()))
+}
+
+func (s *NotifyAppServer) schedulerStatus(ctx context.Context) (*SchedulerStatus, error) {
+	now := s.now(ctx)
+	due, err := s.repo.ListDueUserNotifications(ctx, dueBefore(now))
+	if err != nil {
+		return nil, err
+	}
+
+	status := &SchedulerStatus{Due: len(due)}
+	s.statusMu.Lock()
+	if !s.lastTrigger.IsZero() {
+		status.LastRun = s.lastTrigger.Format(timeFormat)
+	}
+	s.statusMu.Unlock()
+	for _, up := range due {
+		next, err := time.Parse(timeFormat, up.NextNotificationTime)
+		if err != nil {
+			Logger(ctx).Warnf("failed to parse next notification time '%s': %s", up.NextNotificationTime, err)
+			continue
+		}
+		if lag := now.Sub(next).Seconds(); lag > status.LagSeconds {
+			status.LagSeconds = lag
+		}
+	}
+	return status, nil
+}
+
+// GetSchedulerStatus reports the last successful scheduler run, how many
+// notifications are due and how far behind the oldest is.
+func (s *NotifyAppServer) GetSchedulerStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.schedulerStatus(r.Context())
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to get scheduler status: %s", err)
+		writeJSON(w, http.StatusInternalServerError, struct {
+			Error string `json:"error"`
+		}{"failed to get scheduler status"})
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
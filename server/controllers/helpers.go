@@ -1,25 +1,27 @@
 package controllers
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/mikerjacobi/notify-app/server/repository"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 )
 
 type contextKey string
 
 var (
-	userKey contextKey = "user"
+	userKey     contextKey = "user"
+	apiTokenKey contextKey = "api_token"
+	baseURLKey  contextKey = "base_url"
 )
 
-type Database interface {
-	Prepare(query string) (*sql.Stmt, error)
-}
+// errNotFound is the cause of errors from helpers that look up a single row
+// which doesn't exist, so rpcs can answer with twirp.NotFound.
+var errNotFound = repository.ErrNotFound
 
 func Contains(slice []string, ele string) bool {
 	for _, val := range slice {
@@ -30,31 +32,11 @@ func Contains(slice []string, ele string) bool {
 	return false
 }
 
-func now(db Database) time.Time {
-	stmt, err := db.Prepare(`SELECT NOW() as now`)
-	if err != nil {
-		logrus.Errorf("failed to prepare NOW: %s", err)
-		return time.Now()
-	}
-	rows, err := stmt.Query()
-	if err != nil {
-		logrus.Errorf("failed to select NOW: %s", err)
-		return time.Now()
-	}
-	defer rows.Close()
-	if !rows.Next() {
-		logrus.Errorf("no NOW rows: %s", err)
-		return time.Now()
-	}
-
-	var nowStr string
-	if err := rows.Scan(&nowStr); err != nil {
-		logrus.Errorf("failed to scan NOW: %s", err)
-		return time.Now()
-	}
-	now, err := time.Parse(timeFormat, nowStr)
+// now is the store's clock, or ours if the store can't be reached.
+func (s *NotifyAppServer) now(ctx context.Context) time.Time {
+	now, err := s.repo.Now(ctx)
 	if err != nil {
-		logrus.Errorf("failed to parse NOW: %s", err)
+		Logger(ctx).Errorf("failed to get NOW: %s", err)
 		return time.Now()
 	}
 	return now
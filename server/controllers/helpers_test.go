@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/mikerjacobi/notify-app/server/repository"
+)
+
+// testKey is a base64 32 byte key for the test keyring.
+var testKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", dataKeySize)))
+
+// newTestServer returns a server on a fresh memory store, with the embedded
+// templates, one master key and no twilio.
+func newTestServer(t *testing.T) *NotifyAppServer {
+	t.Helper()
+	templates, err := loadTemplates(clientFiles(""), false)
+	if err != nil {
+		t.Fatalf("failed to load templates: %s", err)
+	}
+	keys, err := newKeyring(KeyConfig{
+		MasterKeys:      map[string]string{"m1": testKey},
+		ActiveMasterKey: "m1",
+		HashKey:         testKey,
+	})
+	if err != nil {
+		t.Fatalf("failed to load keys: %s", err)
+	}
+	return &NotifyAppServer{
+		config:    Configuration{SessionConfig: SessionConfig{DeviceKey: "0123456789abcdef0123456789abcdef"}},
+		repo:      repository.NewMemory(),
+		templates: templates,
+		keys:      keys,
+	}
+}
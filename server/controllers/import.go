@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mikerjacobi/notify-app/server/repository"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/twitchtv/twirp"
+)
+
+var (
+	importFormats = []string{"json", "csv", "dayone"}
+	// importDateLayouts are tried in order on every imported date
+	importDateLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05.999999",
+		timeFormat,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+		"01/02/2006 15:04",
+		"01/02/2006",
+	}
+	maxImportSize int64 = 10 << 20
+)
+
+const (
+	importStatusNew       = "new"
+	importStatusDuplicate = "duplicate"
+	importStatusInvalid   = "invalid"
+)
+
+// contentHash is what we dedupe journals on, along with the day they were
+// written.  values are a journal's title and entry.
+func (s *NotifyAppServer) contentHash(phoneNumber string, values ...string) string {
+	return s.keys.hash(phoneNumber, "content", strings.Join(values, "\n"))
+}
+
+// importEntry is the union of the fields we understand from our own export
+// and other apps' json exports.
+type importEntry struct {
+	Title        string `json:"title"`
+	Entry        string `json:"entry"`
+	Text         string `json:"text"`
+	Created      string `json:"created"`
+	Date         string `json:"date"`
+	CreationDate string `json:"creationDate"`
+}
+
+func (e importEntry) journal() *pb.Journal {
+	j := &pb.Journal{Title: e.Title, Entry: e.Entry, Created: e.Created}
+	if j.Entry == "" {
+		j.Entry = e.Text
+	}
+	if j.Created == "" {
+		j.Created = e.Date
+	}
+	if j.Created == "" {
+		j.Created = e.CreationDate
+	}
+	return j
+}
+
+// parseImport turns an upload into journals.  json is either an array or one
+// object per line, like our jsonl export.  dayone is day one's json export.
+func parseImport(format string, data []byte) ([]*pb.Journal, error) {
+	entries := []importEntry{}
+	switch format {
+	case "json":
+		trimmed := bytes.TrimSpace(data)
+		if bytes.HasPrefix(trimmed, []byte("[")) {
+			if err := json.Unmarshal(trimmed, &entries); err != nil {
+				return nil, errors.Wrap(err, "failed to unmarshal")
+			}
+			break
+		}
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		for {
+			e := importEntry{}
+			if err := dec.Decode(&e); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, errors.Wrap(err, "failed to decode")
+			}
+			entries = append(entries, e)
+		}
+	case "dayone":
+		export := struct {
+			Entries []importEntry `json:"entries"`
+		}{}
+		if err := json.Unmarshal(data, &export); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal")
+		}
+		entries = export.Entries
+	case "csv":
+		return parseImportCSV(data)
+	default:
+		return nil, fmt.Errorf("unknown format '%s'", format)
+	}
+
+	journals := []*pb.Journal{}
+	for _, e := range entries {
+		journals = append(journals, e.journal())
+	}
+	return journals, nil
+}
+
+// parseImportCSV reads a csv with a header row naming at least an entry (or
+// text) column, and a created (or date) column.
+func parseImportCSV(data []byte) ([]*pb.Journal, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read csv")
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing header")
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	field := func(record []string, names ...string) string {
+		for _, name := range names {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return record[i]
+			}
+		}
+		return ""
+	}
+
+	journals := []*pb.Journal{}
+	for _, record := range records[1:] {
+		journals = append(journals, &pb.Journal{
+			Title:   field(record, "title"),
+			Entry:   field(record, "entry", "text"),
+			Created: field(record, "created", "date"),
+		})
+	}
+	return journals, nil
+}
+
+func parseImportDate(date string) (time.Time, error) {
+	date = strings.TrimSpace(date)
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date '%s'", date)
+}
+
+// importJournals checks every parsed journal against what the user already
+// has, and what came earlier in the same upload, and inserts the new ones
+// unless this is a dry run.  an import is all or nothing.
+func (s *NotifyAppServer) importJournals(ctx context.Context, phoneNumber, format string, data []byte, dryRun bool) (*pb.ImportReport, error) {
+	journals, err := parseImport(format, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse import")
+	}
+
+	//a user's first data key is written on s.repo, which mustn't happen with
+	//the import's transaction open, so it's made now and cached for the rows
+	if !dryRun {
+		if _, _, err := s.activeDataKey(ctx, phoneNumber); err != nil {
+			return nil, errors.Wrap(err, "failed to get data key")
+		}
+	}
+
+	var report *pb.ImportReport
+	err = s.repo.InTx(ctx, func(tx repository.Repository) error {
+		report = &pb.ImportReport{DryRun: dryRun}
+		if !dryRun {
+			report.ImportId = uuid.NewV4().String()
+		}
+		seen := map[string]bool{}
+		for i, j := range journals {
+			row := &pb.ImportRow{Row: int32(i + 1), Title: j.Title, Created: j.Created}
+			report.Rows = append(report.Rows, row)
+
+			created, err := parseImportDate(j.Created)
+			if err != nil {
+				row.Status, row.Error = importStatusInvalid, err.Error()
+				report.Invalid++
+				continue
+			}
+			if strings.TrimSpace(j.Entry) == "" {
+				row.Status, row.Error = importStatusInvalid, "missing entry"
+				report.Invalid++
+				continue
+			}
+			j.PhoneNumber = phoneNumber
+			j.Created = created.Format(timeFormat)
+			row.Created = j.Created
+
+			hash := s.contentHash(phoneNumber, j.Title, j.Entry)
+			key := hash + created.Format("2006-01-02")
+			exists, err := tx.JournalExists(ctx, phoneNumber, hash, j.Created)
+			if err != nil {
+				return errors.Wrap(err, "failed to check for duplicate")
+			}
+			if exists || seen[key] {
+				row.Status = importStatusDuplicate
+				report.Duplicates++
+				continue
+			}
+			seen[key] = true
+
+			row.Status = importStatusNew
+			report.Imported++
+			if dryRun {
+				continue
+			}
+			if err := s.insertJournal(ctx, tx, j); err != nil {
+				return errors.Wrapf(err, "failed to insert row %d", row.Row)
+			}
+			row.JournalId = j.JournalId
+		}
+
+		if dryRun {
+			return nil
+		}
+		return errors.Wrap(tx.InsertJournalImport(ctx, phoneNumber, format, report), "failed to insert import")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *NotifyAppServer) ImportJournals(ctx context.Context, req *pb.ImportReq) (*pb.ImportReport, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !Contains(importFormats, req.Format) {
+		return nil, twirp.InvalidArgumentError("format", "invalid")
+	}
+	if int64(len(req.Data)) > maxImportSize {
+		return nil, twirp.InvalidArgumentError("data", "too large")
+	}
+
+	report, err := s.importJournals(ctx, phoneNumber, req.Format, req.Data, req.DryRun)
+	if err != nil {
+		Logger(ctx).Errorf("failed to import journals: %s", err)
+		return nil, twirp.InternalError("failed to import journals")
+	}
+	return report, nil
+}
+
+// ImportBodyMiddleware limits an upload to maxImportSize.  it goes before
+// AuthMiddleware, whose csrf check would otherwise parse up to 32MB of form.
+func (s *NotifyAppServer) ImportBodyMiddleware(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxImportSize {
+			Logger(r.Context()).Errorf("import of %d bytes is too large", r.ContentLength)
+			http.Error(w, "import too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+		f(w, r)
+	}
+}
+
+func (s *NotifyAppServer) PostImport(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	//the body is already limited by ImportBodyMiddleware
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		Logger(r.Context()).Errorf("failed to parse form: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	format := r.FormValue("format")
+	if !Contains(importFormats, format) {
+		Logger(r.Context()).Errorf("invalid import format: %s", format)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	f, _, err := r.FormFile("file")
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to get import file: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to read import file: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
+	report, err := s.importJournals(r.Context(), user.PhoneNumber, format, data, r.FormValue("dry_run") != "")
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to import journals: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	s.renderTemplate(w, r, "import", report)
+}
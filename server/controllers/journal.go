@@ -3,74 +3,203 @@ package controllers
 import (
 	"context"
 
+	gpb "github.com/golang/protobuf/ptypes/empty"
+	"github.com/mikerjacobi/notify-app/server/repository"
 	pb "github.com/mikerjacobi/notify-app/server/rpc"
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
+	"github.com/twitchtv/twirp"
 )
 
-func (s *NotifyAppServer) insertJournal(ctx context.Context, db Database, j *pb.Journal) error {
-	stmt, err := db.Prepare(`
-		INSERT INTO journals (journal_id, comms_id, phone_number, title, entry, created, updated)
-		VALUES (?, ?, ?, ?, ?, NOW(6), NOW(6))
-	`)
+// insertJournal inserts j through repo, which is s.repo or an import's
+// transaction, created now unless j.Created is set as it is for imported
+// entries.
+func (s *NotifyAppServer) insertJournal(ctx context.Context, repo repository.Repository, j *pb.Journal) error {
+	//the id is picked here, the ciphertext is bound to it
+	journalID := uuid.NewV4().String()
+	title, err := s.encrypt(ctx, j.PhoneNumber, journalField(journalID, "title"), j.Title)
 	if err != nil {
-		return errors.Wrap(err, "failed to prepare")
+		return errors.Wrap(err, "failed to encrypt title")
 	}
-	j.JournalId = uuid.NewV4().String()
-	if _, err = stmt.Exec(j.JournalId, j.CommsId, j.PhoneNumber, j.Title, j.Entry); err != nil {
-		return errors.Wrap(err, "failed to exec")
+	entry, err := s.encrypt(ctx, j.PhoneNumber, journalField(journalID, "entry"), j.Entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt entry")
+	}
+	sealed := *j
+	sealed.JournalId, sealed.Title, sealed.Entry = journalID, title, entry
+	err = repo.InTx(ctx, func(tx repository.Repository) error {
+		if err := tx.InsertJournal(ctx, &sealed, s.contentHash(j.PhoneNumber, j.Title, j.Entry)); err != nil {
+			return errors.Wrap(err, "failed to insert")
+		}
+		return tx.SetJournalTerms(ctx, j.PhoneNumber, journalID, s.journalTerms(j.PhoneNumber, j.Title, j.Entry))
+	})
+	if err != nil {
+		return err
+	}
+	j.JournalId = journalID
+	if err := repo.AddJournalTags(ctx, j.PhoneNumber, j.JournalId, parseHashtags(j.Entry), tagSourceHashtag); err != nil {
+		return errors.Wrap(err, "failed to add hashtags")
 	}
 	return nil
 }
 
-func (s *NotifyAppServer) updateJournal(ctx context.Context, db Database, j *pb.Journal) error {
-	stmt, err := db.Prepare(`
-		UPDATE journals SET updated=NOW(6), entry=?
-		WHERE journal_id=? AND phone_number=?
-	`)
+// updateJournal replaces j's entry, keeping the old one in journal_revisions.
+func (s *NotifyAppServer) updateJournal(ctx context.Context, j *pb.Journal) error {
+	//before the transaction, encrypting may write the user's data key
+	entry, err := s.encrypt(ctx, j.PhoneNumber, journalField(j.JournalId, "entry"), j.Entry)
 	if err != nil {
-		return errors.Wrap(err, "failed to prepare")
+		return errors.Wrap(err, "failed to encrypt entry")
 	}
-	if _, err = stmt.Exec(j.Entry, j.JournalId, j.PhoneNumber); err != nil {
-		return errors.Wrap(err, "failed to exec")
+	sealed := *j
+	sealed.Entry = entry
+	//the row is locked for the revision, the update and the terms, so a
+	//concurrent edit can't slip between them and lose its revision
+	err = s.repo.InTx(ctx, func(tx repository.Repository) error {
+		//the title is needed for the content hash, and this checks j is the user's
+		existing, err := tx.LockJournal(ctx, j.PhoneNumber, j.JournalId)
+		if err != nil {
+			return errors.Wrap(err, "failed to get journal")
+		}
+		if err := s.decryptJournal(ctx, existing); err != nil {
+			return errors.Wrap(err, "failed to decrypt journal")
+		}
+		if err := tx.InsertJournalRevision(ctx, j.PhoneNumber, j.JournalId); err != nil {
+			return errors.Wrap(err, "failed to insert revision")
+		}
+		if err := tx.UpdateJournalEntry(ctx, &sealed, s.contentHash(j.PhoneNumber, existing.Title, j.Entry)); err != nil {
+			return errors.Wrap(err, "failed to update")
+		}
+		return tx.SetJournalTerms(ctx, j.PhoneNumber, j.JournalId, s.journalTerms(j.PhoneNumber, existing.Title, j.Entry))
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.setJournalTags(ctx, j, parseHashtags(j.Entry), tagSourceHashtag); err != nil {
+		return errors.Wrap(err, "failed to set hashtags")
 	}
 	return nil
 }
 
-func (s *NotifyAppServer) deleteJournal(ctx context.Context, db Database, j *pb.Journal) error {
-	stmt, err := db.Prepare(`
-		DELETE FROM journals
-		WHERE journal_id=? AND phone_number=?
-	`)
+// getJournalEntries returns a page of a user's journals, optionally only those
+// with every word of title in theirs and that are tagged tag, and the cursor
+// of the next page if any.
+func (s *NotifyAppServer) getJournalEntries(ctx context.Context, phoneNumber string, page *repository.Page, title, tag string) ([]*pb.Journal, string, error) {
+	//titles are encrypted, they're matched on the blind index
+	terms := []string{}
+	for _, t := range tokenize(title) {
+		terms = append(terms, s.termHash(phoneNumber, titleTerm, t))
+	}
+	entries, nextCursor, err := s.repo.ListJournals(ctx, phoneNumber, page, normalizeTag(tag), terms)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, j := range entries {
+		if err := s.decryptJournal(ctx, j); err != nil {
+			return nil, "", err
+		}
+	}
+	return entries, nextCursor, nil
+}
+
+func (s *NotifyAppServer) getJournal(ctx context.Context, phoneNumber, journalID string) (*pb.Journal, error) {
+	j, err := s.repo.GetJournal(ctx, phoneNumber, journalID)
 	if err != nil {
-		return errors.Wrap(err, "failed to prepare")
+		return nil, err
 	}
-	if _, err = stmt.Exec(j.JournalId, j.PhoneNumber); err != nil {
-		return errors.Wrap(err, "failed to exec")
+	if err := s.decryptJournal(ctx, j); err != nil {
+		return nil, err
 	}
-	return nil
+	return j, nil
+}
+
+func (s *NotifyAppServer) ListJournals(ctx context.Context, req *pb.ListJournalsReq) (*pb.JournalList, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	page, arg, err := newPageQuery(req.Cursor, int(req.PageSize), req.CreatedAfter, req.CreatedBefore)
+	if err != nil {
+		Logger(ctx).Errorf("failed validation: %s", err)
+		return nil, twirp.InvalidArgumentError(arg, "invalid")
+	}
+
+	entries, nextCursor, err := s.getJournalEntries(ctx, phoneNumber, page, req.Title, req.Tag)
+	if err != nil {
+		Logger(ctx).Errorf("failed to get entries: %s", err)
+		return nil, twirp.InternalError("failed to list journals")
+	}
+	return &pb.JournalList{Journals: entries, NextCursor: nextCursor}, nil
 }
 
-func (s *NotifyAppServer) getJournalEntries(ctx context.Context, db Database, phoneNumber string) ([]*pb.Journal, error) {
-	stmt, err := db.Prepare(`SELECT journal_id,comms_id,phone_number,title,entry,created,updated 
-		FROM journals 
-		WHERE phone_number=?
-		ORDER BY created DESC`)
+func (s *NotifyAppServer) GetJournal(ctx context.Context, req *pb.Journal) (*pb.Journal, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to prepare")
+		return nil, err
 	}
-	rows, err := stmt.Query(phoneNumber)
+
+	j, err := s.getJournal(ctx, phoneNumber, req.JournalId)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to query")
-	}
-	defer rows.Close()
-	entries := []*pb.Journal{}
-	for rows.Next() {
-		j := &pb.Journal{}
-		if err := rows.Scan(&j.JournalId, &j.CommsId, &j.PhoneNumber, &j.Title, &j.Entry, &j.Created, &j.Updated); err != nil {
-			return nil, errors.Wrap(err, "failed to scan")
+		if errors.Cause(err) == errNotFound {
+			return nil, twirp.NotFoundError("journal not found")
 		}
-		entries = append(entries, j)
+		Logger(ctx).Errorf("failed to get journal: %s", err)
+		return nil, twirp.InternalError("failed to get journal")
+	}
+	return j, nil
+}
+
+func (s *NotifyAppServer) CreateJournal(ctx context.Context, req *pb.Journal) (*pb.Journal, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if req.Entry == "" {
+		return nil, twirp.InvalidArgumentError("entry", "is required")
+	}
+
+	journal := &pb.Journal{
+		PhoneNumber: phoneNumber,
+		Title:       req.Title,
+		Entry:       req.Entry,
+	}
+	if err := s.insertJournal(ctx, s.repo, journal); err != nil {
+		Logger(ctx).Errorf("failed to insert journal: %s", err)
+		return nil, twirp.InternalError("failed to create journal")
+	}
+	return s.GetJournal(ctx, journal)
+}
+
+func (s *NotifyAppServer) UpdateJournal(ctx context.Context, req *pb.Journal) (*pb.Journal, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	req.PhoneNumber = phoneNumber
+	if req.Entry == "" {
+		return nil, twirp.InvalidArgumentError("entry", "is required")
+	}
+
+	if _, err := s.GetJournal(ctx, req); err != nil {
+		return nil, err
+	}
+	if err := s.updateJournal(ctx, req); err != nil {
+		Logger(ctx).Errorf("failed to update journal: %s", err)
+		return nil, twirp.InternalError("failed to update journal")
+	}
+	return s.GetJournal(ctx, req)
+}
+
+func (s *NotifyAppServer) DeleteJournal(ctx context.Context, req *pb.Journal) (*gpb.Empty, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	journal := &pb.Journal{PhoneNumber: phoneNumber, JournalId: req.JournalId}
+	if err := s.repo.DeleteJournal(ctx, journal.PhoneNumber, journal.JournalId); err != nil {
+		Logger(ctx).Errorf("failed to delete journal: %s", err)
+		return nil, twirp.InternalError("failed to delete journal")
 	}
-	return entries, nil
+	return &gpb.Empty{}, nil
 }
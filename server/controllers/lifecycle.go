@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Start runs the background loops, NotifyLoop and PurgeLoop, until Shutdown.
+// ending ctx cancels them like Shutdown's deadline does.
+func (s *NotifyAppServer) Start(ctx context.Context) {
+	context.AfterFunc(ctx, s.cancel)
+	for _, loop := range []func(context.Context){s.NotifyLoop, s.PurgeLoop} {
+		s.goBackground(ctx, loop)
+	}
+}
+
+// goBackground runs fn as background work that Shutdown waits for.  it logs
+// with ctx's request id, or its own, but isn't cancelled with ctx.
+func (s *NotifyAppServer) goBackground(ctx context.Context, fn func(context.Context)) {
+	info := &requestInfo{id: uuid.NewV4().String()}
+	if parent, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		*info = *parent
+	}
+	s.loops.Add(1)
+	go func() {
+		defer s.loops.Done()
+		fn(context.WithValue(s.background, requestInfoKey{}, info))
+	}()
+}
+
+// Shutdown stops the background loops and waits until ctx's deadline for the
+// sends in flight to finish.  past the deadline their context is cancelled,
+// which aborts their store and twilio calls, and once they've returned the
+// store is closed.  the http server should be shut down first, so no request
+// is using the store.
+func (s *NotifyAppServer) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	defer s.cancel()
+
+	drained := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = errors.Wrap(ctx.Err(), "failed to drain notification sends")
+		s.cancel()
+		<-drained
+	}
+
+	if closeErr := s.repo.Close(); closeErr != nil && err == nil {
+		err = errors.Wrap(closeErr, "failed to close store")
+	}
+	return err
+}
+
+// stopping reports whether Shutdown has been called.
+func (s *NotifyAppServer) stopping() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait sleeps for d, it returns false early if ctx is done or the server is
+// shutting down.
+func (s *NotifyAppServer) wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.stop:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
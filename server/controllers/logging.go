@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestIDHeader carries the request id in from a proxy and back out to the
+// caller.
+const requestIDHeader = "X-Request-Id"
+
+// validRequestID keeps ids we're handed short and printable, anything else
+// gets a fresh one.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type requestInfoKey struct{}
+
+// requestInfo identifies a request, or a scheduler run, in its logs.  it's a
+// pointer in the context so the user AuthMiddleware finds is seen by the
+// middleware outside it too.
+type requestInfo struct {
+	id   string
+	user string
+}
+
+// WithRequestID starts ctx's logs for a request or job called id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, &requestInfo{id: id})
+}
+
+// RequestIDMiddleware gives each request an id, the caller's X-Request-Id if
+// it sent a sane one, and echoes it in the response.
+func RequestIDMiddleware(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewV4().String()
+		}
+		w.Header().Set(requestIDHeader, id)
+		f(w, r.WithContext(WithRequestID(r.Context(), id)))
+	}
+}
+
+// setLogUser names the user ctx's request is acting as in its logs.
+func setLogUser(ctx context.Context, phoneNumber string) {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.user = phoneNumber
+	}
+}
+
+// Logger is logrus with ctx's request id, user, trace and rpc as fields.
+func Logger(ctx context.Context) *logrus.Entry {
+	fields := logrus.Fields{}
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		fields["request_id"] = info.id
+		if info.user != "" {
+			fields["user"] = info.user
+		}
+	}
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		fields["trace_id"] = span.TraceID().String()
+	}
+	if method, ok := twirp.MethodName(ctx); ok {
+		fields["rpc"] = method
+	}
+	return logrus.WithFields(fields)
+}
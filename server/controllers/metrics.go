@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/husobee/vestigo"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/twitchtv/twirp"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notify_http_requests_total",
+		Help: "http requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "notify_http_request_duration_seconds",
+		Help:    "http request latency by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	twirpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notify_twirp_requests_total",
+		Help: "twirp calls by method and error code, ok if there was no error.",
+	}, []string{"method", "code"})
+	twirpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "notify_twirp_request_duration_seconds",
+		Help:    "twirp call latency by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+
+	schedulerLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "notify_scheduler_lag_seconds",
+		Help: "how long the oldest notification in the last scheduler batch waited past its time.",
+	})
+	schedulerBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "notify_scheduler_batch_size",
+		Help:    "notifications due per scheduler run.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 10),
+	})
+	schedulerLastRun = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "notify_scheduler_last_success_timestamp_seconds",
+		Help: "when the scheduler last finished a run.",
+	})
+
+	sendAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notify_send_attempts_total",
+		Help: "messages we tried to send by channel.",
+	}, []string{"channel"})
+	sendResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notify_sends_total",
+		Help: "messages sent by channel and result, success or failure.",
+	}, []string{"channel", "result"})
+
+	inboundMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "notify_inbound_messages_total",
+		Help: "messages users texted us by command.",
+	}, []string{"command"})
+)
+
+// channelSMS labels sends through twilio's messaging api.
+const channelSMS = "sms"
+
+// MetricsHandler serves the metrics for prometheus to scrape.
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterMetrics exports the store's connection pool stats, it's called once
+// per process.
+func (s *NotifyAppServer) RegisterMetrics() {
+	repo := s.repo
+	gauge := func(name, help string, value func() float64) {
+		prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "notify_db_" + name, Help: help}, value))
+	}
+	counter := func(name, help string, value func() float64) {
+		prometheus.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "notify_db_" + name, Help: help}, value))
+	}
+	gauge("max_open_connections", "most connections the pool opens.", func() float64 { return float64(repo.Stats().MaxOpenConnections) })
+	gauge("open_connections", "connections open, in use or idle.", func() float64 { return float64(repo.Stats().OpenConnections) })
+	gauge("in_use_connections", "connections in use.", func() float64 { return float64(repo.Stats().InUse) })
+	gauge("idle_connections", "idle connections.", func() float64 { return float64(repo.Stats().Idle) })
+	counter("wait_count_total", "times a query waited for a connection.", func() float64 { return float64(repo.Stats().WaitCount) })
+	counter("wait_duration_seconds_total", "time queries spent waiting for a connection.", func() float64 { return repo.Stats().WaitDuration.Seconds() })
+	counter("max_idle_closed_total", "connections closed for db-max-idle-conns.", func() float64 { return float64(repo.Stats().MaxIdleClosed) })
+	counter("max_lifetime_closed_total", "connections closed for db-conn-max-lifetime.", func() float64 { return float64(repo.Stats().MaxLifetimeClosed) })
+}
+
+// statusRecorder remembers the status a handler wrote.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// MetricsMiddleware counts and times requests by their route.
+func MetricsMiddleware(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		f(rec, r)
+		labels := prometheus.Labels{"method": r.Method, "route": routeOf(r), "status": strconv.Itoa(rec.status)}
+		httpRequests.With(labels).Inc()
+		httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	}
+}
+
+// routeOf is the route r matched, with its params put back, eg
+// /journal/:journal_id, so each route is one series however many ids it
+// sees.  twirp has its own metrics per rpc.
+func routeOf(r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, pb.NotifyAppPathPrefix) {
+		return pb.NotifyAppPathPrefix + "*"
+	}
+	segments := strings.Split(r.URL.Path, "/")
+	for _, name := range vestigo.ParamNames(r) {
+		name = strings.TrimPrefix(name, ":")
+		value := vestigo.Param(r, name)
+		for i, segment := range segments {
+			if value != "" && segment == value {
+				segments[i] = ":" + name
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+type twirpStartKey struct{}
+type twirpCodeKey struct{}
+
+func twirpRequestReceived(ctx context.Context) (context.Context, error) {
+	return context.WithValue(ctx, twirpStartKey{}, time.Now()), nil
+}
+
+func twirpError(ctx context.Context, err twirp.Error) context.Context {
+	return context.WithValue(ctx, twirpCodeKey{}, string(err.Code()))
+}
+
+func twirpResponseSent(ctx context.Context) {
+	method, _ := twirp.MethodName(ctx)
+	code, ok := ctx.Value(twirpCodeKey{}).(string)
+	if !ok {
+		code = "ok"
+	}
+	twirpRequests.WithLabelValues(method, code).Inc()
+	if start, ok := ctx.Value(twirpStartKey{}).(time.Time); ok {
+		twirpDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// observeSchedulerBatch records a scheduler run's batch, lag is how late its
+// oldest notification is.
+func observeSchedulerBatch(size int, lag time.Duration) {
+	schedulerBatchSize.Observe(float64(size))
+	schedulerLag.Set(lag.Seconds())
+}
+
+// observeSend counts a send attempt on channel and whether it worked.
+func observeSend(channel string, err error) {
+	sendAttempts.WithLabelValues(channel).Inc()
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	sendResults.WithLabelValues(channel, result).Inc()
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status | baseline <version>"
+
+// Migrate runs the migrate command against the configured store, printing
+// what it did to out.  it's shared by the server and notifyctl.
+func Migrate(config Configuration, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	repo, err := OpenRepository(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to open store")
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := repo.MigrateUp(ctx)
+		for _, m := range applied {
+			fmt.Fprintf(out, "applied %d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("steps '%s' is invalid", args[1])
+			}
+		}
+		reverted, err := repo.MigrateDown(ctx, steps)
+		for _, m := range reverted {
+			fmt.Fprintf(out, "reverted %d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		status, err := repo.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, m := range status {
+			applied := m.Applied
+			if applied == "" {
+				applied = "pending"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", m.Version, m.Name, applied)
+		}
+		return w.Flush()
+	case "baseline":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("version '%s' is invalid", args[1])
+		}
+		return repo.Baseline(ctx, version)
+	default:
+		return errors.New(migrateUsage)
+	}
+}
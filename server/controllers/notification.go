@@ -9,81 +9,23 @@ import (
 	gpb "github.com/golang/protobuf/ptypes/empty"
 	pb "github.com/mikerjacobi/notify-app/server/rpc"
 	"github.com/pkg/errors"
-	uuid "github.com/satori/go.uuid"
-	"github.com/sirupsen/logrus"
 	"github.com/twitchtv/twirp"
 )
 
-func (s *NotifyAppServer) getNotification(ctx context.Context, db Database, notificationID string) (*pb.Notification, error) {
-	stmt, err := db.Prepare(`
-		SELECT name,type,template
-		FROM notifications 
-		WHERE notification_id=?`)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to prepare")
-	}
-	rows, err := stmt.Query(notificationID)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to query")
-	}
-	defer rows.Close()
-	notification := &pb.Notification{NotificationId: notificationID}
-	if rows.Next() {
-		if err := rows.Scan(&notification.Name, &notification.Type, &notification.Template); err != nil {
-			return nil, errors.Wrap(err, "failed to scan")
-		}
-	} else {
-		return nil, fmt.Errorf("notification id '%s' not found", notificationID)
-	}
-	return notification, nil
-}
-
-func (s *NotifyAppServer) getNotifications(ctx context.Context, db Database) ([]*pb.Notification, error) {
-	stmt, err := db.Prepare(`
-		SELECT notification_id,name,type,template
-		FROM notifications`)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to prepare")
-	}
-	rows, err := stmt.Query()
+func (s *NotifyAppServer) AddUserNotification(ctx context.Context, req *pb.UserNotification) (*gpb.Empty, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to query")
+		return nil, err
 	}
-	defer rows.Close()
-	notifications := []*pb.Notification{}
-	for rows.Next() {
-		p := &pb.Notification{}
-		if err := rows.Scan(&p.NotificationId, &p.Name, &p.Type, &p.Template); err != nil {
-			return nil, errors.Wrap(err, "failed to scan")
-		}
-		notifications = append(notifications, p)
-	}
-	return notifications, nil
-}
+	req.PhoneNumber = phoneNumber
 
-func (s *NotifyAppServer) insertNotification(ctx context.Context, db Database, p *pb.Notification) error {
-	p.NotificationId = uuid.NewV4().String()
-	stmt, err := db.Prepare(`
-		INSERT INTO notifications (notification_id, name, type, template, created, updated)
-		VALUES (?, ?, ?, ?, NOW(6), NOW(6))
-	`)
-	if err != nil {
-		return errors.Wrap(err, "failed to prepare")
-	}
-	if _, err = stmt.Exec(p.NotificationId, p.Name, p.Type, p.Template); err != nil {
-		return errors.Wrap(err, "failed to exec")
-	}
-	return nil
-}
-
-func (s *NotifyAppServer) AddUserNotification(ctx context.Context, req *pb.UserNotification) (*gpb.Empty, error) {
 	if arg, err := s.validateAddUserNotification(ctx, req); err != nil {
-		logrus.Errorf("failed validation: %s", err)
+		Logger(ctx).Errorf("failed validation: %s", err)
 		return nil, twirp.InvalidArgumentError(arg, "invalid")
 	}
 
-	if err := s.insertUserNotification(ctx, s.DB, req); err != nil {
-		logrus.Error("failed to insert user notification: %s", err)
+	if err := s.repo.InsertUserNotification(ctx, req); err != nil {
+		Logger(ctx).Errorf("failed to insert user notification: %s", err)
 		return nil, twirp.InternalError("failed to add user notification")
 	}
 	return &gpb.Empty{}, nil
@@ -93,9 +35,11 @@ func (s *NotifyAppServer) validateAddUserNotification(ctx context.Context, req *
 	if !govalidator.IsUUID(req.NotificationId) {
 		return "notification_id", fmt.Errorf("notification_id '%s' is invalid", req.NotificationId)
 	}
-	if len(req.PhoneNumber) != 10 || !govalidator.IsNumeric(req.PhoneNumber) {
-		return "phone_number", fmt.Errorf("phone_number '%s' is invalid", req.PhoneNumber)
+	phoneNumber, err := normalizePhoneNumber(req.PhoneNumber, s.config.DefaultRegion)
+	if err != nil {
+		return "phone_number", errors.Wrapf(err, "phone_number '%s' is invalid", req.PhoneNumber)
 	}
+	req.PhoneNumber = phoneNumber
 	if _, err := time.Parse(timeFormat, req.NextNotificationTime); err != nil {
 		return "next_notification_time", errors.Wrapf(err, "next_notification_time '%s' is invalid", req.NextNotificationTime)
 	}
@@ -107,118 +51,167 @@ func (s *NotifyAppServer) validateAddUserNotification(ctx context.Context, req *
 	return "", nil
 }
 
-func (s *NotifyAppServer) insertUserNotification(ctx context.Context, db Database, up *pb.UserNotification) error {
-	stmt, err := db.Prepare(`
-		INSERT INTO user_notifications (notification_id, phone_number, next_notification_time, frequency, created, updated)
-		VALUES (?, ?, ?, ?, NOW(6), NOW(6))
-	`)
+// claimUserNotification moves a due notification on to its next time, or
+// deletes it if it doesn't recur.  ErrNotFound means another run already has.
+func (s *NotifyAppServer) claimUserNotification(ctx context.Context, notification *pb.UserNotification) error {
+	currNotificationTime, err := time.Parse(timeFormat, notification.NextNotificationTime)
 	if err != nil {
-		return errors.Wrap(err, "failed to prepare")
+		return errors.Wrapf(err, "failed to parse next notification time")
 	}
-	if _, err = stmt.Exec(up.NotificationId, up.PhoneNumber, up.NextNotificationTime, up.Frequency); err != nil {
-		return errors.Wrap(err, "failed to exec")
+	if notification.Frequency == "" {
+		//notification is not recurring, there's nothing to restore so skip the trash
+		return s.repo.AdvanceUserNotification(ctx, notification.PhoneNumber, notification.NotificationId, currNotificationTime, time.Time{})
 	}
-	return nil
-}
 
-func (s *NotifyAppServer) deleteUserNotification(ctx context.Context, db Database, phoneNumber, notificationID string) error {
-	stmt, err := db.Prepare(`
-		DELETE FROM user_notifications
-		WHERE phone_number=?
-		AND notification_id=?
-	`)
+	frequency, err := parseDuration(notification.Frequency)
 	if err != nil {
-		return errors.Wrap(err, "failed to prepare")
+		return errors.Wrapf(err, "failed to parse frequency")
 	}
-	if _, err = stmt.Exec(phoneNumber, notificationID); err != nil {
-		return errors.Wrap(err, "failed to exec")
+
+	nextNotificationTime := currNotificationTime.Add(frequency)
+	if now := s.now(ctx); nextNotificationTime.Before(now) {
+		//this is the case that prevents notifier from running repeatedly on really old notifications
+		nextNotificationTime = now.Add(frequency)
 	}
-	return nil
+
+	return s.repo.AdvanceUserNotification(ctx, notification.PhoneNumber, notification.NotificationId, currNotificationTime, nextNotificationTime)
 }
 
-func (s *NotifyAppServer) getUserNotifications(ctx context.Context, db Database, phoneNumber string) ([]*pb.UserNotification, error) {
-	stmt, err := db.Prepare(`
-		SELECT up.notification_id,up.phone_number,up.next_notification_time,up.frequency,p.template,p.type,p.name
-		FROM user_notifications up, notifications p
-		WHERE up.phone_number = ?
-		AND up.notification_id=p.notification_id`)
+func (s *NotifyAppServer) ListNotifications(ctx context.Context, empty *gpb.Empty) (*pb.NotificationList, error) {
+	notifications, err := s.listNotifications(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to prepare")
+		Logger(ctx).Errorf("failed to get notifications: %s", err)
+		return nil, twirp.InternalError("failed to list notifications")
 	}
-	rows, err := stmt.Query(phoneNumber)
+	return &pb.NotificationList{Notifications: notifications}, nil
+}
+
+// listNotifications leaves out alerts, like the lockout and login code
+// texts, which are only sent by the server and can't be subscribed to.
+func (s *NotifyAppServer) listNotifications(ctx context.Context) ([]*pb.Notification, error) {
+	all, err := s.repo.ListNotifications(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to query")
-	}
-	defer rows.Close()
-	userNotifications := []*pb.UserNotification{}
-	for rows.Next() {
-		up := &pb.UserNotification{Notification: &pb.Notification{}}
-		if err := rows.Scan(&up.NotificationId, &up.PhoneNumber, &up.NextNotificationTime, &up.Frequency, &up.Notification.Template, &up.Notification.Type, &up.Notification.Name); err != nil {
-			return nil, errors.Wrap(err, "failed to scan")
+		return nil, err
+	}
+	notifications := []*pb.Notification{}
+	for _, n := range all {
+		if n.Type != "alert" {
+			notifications = append(notifications, n)
 		}
-		userNotifications = append(userNotifications, up)
 	}
-	return userNotifications, nil
+	return notifications, nil
 }
 
-func (s *NotifyAppServer) getAllUserNotifications(ctx context.Context, db Database) ([]*pb.UserNotification, error) {
-	stmt, err := db.Prepare(`
-		SELECT up.notification_id,up.phone_number,up.next_notification_time,up.frequency,p.template,p.type,p.name
-		FROM user_notifications up, notifications p
-		WHERE up.next_notification_time <= DATE_SUB(NOW(6), INTERVAL 15 SECOND)
-		AND up.notification_id=p.notification_id`)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to prepare")
+// CreateNotification adds a prompt or reminder anyone can subscribe to, so
+// it's admin only.
+func (s *NotifyAppServer) CreateNotification(ctx context.Context, req *pb.Notification) (*pb.Notification, error) {
+	if req.Type != "prompt" && req.Type != "reminder" {
+		return nil, twirp.InvalidArgumentError("type", "must be prompt or reminder")
 	}
-	rows, err := stmt.Query()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to query")
-	}
-	defer rows.Close()
-	userNotifications := []*pb.UserNotification{}
-	for rows.Next() {
-		up := &pb.UserNotification{Notification: &pb.Notification{}}
-		if err := rows.Scan(&up.NotificationId, &up.PhoneNumber, &up.NextNotificationTime, &up.Frequency, &up.Notification.Template, &up.Notification.Type, &up.Notification.Name); err != nil {
-			return nil, errors.Wrap(err, "failed to scan")
+	if req.Template == "" {
+		return nil, twirp.InvalidArgumentError("template", "is required")
+	}
+	if req.DefaultTag != "" {
+		if req.DefaultTag = normalizeTag(req.DefaultTag); req.DefaultTag == "" {
+			return nil, twirp.InvalidArgumentError("default_tag", "invalid")
 		}
-		userNotifications = append(userNotifications, up)
 	}
-	return userNotifications, nil
+
+	if err := s.repo.InsertNotification(ctx, req); err != nil {
+		Logger(ctx).Errorf("failed to insert notification: %s", err)
+		return nil, twirp.InternalError("failed to create notification")
+	}
+	return req, nil
 }
 
-func (s *NotifyAppServer) updateUserNotification(ctx context.Context, db Database, notification *pb.UserNotification) error {
-	stmt, err := db.Prepare(`
-		UPDATE user_notifications 
-		SET updated=NOW(6), next_notification_time=? 
-		WHERE notification_id=?`)
+// UpdateNotification edits a prompt or reminder for everyone subscribed to it,
+// so it's admin only.
+func (s *NotifyAppServer) UpdateNotification(ctx context.Context, req *pb.Notification) (*pb.Notification, error) {
+	if req.Template == "" {
+		return nil, twirp.InvalidArgumentError("template", "is required")
+	}
+	if req.DefaultTag != "" {
+		if req.DefaultTag = normalizeTag(req.DefaultTag); req.DefaultTag == "" {
+			return nil, twirp.InvalidArgumentError("default_tag", "invalid")
+		}
+	}
+
+	existing, err := s.repo.GetNotification(ctx, req.NotificationId)
 	if err != nil {
-		return errors.Wrap(err, "failed to prepare")
+		Logger(ctx).Errorf("failed to get notification: %s", err)
+		return nil, twirp.NotFoundError("notification not found")
 	}
-	currNotificationTime, err := time.Parse(timeFormat, notification.NextNotificationTime)
+	if existing.Type != "prompt" && existing.Type != "reminder" {
+		return nil, twirp.InvalidArgumentError("notification_id", "system notifications can't be edited")
+	}
+	req.Type = existing.Type
+
+	if err := s.repo.UpdateNotification(ctx, req); err != nil {
+		Logger(ctx).Errorf("failed to update notification: %s", err)
+		return nil, twirp.InternalError("failed to update notification")
+	}
+	return req, nil
+}
+
+func (s *NotifyAppServer) ListUserNotifications(ctx context.Context, req *pb.ListUserNotificationsReq) (*pb.UserNotificationList, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
 	if err != nil {
-		return errors.Wrapf(err, "failed to parse next notification time")
+		return nil, err
 	}
-	if notification.Frequency == "" {
-		//notification is not recurring
-		if err := s.deleteUserNotification(ctx, db, notification.PhoneNumber, notification.NotificationId); err != nil {
-			return errors.Wrapf(err, "failed to delete one time notification: %+v", notification)
+
+	userNotifications, err := s.repo.ListUserNotifications(ctx, phoneNumber)
+	if err != nil {
+		Logger(ctx).Errorf("failed to get user notifications: %s", err)
+		return nil, twirp.InternalError("failed to list user notifications")
+	}
+	return &pb.UserNotificationList{UserNotifications: userNotifications}, nil
+}
+
+func (s *NotifyAppServer) UpdateUserNotification(ctx context.Context, req *pb.UserNotification) (*pb.UserNotification, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	req.PhoneNumber = phoneNumber
+
+	if arg, err := s.validateAddUserNotification(ctx, req); err != nil {
+		Logger(ctx).Errorf("failed validation: %s", err)
+		return nil, twirp.InvalidArgumentError(arg, "invalid")
+	}
+
+	if _, err := s.repo.GetUserNotification(ctx, req.PhoneNumber, req.NotificationId); err != nil {
+		if errors.Cause(err) == errNotFound {
+			return nil, twirp.NotFoundError("user notification not found")
 		}
-		return nil
+		Logger(ctx).Errorf("failed to get user notification: %s", err)
+		return nil, twirp.InternalError("failed to update user notification")
 	}
 
-	frequency, err := parseDuration(notification.Frequency)
+	if err := s.repo.UpdateUserNotificationSchedule(ctx, req); err != nil {
+		Logger(ctx).Errorf("failed to update user notification: %s", err)
+		return nil, twirp.InternalError("failed to update user notification")
+	}
+
+	up, err := s.repo.GetUserNotification(ctx, req.PhoneNumber, req.NotificationId)
 	if err != nil {
-		return errors.Wrapf(err, "failed to parse frequency")
+		Logger(ctx).Errorf("failed to get user notification: %s", err)
+		return nil, twirp.InternalError("failed to update user notification")
 	}
+	return up, nil
+}
 
-	nextNotificationTime := currNotificationTime.Add(frequency)
-	if nextNotificationTime.Before(now(db)) {
-		//this is the case that prevents notifier from running repeatedly on really old notifications
-		nextNotificationTime = now(s.DB).Add(frequency)
+func (s *NotifyAppServer) DeleteUserNotification(ctx context.Context, req *pb.UserNotification) (*gpb.Empty, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !govalidator.IsUUID(req.NotificationId) {
+		return nil, twirp.InvalidArgumentError("notification_id", "invalid")
 	}
 
-	if _, err = stmt.Exec(nextNotificationTime, notification.NotificationId); err != nil {
-		return errors.Wrap(err, "failed to exec")
+	if err := s.repo.DeleteUserNotification(ctx, phoneNumber, req.NotificationId); err != nil {
+		Logger(ctx).Errorf("failed to delete user notification: %s", err)
+		return nil, twirp.InternalError("failed to delete user notification")
 	}
-	return nil
+	return &gpb.Empty{}, nil
 }
@@ -2,41 +2,111 @@ package controllers
 
 import (
 	"context"
-	"database/sql"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
-	"strings"
+	"sync"
 	"time"
 
-	"github.com/asaskevich/govalidator"
-	_ "github.com/go-sql-driver/mysql"
 	gpb "github.com/golang/protobuf/ptypes/empty"
+	"github.com/mikerjacobi/notify-app/server/repository"
 	pb "github.com/mikerjacobi/notify-app/server/rpc"
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type Configuration struct {
-	DBSecretsPath     string
-	TwilioSecretsPath string
+	// Addr is the host:port the http server listens on, PublicURL is where
+	// it's reached, which twilio's request signatures cover and export links
+	// point at
+	Addr      string
+	PublicURL string
+	// MetricsAddr is the host:port /metrics and /status/scheduler are served
+	// on, apart from the public server.  they aren't served if it's empty
+	MetricsAddr string
+	// TrustedProxies are the ips and cidrs, comma separated, of the load
+	// balancers whose X-Forwarded-* headers are believed
+	TrustedProxies     string
+	DBSecretsPath      string
+	TwilioSecretsPath  string
+	SessionSecretsPath string
+	KeySecretsPath     string
+	DefaultRegion      string
+	// TrashRetention is how long deleted journals and notifications can be
+	// restored before they are purged
+	TrashRetention time.Duration
+	// BlobDir is where the default file BlobStore keeps attachments, unless
+	// Blobs is set
+	BlobDir string
+	Blobs   BlobStore
+	// Store is mysql, sqlite or memory, unless Repository is set.  mysql is
+	// reached with DBSecretsPath, sqlite keeps its database at SQLitePath
+	Store      string
+	SQLitePath string
+	Repository repository.Repository
+	// AutoMigrate applies pending schema migrations at startup
+	AutoMigrate bool
+	// DBName is the mysql database, DBPool sizes the connections to it
+	DBName string
+	DBPool repository.Pool
+	// LoopInterval is how often NotifyLoop sends the notifications due
+	LoopInterval time.Duration
+	// ShutdownTimeout is how long in-flight requests and sends have to finish
+	// on shutdown
+	ShutdownTimeout time.Duration
+	// TemplateDir holds the client's templates and static files, instead of
+	// the ones embedded in the binary.  ReloadTemplates reparses them when
+	// they change, for development
+	TemplateDir     string
+	ReloadTemplates bool
+	// TraceExporter is otlp, stdout or none.  otlp goes to TraceEndpoint
+	TraceExporter    string
+	TraceEndpoint    string
+	TraceSampleRatio float64
 	TwilioConfig
+	SessionConfig
+	KeyConfig
 }
 
 type NotifyAppServer struct {
 	config Configuration
 	client *http.Client
-	*sql.DB
+	blobs  BlobStore
+	keys   *keyring
+	repo   repository.Repository
+	// templates are the parsed pages renderTemplate executes
+	templates *templates
+	// proxies are the parsed TrustedProxies
+	proxies []*net.IPNet
+	// stop is closed by Shutdown to end the background loops, which loops
+	// waits on.  background is the loops' context, cancelled if they're
+	// still running at Shutdown's deadline
+	stop       chan struct{}
+	stopOnce   sync.Once
+	background context.Context
+	cancel     context.CancelFunc
+	// mediaJobs limits how many replies' media download at once
+	mediaJobs chan struct{}
+	loops     sync.WaitGroup
+	// lastTrigger is when triggerNotifications last succeeded
+	statusMu    sync.Mutex
+	lastTrigger time.Time
 }
 
 var (
 	birthdayFormat = "2006-01-02"
 	timeFormat     = "2006-01-02 15:04:05"
+	// clientTimeout bounds each call to twilio, so a hung one can't hold up a
+	// send or shutdown
+	clientTimeout = 30 * time.Second
 )
 
 func NewNotifyAppServer(config Configuration) (*NotifyAppServer, error) {
@@ -47,7 +117,88 @@ func NewNotifyAppServer(config Configuration) (*NotifyAppServer, error) {
 	if err := json.Unmarshal(twilio, &config); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal twilio")
 	}
-	//connect to database
+	session, err := ioutil.ReadFile(config.SessionSecretsPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read session secrets")
+	}
+	if err := json.Unmarshal(session, &config.SessionConfig); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal session secrets")
+	}
+	if len(config.DeviceKey) < 32 {
+		return nil, fmt.Errorf("device_key must be at least 32 characters")
+	}
+	keyFile, err := ioutil.ReadFile(config.KeySecretsPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read key secrets")
+	}
+	if err := json.Unmarshal(keyFile, &config.KeyConfig); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal key secrets")
+	}
+	keys, err := newKeyring(config.KeyConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load master keys")
+	}
+	templates, err := loadTemplates(clientFiles(config.TemplateDir), config.ReloadTemplates)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load templates")
+	}
+
+	repo := config.Repository
+	if repo == nil {
+		if repo, err = OpenRepository(config); err != nil {
+			return nil, errors.Wrapf(err, "failed to open %s store", config.Store)
+		}
+	}
+	if config.AutoMigrate {
+		applied, err := repo.MigrateUp(context.Background())
+		for _, m := range applied {
+			logrus.Infof("applied migration %d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to migrate")
+		}
+	}
+
+	blobs := config.Blobs
+	if blobs == nil {
+		if blobs, err = NewFileBlobStore(config.BlobDir); err != nil {
+			return nil, errors.Wrap(err, "failed to create blob store")
+		}
+	}
+
+	proxies, err := parseProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse trusted proxies")
+	}
+
+	background, cancel := context.WithCancel(context.Background())
+	c := &NotifyAppServer{
+		config:     config,
+		client:     &http.Client{Timeout: clientTimeout},
+		proxies:    proxies,
+		blobs:      blobs,
+		keys:       keys,
+		repo:       repo,
+		templates:  templates,
+		stop:       make(chan struct{}),
+		background: background,
+		cancel:     cancel,
+		mediaJobs:  make(chan struct{}, maxMediaJobs),
+	}
+	return c, nil
+}
+
+// OpenRepository opens the store config.Store names.
+func OpenRepository(config Configuration) (repository.Repository, error) {
+	switch config.Store {
+	case "sqlite":
+		return repository.OpenSQLite(config.SQLitePath)
+	case "memory":
+		return repository.NewMemory(), nil
+	case "", "mysql":
+	default:
+		return nil, fmt.Errorf("store '%s' is unhandled", config.Store)
+	}
 	dbData := struct {
 		Username string `json:"user"`
 		Password string `json:"password"`
@@ -60,48 +211,36 @@ func NewNotifyAppServer(config Configuration) (*NotifyAppServer, error) {
 	if err := json.Unmarshal(dbFile, &dbData); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal db data")
 	}
-	connStr := fmt.Sprintf("%s:%s@tcp(%s)/notify", dbData.Username, dbData.Password, dbData.Host)
-	db, err := sql.Open("mysql", connStr)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to open dbconn to %s", dbData.Host)
-	}
-	db.SetConnMaxLifetime(time.Second * 10)
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(10)
-
-	c := &NotifyAppServer{
-		config: config,
-		client: http.DefaultClient,
-		DB:     db,
-	}
-	return c, nil
+	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s", dbData.Username, dbData.Password, dbData.Host, config.DBName)
+	repo, err := repository.OpenMySQL(dsn, config.DBPool)
+	return repo, errors.Wrapf(err, "failed to connect to %s", dbData.Host)
 }
 
 func (s *NotifyAppServer) CreateAccount(ctx context.Context, req *pb.CreateAccountReq) (*pb.CreateAccountResp, error) {
 	if arg, err := s.validateCreateAccount(ctx, req); err != nil {
-		logrus.Errorf("failed validation: %s", err)
+		Logger(ctx).Errorf("failed validation: %s", err)
 		return nil, twirp.InvalidArgumentError(arg, "invalid")
 	}
 
-	if err := s.insertUser(ctx, s.DB, req.User); err != nil {
-		logrus.Error("failed to insert account: %s", err)
+	if err := s.insertUser(ctx, req.User); err != nil {
+		Logger(ctx).Errorf("failed to insert account: %s", err)
 		return nil, twirp.InternalError("failed to create account")
 	}
 
 	registerNotificationID := "deaabd59-0d15-4f44-a3a8-1e3f920a3710"
-	msg, err := s.populateTemplateByID(ctx, s.DB, registerNotificationID, nil)
+	msg, err := s.populateTemplateByID(ctx, registerNotificationID, nil)
 	if err != nil {
-		logrus.Error("failed to populate template: %s", err)
+		Logger(ctx).Errorf("failed to populate template: %s", err)
 		return nil, twirp.InternalError("failed to create account")
 	}
 
 	if err := s.sendSMS(ctx, req.User.PhoneNumber, msg); err != nil {
-		logrus.Error("failed to send sms: %s", err)
+		Logger(ctx).Errorf("failed to send sms: %s", err)
 		return nil, twirp.InternalError("failed to create account")
 	}
 	comm := &pb.Communication{From: s.config.From, To: req.User.PhoneNumber, Message: msg}
-	if err := s.insertCommunication(ctx, s.DB, comm); err != nil {
-		logrus.Warn("failed to insert comms: %s", err)
+	if err := s.insertCommunication(ctx, comm); err != nil {
+		Logger(ctx).Warnf("failed to insert comms: %s", err)
 	}
 	return &pb.CreateAccountResp{Success: true}, nil
 }
@@ -110,9 +249,15 @@ func (s *NotifyAppServer) validateCreateAccount(ctx context.Context, req *pb.Cre
 	if req.User == nil {
 		return "user", fmt.Errorf("user object missing")
 	}
-	if len(req.User.PhoneNumber) != 10 || !govalidator.IsNumeric(req.User.PhoneNumber) {
-		return "phone_number", fmt.Errorf("phone_number '%s' is invalid", req.User.PhoneNumber)
+	region := req.Region
+	if region == "" {
+		region = s.config.DefaultRegion
+	}
+	phoneNumber, err := normalizePhoneNumber(req.User.PhoneNumber, region)
+	if err != nil {
+		return "phone_number", errors.Wrapf(err, "phone_number '%s' is invalid", req.User.PhoneNumber)
 	}
+	req.User.PhoneNumber = phoneNumber
 	if req.User.Password != req.PasswordRepeat {
 		return "password", fmt.Errorf("passwords don't match")
 	}
@@ -128,74 +273,31 @@ func (s *NotifyAppServer) validateCreateAccount(ctx context.Context, req *pb.Cre
 	return "", nil
 }
 
-func (s *NotifyAppServer) insertUser(ctx context.Context, db Database, user *pb.User) error {
-	stmt, err := db.Prepare(`
-		INSERT INTO users (phone_number, name, hashword, birthday, verified, created, updated)
-		VALUES (?, ?, ?, ?, 0, NOW(6), NOW(6))
-	`)
-	if err != nil {
-		return errors.Wrap(err, "failed to prepare")
-	}
+// insertUser stores user with a bcrypt hash of their password.
+func (s *NotifyAppServer) insertUser(ctx context.Context, user *pb.User) error {
 	hashwordBytes, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate hashword")
 	}
-	hashword := base64.StdEncoding.EncodeToString(hashwordBytes)
-	if _, err = stmt.Exec(user.PhoneNumber, user.Name, hashword, user.Birthday); err != nil {
-		return errors.Wrap(err, "failed to exec")
-	}
-	return nil
-}
-
-func (s *NotifyAppServer) getUser(ctx context.Context, db Database, phoneNumber string) (*pb.User, error) {
-	stmt, err := db.Prepare(`SELECT hashword,name,birthday,verified,session_id FROM users WHERE phone_number=?`)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to prepare")
-	}
-	rows, err := stmt.Query(phoneNumber)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to query")
-	}
-	defer rows.Close()
-	user := &pb.User{PhoneNumber: phoneNumber}
-	if rows.Next() {
-		if err := rows.Scan(&user.Password, &user.Name, &user.Birthday, &user.Verified, &user.SessionId); err != nil {
-			return nil, errors.Wrap(err, "failed to scan")
-		}
-	} else {
-		return nil, fmt.Errorf("number '%s' not found", phoneNumber)
-	}
-	return user, nil
-}
-
-func (s *NotifyAppServer) updateUser(ctx context.Context, db Database, user *pb.User) error {
-	stmt, err := db.Prepare(`
-		UPDATE users SET verified=?,session_id=?,updated=NOW(6)
-		WHERE phone_number=?
-	`)
-	if err != nil {
-		return errors.Wrap(err, "failed to prepare")
-	}
-	if _, err = stmt.Exec(user.Verified, user.SessionId, user.PhoneNumber); err != nil {
-		return errors.Wrap(err, "failed to exec")
-	}
-	return nil
+	hashed := *user
+	hashed.Password = base64.StdEncoding.EncodeToString(hashwordBytes)
+	return s.repo.InsertUser(ctx, &hashed)
 }
 
 func (s *NotifyAppServer) verifyUser(ctx context.Context, user *pb.User) error {
 	if user.Verified {
-		logrus.Infof("user %s already registered", user.PhoneNumber)
+		Logger(ctx).Infof("user %s already registered", user.PhoneNumber)
 		return nil
 	}
 
 	user.Verified = true
-	if err := s.updateUser(ctx, s.DB, user); err != nil {
+	if err := s.repo.UpdateUser(ctx, user); err != nil {
 		return errors.Wrap(err, "failed to verify user")
 	}
 
 	payload := &regAckPayload{user.Name}
 	regAckNotificationID := "81a36dd3-8301-410c-af35-0b2a87cdd921"
-	msg, err := s.populateTemplateByID(ctx, s.DB, regAckNotificationID, payload)
+	msg, err := s.populateTemplateByID(ctx, regAckNotificationID, payload)
 	if err != nil {
 		return errors.Wrap(err, "failed to populate regack tmpl")
 	}
@@ -204,115 +306,137 @@ func (s *NotifyAppServer) verifyUser(ctx context.Context, user *pb.User) error {
 		return errors.Wrap(err, "failed to send sms")
 	}
 	comm := &pb.Communication{From: s.config.From, To: user.PhoneNumber, Message: msg, NotificationId: regAckNotificationID}
-	if err := s.insertCommunication(ctx, s.DB, comm); err != nil {
-		logrus.Warn("failed to insert comms: %s", err)
+	if err := s.insertCommunication(ctx, comm); err != nil {
+		Logger(ctx).Warnf("failed to insert comms: %s", err)
 	}
 	return nil
 }
 
-func (s *NotifyAppServer) NotifyLoop() {
-	ctx := context.Background()
+// NotifyLoop sends the due notifications every LoopInterval until ctx is done
+// or the server is shut down.
+func (s *NotifyAppServer) NotifyLoop(ctx context.Context) {
 	for {
-		time.Sleep(15 * time.Second)
+		if !s.wait(ctx, s.config.LoopInterval) {
+			return
+		}
 
-		if err := s.triggerNotifications(ctx); err != nil {
-			logrus.Errorf("failed to auto trigger notifications: %s", err)
+		runCtx := WithRequestID(ctx, uuid.NewV4().String())
+		if err := s.triggerNotifications(runCtx); err != nil {
+			Logger(runCtx).Errorf("failed to auto trigger notifications: %s", err)
 		}
 	}
 }
 
 func (s *NotifyAppServer) TriggerNotifications(ctx context.Context, empty *gpb.Empty) (*gpb.Empty, error) {
 	if err := s.triggerNotifications(ctx); err != nil {
-		logrus.Errorf("failed to http trigger notifications: %s", err)
+		Logger(ctx).Errorf("failed to http trigger notifications: %s", err)
 		return nil, twirp.InternalError("failed to trigger notifications")
 	}
 	return &gpb.Empty{}, nil
 }
 
-func (s *NotifyAppServer) triggerNotifications(ctx context.Context) error {
-	notifications, err := s.getAllUserNotifications(ctx, s.DB)
+// dueDelay holds a notification back for a moment past its time, as the
+// scheduler always has.
+const dueDelay = 15 * time.Second
+
+// dueBefore is the cutoff at now for a notification to be due, shared by the
+// scheduler and its status so they count the same ones.
+func dueBefore(now time.Time) time.Time {
+	return now.Add(-dueDelay)
+}
+
+func (s *NotifyAppServer) triggerNotifications(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "scheduler tick")
+	defer func() { endSpan(span, err) }()
+
+	now := s.now(ctx)
+	notifications, err := s.repo.ListDueUserNotifications(ctx, dueBefore(now))
 	if err != nil {
 		return errors.Wrapf(err, "failed to get user notifications")
 	}
+	lag := time.Duration(0)
+	if len(notifications) > 0 {
+		//due notifications are sorted by next notification time
+		if next, err := time.Parse(timeFormat, notifications[0].NextNotificationTime); err == nil {
+			lag = now.Sub(next)
+		}
+	}
+	observeSchedulerBatch(len(notifications), lag)
+	span.SetAttributes(attribute.Int("scheduler.batch_size", len(notifications)))
 
-	for _, notification := range notifications {
+	for i, notification := range notifications {
+		if s.stopping() {
+			//finish the send in flight, but leave the rest for the next run
+			Logger(ctx).Infof("shutting down with %d notifications left to send", len(notifications)-i)
+			return nil
+		}
 		if err := s.handleUserNotification(ctx, notification); err != nil {
-			logrus.Warnf("failed to handle user notification: %+v.  %s", notification, err)
+			Logger(ctx).WithFields(logrus.Fields{
+				"phone_number":    notification.PhoneNumber,
+				"notification_id": notification.NotificationId,
+			}).Warnf("failed to handle user notification: %s", err)
 		}
 	}
+	s.recordTriggerRun(now)
 	return nil
 }
 
-func (s *NotifyAppServer) handleUserNotification(ctx context.Context, up *pb.UserNotification) error {
-	txn, err := s.DB.Begin()
+// handleUserNotification sends a due notification.  it's claimed first, by
+// committing its next time, so no other run sends it too, and twilio is
+// called outside any transaction.  a send twilio fails is kept as a dead
+// letter rather than retried every run, notifyctl dead-letters replays it.
+func (s *NotifyAppServer) handleUserNotification(ctx context.Context, up *pb.UserNotification) (err error) {
+	ctx, span := tracer.Start(ctx, "scheduler send", trace.WithAttributes(
+		attribute.String("notification_id", up.NotificationId),
+	))
+	defer func() { endSpan(span, err) }()
+
+	//before the claim, so a bad template doesn't use up the send
+	msg, err := s.populateTemplate(ctx, up.Notification, nil)
 	if err != nil {
-		return errors.Wrap(err, "failed to begin txn")
+		return errors.Wrap(err, "failed to populate tmpl")
 	}
-	defer txn.Rollback()
-
-	//update user notifications
-	if err := s.updateUserNotification(context.Background(), txn, up); err != nil {
-		return errors.Wrap(err, "failed to update user notification")
-	}
-
-	msg, err := s.populateTemplate(ctx, s.DB, up.Notification, nil)
-	if err != nil {
-		return errors.Wrap(err, "failed to populate regack tmpl")
-	}
-
-	//send sms
-	if err := s.sendSMS(ctx, up.PhoneNumber, msg); err != nil {
-		return errors.Wrap(err, "failed to send sms")
+	if err := s.claimUserNotification(ctx, up); errors.Cause(err) == errNotFound {
+		Logger(ctx).Infof("user notification already claimed")
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to claim user notification")
 	}
 
 	comm := &pb.Communication{From: s.config.From, To: up.PhoneNumber, Message: msg, NotificationId: up.NotificationId}
-	if err := s.insertCommunication(ctx, txn, comm); err != nil {
-		logrus.Warn("failed to insert comms: %s", err)
-	}
-	return errors.Wrap(txn.Commit(), "failed to commit")
-}
-
-func (s *NotifyAppServer) insertCommunication(ctx context.Context, db Database, comm *pb.Communication) error {
-	comm.CommsId = uuid.NewV4().String()
-
-	stmt, err := db.Prepare(`
-		INSERT INTO communications (comms_id, notification_id, from_phone, to_phone, message, created)
-		VALUES (?, ?, ?, ?, ?, NOW(6))
-	`)
-	if err != nil {
-		return errors.Wrap(err, "failed to prepare")
+	sendErr := s.sendSMS(ctx, up.PhoneNumber, msg)
+	if sendErr != nil {
+		comm.Status, comm.Error = repository.CommsFailed, sendErr.Error()
+	}
+	if err := s.insertCommunication(ctx, comm); err != nil {
+		if sendErr != nil {
+			//the claim is committed, so without its dead letter the send is lost
+			return errors.Wrapf(err, "failed to insert dead letter for send failing with %s", sendErr)
+		}
+		Logger(ctx).Warnf("failed to insert comms: %s", err)
 	}
-	from := strings.Replace(comm.From, "+1", "", -1)
-	to := strings.Replace(comm.To, "+1", "", -1)
-	if _, err = stmt.Exec(comm.CommsId, comm.NotificationId, from, to, comm.Message); err != nil {
-		return errors.Wrap(err, "failed to exec")
+	if sendErr != nil {
+		return errors.Wrap(sendErr, "failed to send sms, kept as a dead letter")
 	}
 	return nil
 }
 
-func (s *NotifyAppServer) getMostRecentPrompt(ctx context.Context, db Database, phoneNumber string) (string, error) {
-	stmt, err := db.Prepare(`
-		SELECT n.template
-		FROM communications c, notifications n 
-		WHERE c.to_phone=? 
-		AND c.notification_id = n.notification_id
-		AND n.type="prompt"
-		ORDER BY c.created DESC LIMIT 1`)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to prepare")
-	}
-	rows, err := stmt.Query(phoneNumber)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to query")
+// insertCommunication stores comm, encrypting it if it's from a user.  it
+// may write their data key, so it's never called inside a transaction.
+func (s *NotifyAppServer) insertCommunication(ctx context.Context, comm *pb.Communication) error {
+	//only what users text us is encrypted, what we send them is our templates
+	stored := *comm
+	if comm.To == s.config.From {
+		//the id is picked here, the ciphertext is bound to it
+		stored.CommsId = uuid.NewV4().String()
+		var err error
+		if stored.Message, err = s.encrypt(ctx, comm.From, commsField(stored.CommsId), comm.Message); err != nil {
+			return errors.Wrap(err, "failed to encrypt message")
+		}
 	}
-	defer rows.Close()
-	if !rows.Next() {
-		return "", fmt.Errorf("sent message not found to %s", phoneNumber)
+	if err := s.repo.InsertCommunication(ctx, &stored); err != nil {
+		return err
 	}
-
-	notification := &pb.Notification{}
-	if err := rows.Scan(&notification.Template); err != nil {
-		return "", errors.Wrap(err, "failed to scan")
-	}
-	return notification.Template, nil
+	comm.CommsId = stored.CommsId
+	return nil
 }
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/mikerjacobi/notify-app/server/repository"
+	"github.com/pkg/errors"
+)
+
+var (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// newPageQuery validates the paging fields of a list request.  rows are
+// returned newest first, and the cursor is the created time and id of the
+// last row of the previous page.
+func newPageQuery(cursor string, limit int, createdAfter, createdBefore string) (*repository.Page, string, error) {
+	page := &repository.Page{Cursor: cursor, Limit: limit}
+	if page.Limit <= 0 {
+		page.Limit = defaultPageSize
+	}
+	if page.Limit > maxPageSize {
+		page.Limit = maxPageSize
+	}
+	if cursor != "" {
+		if _, _, err := repository.DecodeCursor(cursor); err != nil {
+			return nil, "cursor", err
+		}
+	}
+	var err error
+	if page.CreatedAfter, err = parseDateFilter(createdAfter); err != nil {
+		return nil, "created_after", err
+	}
+	if page.CreatedBefore, err = parseDateFilter(createdBefore); err != nil {
+		return nil, "created_before", err
+	}
+	return page, "", nil
+}
+
+// parseDateFilter accepts a date or a timestamp and returns it as a
+// timestamp the store can compare against.
+func parseDateFilter(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if t, err := time.Parse(timeFormat, value); err == nil {
+		return t.Format(timeFormat), nil
+	}
+	t, err := time.Parse(birthdayFormat, value)
+	if err != nil {
+		return "", errors.Wrapf(err, "date '%s' is invalid", value)
+	}
+	return t.Format(timeFormat), nil
+}
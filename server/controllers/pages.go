@@ -1,28 +1,33 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"html/template"
+	"io/fs"
 	"net/http"
-	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/husobee/vestigo"
 	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type BaseTemplate struct {
-	Name    string
-	Tab     string
-	Payload interface{}
+	Name      string
+	Tab       string
+	CSRFToken string
+	Payload   interface{}
 }
 
 func (s *NotifyAppServer) GetLogin(w http.ResponseWriter, r *http.Request) {
-	renderTemplate(w, r, "login", nil)
+	s.renderTemplate(w, r, "login", nil)
 }
 
 func (s *NotifyAppServer) Logout(w http.ResponseWriter, r *http.Request) {
@@ -31,102 +36,329 @@ func (s *NotifyAppServer) Logout(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/login", http.StatusFound)
 }
 
+// dummyHashword is checked when the phone number has no account, so a login
+// takes as long whether it has one or not.
+var dummyHashword, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+
 func (s *NotifyAppServer) PostLogin(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 	if err := r.ParseForm(); err != nil {
-		logrus.Errorf("failed to parse form: %s", err)
+		Logger(ctx).Errorf("failed to parse form: %s", err)
 		http.Redirect(w, r, "/login", http.StatusFound)
 		return
 	}
 
-	phoneNumber := r.PostForm.Get("phone_number")
-	user, err := s.getUser(ctx, s.DB, phoneNumber)
+	phoneNumber, err := normalizePhoneNumber(r.PostForm.Get("phone_number"), s.config.DefaultRegion)
 	if err != nil {
-		logrus.Errorf("failed to get user: %s", err)
+		Logger(ctx).Errorf("failed to normalize phone number: %s", err)
 		http.Redirect(w, r, "/login", http.StatusFound)
 		return
 	}
+	ip := s.clientIP(r)
+	lf := logrus.Fields{"phone_number": phoneNumber, "ip": ip}
+	attemptID, err := s.claimAttempt(ctx, loginAttempt, phoneNumber, ip)
+	if err != nil {
+		terr, ok := err.(*throttledError)
+		if !ok {
+			Logger(ctx).WithFields(lf).Errorf("failed to claim attempt: %s", err)
+			s.renderTemplate(w, r, "error", nil)
+			return
+		}
+		Logger(ctx).WithFields(lf).Warnf("login throttled: %s", err)
+		w.Header().Set("Retry-After", strconv.Itoa(int(terr.retryAfter/time.Second)))
+		s.renderTemplate(w, r, "login", &loginPayload{Error: err.Error()})
+		return
+	}
 
 	inputPassword := []byte(r.PostForm.Get("password"))
+	user, err := s.repo.GetUser(ctx, phoneNumber)
+	if err != nil {
+		Logger(ctx).WithFields(lf).Warnf("failed to get user: %s", err)
+		bcrypt.CompareHashAndPassword(dummyHashword, inputPassword)
+		s.failLogin(ctx, w, r, phoneNumber)
+		return
+	}
+
 	storedPassword, err := base64.StdEncoding.DecodeString(user.Password)
 	if err != nil {
-		logrus.Errorf("failed to decode hashword: %s", err)
+		Logger(ctx).Errorf("failed to decode hashword: %s", err)
 		http.Redirect(w, r, "/login", http.StatusFound)
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword(storedPassword, inputPassword); err != nil {
-		logrus.Errorf("password mismatch: %s", err)
-		http.Redirect(w, r, "/login", http.StatusFound)
+		Logger(ctx).WithFields(lf).Info("password mismatch")
+		s.failLogin(ctx, w, r, phoneNumber)
 		return
 	}
+	if err := s.succeedAttempt(ctx, attemptID); err != nil {
+		Logger(ctx).WithFields(lf).Warnf("failed to record login attempt: %s", err)
+	}
+
+	if user.TwoFactor && !s.trustedDevice(r, user.PhoneNumber) {
+		if err := s.startLoginChallenge(ctx, w, user); err != nil {
+			Logger(ctx).WithFields(lf).Errorf("failed to start login challenge: %s", err)
+			s.renderTemplate(w, r, "error", nil)
+			return
+		}
+		http.Redirect(w, r, "/login/verify", http.StatusFound)
+		return
+	}
+	s.startSession(ctx, w, r, user)
+}
 
+// startSession logs the user in with a fresh session and csrf token.
+func (s *NotifyAppServer) startSession(ctx context.Context, w http.ResponseWriter, r *http.Request, user *pb.User) {
 	user.SessionId = uuid.NewV4().String()
+	user.CsrfToken = uuid.NewV4().String()
 	session := authSession{
-		PhoneNumber: phoneNumber,
+		PhoneNumber: user.PhoneNumber,
 		ID:          user.SessionId,
 	}
 
 	payload, err := json.Marshal(session)
 	if err != nil {
-		logrus.Errorf("failed to marshal session: %s", err)
+		Logger(ctx).Errorf("failed to marshal session: %s", err)
 		http.Redirect(w, r, "/login", http.StatusFound)
 		return
 	}
 
-	if err := s.updateUser(ctx, s.DB, user); err != nil {
-		logrus.Errorf("failed to upate user: %s", err)
+	if err := s.repo.UpdateUser(ctx, user); err != nil {
+		Logger(ctx).Errorf("failed to upate user: %s", err)
 		http.Redirect(w, r, "/login", http.StatusFound)
 		return
 	}
 
 	c := &http.Cookie{
-		Name:  "session",
-		Value: base64.StdEncoding.EncodeToString(payload),
+		Name:     "session",
+		Value:    base64.StdEncoding.EncodeToString(payload),
+		HttpOnly: true,
 	}
 	http.SetCookie(w, c)
 	http.Redirect(w, r, "/journal", http.StatusFound)
 }
 
-func (s *NotifyAppServer) GetJournal(w http.ResponseWriter, r *http.Request) {
+type loginPayload struct {
+	Error string
+}
+
+// failLogin leaves the claimed attempt failed and sends the user back to the
+// login page.
+func (s *NotifyAppServer) failLogin(ctx context.Context, w http.ResponseWriter, r *http.Request, phoneNumber string) {
+	if err := s.failAttempt(ctx, loginAttempt, phoneNumber); err != nil {
+		Logger(ctx).Errorf("failed to check lockout: %s", err)
+	}
+	s.renderTemplate(w, r, "login", &loginPayload{Error: "invalid phone number or password"})
+}
+
+func (s *NotifyAppServer) GetVerifyLogin(w http.ResponseWriter, r *http.Request) {
+	s.renderTemplate(w, r, "verify", nil)
+}
+
+func (s *NotifyAppServer) PostVerifyLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := r.ParseForm(); err != nil {
+		Logger(ctx).Errorf("failed to parse form: %s", err)
+		http.Redirect(w, r, "/login", http.StatusFound)
+		return
+	}
+
+	challenge, err := r.Cookie(challengeCookie)
+	if err != nil || challenge.Value == "" {
+		Logger(ctx).Warnf("missing login challenge")
+		http.Redirect(w, r, "/login", http.StatusFound)
+		return
+	}
+	code, err := s.repo.GetLoginCode(ctx, challenge.Value)
+	if err != nil {
+		Logger(ctx).Warnf("failed to get login code: %s", err)
+		http.Redirect(w, r, "/login", http.StatusFound)
+		return
+	}
+
+	ip := s.clientIP(r)
+	lf := logrus.Fields{"phone_number": code.PhoneNumber, "ip": ip}
+	attemptID, err := s.claimAttempt(ctx, otpAttempt, code.PhoneNumber, ip)
+	if err != nil {
+		terr, ok := err.(*throttledError)
+		if !ok {
+			Logger(ctx).WithFields(lf).Errorf("failed to claim attempt: %s", err)
+			s.renderTemplate(w, r, "error", nil)
+			return
+		}
+		Logger(ctx).WithFields(lf).Warnf("login code throttled: %s", err)
+		w.Header().Set("Retry-After", strconv.Itoa(int(terr.retryAfter/time.Second)))
+		s.renderTemplate(w, r, "verify", &loginPayload{Error: err.Error()})
+		return
+	}
+
+	if !loginCodeMatches(code, r.PostForm.Get("code")) {
+		Logger(ctx).WithFields(lf).Info("login code mismatch")
+		if err := s.failAttempt(ctx, otpAttempt, code.PhoneNumber); err != nil {
+			Logger(ctx).WithFields(lf).Errorf("failed to check lockout: %s", err)
+		}
+		//the code is burned after a few misses, so guessing needs a fresh one
+		used, err := s.repo.MissLoginCode(ctx, code.CodeID, loginCodeMisses)
+		if err != nil && errors.Cause(err) != errNotFound {
+			Logger(ctx).WithFields(lf).Errorf("failed to miss login code: %s", err)
+		}
+		if used || err != nil {
+			Logger(ctx).WithFields(lf).Info("login code used up")
+			http.SetCookie(w, &http.Cookie{Name: challengeCookie, Path: "/login", MaxAge: -1})
+			s.renderTemplate(w, r, "login", &loginPayload{Error: "too many wrong codes, sign in again"})
+			return
+		}
+		s.renderTemplate(w, r, "verify", &loginPayload{Error: "invalid code"})
+		return
+	}
+	if err := s.repo.UseLoginCode(ctx, code.CodeID); errors.Cause(err) == errNotFound {
+		//another request used it after we got it
+		Logger(ctx).WithFields(lf).Info("login code already used")
+		s.renderTemplate(w, r, "verify", &loginPayload{Error: "invalid code"})
+		return
+	} else if err != nil {
+		Logger(ctx).WithFields(lf).Errorf("failed to use login code: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	if err := s.succeedAttempt(ctx, attemptID); err != nil {
+		Logger(ctx).WithFields(lf).Warnf("failed to record login code attempt: %s", err)
+	}
+
+	user, err := s.repo.GetUser(ctx, code.PhoneNumber)
+	if err != nil {
+		Logger(ctx).WithFields(lf).Errorf("failed to get user: %s", err)
+		http.Redirect(w, r, "/login", http.StatusFound)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: challengeCookie, Path: "/login", MaxAge: -1})
+	if r.PostForm.Get("remember_device") == "on" {
+		http.SetCookie(w, s.newDeviceCookie(user.PhoneNumber))
+	}
+	s.startSession(ctx, w, r, user)
+}
+
+func (s *NotifyAppServer) PostTwoFactor(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to parse form: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
 	user, ok := r.Context().Value(userKey).(*pb.User)
 	if !ok {
-		logrus.Errorf("failed to get user")
+		Logger(r.Context()).Errorf("failed to get user")
 		http.Redirect(w, r, "/logout", http.StatusFound)
 		return
 	}
-	entries, err := s.getJournalEntries(r.Context(), s.DB, user.PhoneNumber)
+
+	user.TwoFactor = r.PostForm.Get("two_factor") == "on"
+	if err := s.repo.UpdateUser(r.Context(), user); err != nil {
+		Logger(r.Context()).Errorf("failed to update two factor: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
+	http.Redirect(w, r, "/account", http.StatusFound)
+}
+
+func (s *NotifyAppServer) GetJournalPage(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+	q := r.URL.Query()
+	if query := q.Get("q"); query != "" {
+		s.renderJournalSearch(w, r, user, query)
+		return
+	}
+	page, _, err := newPageQuery(q.Get("cursor"), 0, q.Get("after"), q.Get("before"))
+	if err != nil {
+		Logger(r.Context()).Errorf("invalid journal filters: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	entries, nextCursor, err := s.getJournalEntries(r.Context(), user.PhoneNumber, page, q.Get("title"), q.Get("tag"))
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to get entries: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	tags, err := s.repo.ListTagCounts(r.Context(), user.PhoneNumber)
 	if err != nil {
-		logrus.Errorf("failed to get entries: %s", err)
-		renderTemplate(w, r, "error", nil)
+		Logger(r.Context()).Errorf("failed to get tags: %s", err)
+		s.renderTemplate(w, r, "error", nil)
 		return
 	}
+
+	//next page keeps the current filters
+	next := ""
+	if nextCursor != "" {
+		q.Set("cursor", nextCursor)
+		next = "/journal?" + q.Encode()
+	}
 	payload := struct {
 		Entries []*pb.Journal
-	}{entries}
-	renderTemplate(w, r, "journal", &payload)
+		After   string
+		Before  string
+		Title   string
+		Tag     string
+		Tags    []cloudTag
+		Next    string
+	}{entries, q.Get("after"), q.Get("before"), q.Get("title"), q.Get("tag"), tagCloud(tags), next}
+	s.renderTemplate(w, r, "journal", &payload)
+}
+
+type searchResult struct {
+	Journal *pb.Journal
+	Score   float64
+	Snippet template.HTML
+}
+
+func (s *NotifyAppServer) renderJournalSearch(w http.ResponseWriter, r *http.Request, user *pb.User, query string) {
+	hits, err := s.searchJournals(r.Context(), user.PhoneNumber, query, defaultSearchResults)
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to search entries: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
+	results := make([]searchResult, len(hits))
+	for i, hit := range hits {
+		//snippet() escapes the entry before adding <mark> tags
+		results[i] = searchResult{Journal: hit.Journal, Score: hit.Score, Snippet: template.HTML(hit.Snippet)}
+	}
+	payload := struct {
+		Query   string
+		Results []searchResult
+	}{query, results}
+	s.renderTemplate(w, r, "search", &payload)
 }
 
 func (s *NotifyAppServer) GetConfigure(w http.ResponseWriter, r *http.Request) {
 
 	user, ok := r.Context().Value(userKey).(*pb.User)
 	if !ok {
-		logrus.Errorf("failed to get user")
+		Logger(r.Context()).Errorf("failed to get user")
 		http.Redirect(w, r, "/logout", http.StatusFound)
 		return
 	}
 
-	notifications, err := s.getNotifications(r.Context(), s.DB)
+	notifications, err := s.listNotifications(r.Context())
 	if err != nil {
-		logrus.Errorf("failed to get notifications: %s", err)
-		renderTemplate(w, r, "error", nil)
+		Logger(r.Context()).Errorf("failed to get notifications: %s", err)
+		s.renderTemplate(w, r, "error", nil)
 		return
 	}
 
-	userNotifications, err := s.getUserNotifications(r.Context(), s.DB, user.PhoneNumber)
+	userNotifications, err := s.repo.ListUserNotifications(r.Context(), user.PhoneNumber)
 	if err != nil {
-		logrus.Errorf("failed to get notifications: %s", err)
-		renderTemplate(w, r, "error", nil)
+		Logger(r.Context()).Errorf("failed to get notifications: %s", err)
+		s.renderTemplate(w, r, "error", nil)
 		return
 	}
 
@@ -134,49 +366,62 @@ func (s *NotifyAppServer) GetConfigure(w http.ResponseWriter, r *http.Request) {
 		Notifications     []*pb.Notification
 		UserNotifications []*pb.UserNotification
 	}{notifications, userNotifications}
-	renderTemplate(w, r, "configure", payload)
+	s.renderTemplate(w, r, "configure", payload)
 }
 
-func renderTemplate(w http.ResponseWriter, r *http.Request, page string, payload interface{}) {
-	tmplDir := "../client/"
-	paths := []string{
-		filepath.Join(tmplDir, "base.html"),
-		filepath.Join(tmplDir, page+".html"),
+func (s *NotifyAppServer) renderTemplate(w http.ResponseWriter, r *http.Request, page string, payload interface{}) {
+	tmpl, err := s.templates.page(page)
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to load template: %s", err)
+		http.Error(w, "failed to render page", http.StatusInternalServerError)
+		return
 	}
-	tmpl := template.Must(template.ParseFiles(paths...))
 
 	user, ok := r.Context().Value(userKey).(*pb.User)
 	if !ok || user == nil {
 		user = &pb.User{}
 	}
 
+	base := BaseTemplate{
+		Name:      user.Name,
+		Tab:       page,
+		CSRFToken: user.CsrfToken,
+		Payload:   payload,
+	}
+	//render to a buffer first, so a failed page is a clean 500
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, base); err != nil {
+		Logger(r.Context()).Errorf("failed to execute tmpl: %s", err)
+		http.Error(w, "failed to render page", http.StatusInternalServerError)
+		return
+	}
+
 	if page == "error" {
 		w.WriteHeader(500)
 	}
+	buf.WriteTo(w)
+}
 
-	base := BaseTemplate{
-		Name:    user.Name,
-		Tab:     page,
-		Payload: payload,
-	}
-	if err := tmpl.Execute(w, base); err != nil {
-		logrus.Errorf("failed to execute tmpl: %s", err)
-		w.WriteHeader(500)
-		return
+// StaticHandler serves client/static, the pages' scripts.
+func (s *NotifyAppServer) StaticHandler() http.HandlerFunc {
+	static, err := fs.Sub(clientFiles(s.config.TemplateDir), "static")
+	if err != nil {
+		logrus.Panicf("failed to find static files: %s", err)
 	}
+	return http.StripPrefix("/static/", http.FileServer(http.FS(static))).ServeHTTP
 }
 
 func (s *NotifyAppServer) PostUserNotification(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		logrus.Errorf("failed to parse form: %s", err)
-		renderTemplate(w, r, "error", nil)
+		Logger(r.Context()).Errorf("failed to parse form: %s", err)
+		s.renderTemplate(w, r, "error", nil)
 		return
 	}
 
 	user, ok := r.Context().Value(userKey).(*pb.User)
 	if !ok {
-		logrus.Errorf("failed to get user")
+		Logger(r.Context()).Errorf("failed to get user")
 		http.Redirect(w, r, "/logout", http.StatusFound)
 		return
 	}
@@ -192,55 +437,55 @@ func (s *NotifyAppServer) PostUserNotification(w http.ResponseWriter, r *http.Re
 		newPrompt := r.PostForm.Get("new_prompt")
 		if newPrompt != "" {
 			notification := &pb.Notification{Type: "prompt", Template: newPrompt}
-			err = s.insertNotification(r.Context(), s.DB, notification)
+			err = s.repo.InsertNotification(r.Context(), notification)
 			up.NotificationId = notification.NotificationId
 		} else {
 			up.NotificationId = r.PostForm.Get("select_notification")
-			_, err = s.getNotification(r.Context(), s.DB, up.NotificationId)
+			_, err = s.repo.GetNotification(r.Context(), up.NotificationId)
 		}
 	case "reminder":
 		notification := &pb.Notification{Type: "reminder", Template: r.PostForm.Get("new_reminder")}
-		err = s.insertNotification(r.Context(), s.DB, notification)
+		err = s.repo.InsertNotification(r.Context(), notification)
 		up.NotificationId = notification.NotificationId
 	default:
-		logrus.Errorf("invalid notification type: %s", r.PostForm.Get("radios"))
-		renderTemplate(w, r, "error", nil)
+		Logger(r.Context()).Errorf("invalid notification type: %s", r.PostForm.Get("radios"))
+		s.renderTemplate(w, r, "error", nil)
 		return
 	}
 	if err != nil {
-		logrus.Errorf("failed to insert/get notification: %s", err)
-		renderTemplate(w, r, "error", nil)
+		Logger(r.Context()).Errorf("failed to insert/get notification: %s", err)
+		s.renderTemplate(w, r, "error", nil)
 		return
 	}
 
 	if _, err := s.AddUserNotification(r.Context(), up); err != nil {
-		logrus.Errorf("failed to insert user notification: %s", err)
-		renderTemplate(w, r, "error", nil)
+		Logger(r.Context()).Errorf("failed to insert user notification: %s", err)
+		s.renderTemplate(w, r, "error", nil)
 		return
 	}
 
 	http.Redirect(w, r, "/configure", http.StatusFound)
 }
 
-func (s *NotifyAppServer) DeleteUserNotification(w http.ResponseWriter, r *http.Request) {
+func (s *NotifyAppServer) DeleteUserNotificationPage(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		logrus.Errorf("failed to parse form: %s", err)
-		renderTemplate(w, r, "error", nil)
+		Logger(r.Context()).Errorf("failed to parse form: %s", err)
+		s.renderTemplate(w, r, "error", nil)
 		return
 	}
 
 	user, ok := r.Context().Value(userKey).(*pb.User)
 	if !ok {
-		logrus.Errorf("failed to get user")
+		Logger(r.Context()).Errorf("failed to get user")
 		http.Redirect(w, r, "/logout", http.StatusFound)
 		return
 	}
 
 	notificationID := vestigo.Param(r, "notification_id")
-	if err := s.deleteUserNotification(r.Context(), s.DB, user.PhoneNumber, notificationID); err != nil {
-		logrus.Errorf("failed to deleteuser notification: %s", err)
-		renderTemplate(w, r, "error", nil)
+	if err := s.repo.DeleteUserNotification(r.Context(), user.PhoneNumber, notificationID); err != nil {
+		Logger(r.Context()).Errorf("failed to deleteuser notification: %s", err)
+		s.renderTemplate(w, r, "error", nil)
 		return
 	}
 
@@ -250,14 +495,14 @@ func (s *NotifyAppServer) DeleteUserNotification(w http.ResponseWriter, r *http.
 func (s *NotifyAppServer) PostJournal(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		logrus.Errorf("failed to parse form: %s", err)
-		renderTemplate(w, r, "error", nil)
+		Logger(r.Context()).Errorf("failed to parse form: %s", err)
+		s.renderTemplate(w, r, "error", nil)
 		return
 	}
 
 	user, ok := r.Context().Value(userKey).(*pb.User)
 	if !ok {
-		logrus.Errorf("failed to get user")
+		Logger(r.Context()).Errorf("failed to get user")
 		http.Redirect(w, r, "/logout", http.StatusFound)
 		return
 	}
@@ -268,9 +513,14 @@ func (s *NotifyAppServer) PostJournal(w http.ResponseWriter, r *http.Request) {
 		Entry:       r.PostForm.Get("journal_entry"),
 	}
 
-	if err := s.insertJournal(r.Context(), s.DB, journal); err != nil {
-		logrus.Errorf("failed to insert user notification: %s", err)
-		renderTemplate(w, r, "error", nil)
+	if err := s.insertJournal(r.Context(), s.repo, journal); err != nil {
+		Logger(r.Context()).Errorf("failed to insert user notification: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	if err := s.addJournalTags(r.Context(), journal, parseTags(r.PostForm.Get("journal_tags")), tagSourceManual); err != nil {
+		Logger(r.Context()).Errorf("failed to tag journal: %s", err)
+		s.renderTemplate(w, r, "error", nil)
 		return
 	}
 
@@ -280,7 +530,7 @@ func (s *NotifyAppServer) PostJournal(w http.ResponseWriter, r *http.Request) {
 func (s *NotifyAppServer) PutJournal(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(userKey).(*pb.User)
 	if !ok {
-		logrus.Errorf("failed to get user")
+		Logger(r.Context()).Errorf("failed to get user")
 		w.WriteHeader(500)
 		w.Write([]byte("{}"))
 		return
@@ -291,14 +541,14 @@ func (s *NotifyAppServer) PutJournal(w http.ResponseWriter, r *http.Request) {
 		JournalId:   vestigo.Param(r, "journal_id"),
 	}
 	if err := json.NewDecoder(r.Body).Decode(journal); err != nil {
-		logrus.Errorf("failed to decode put journal: %s", err)
+		Logger(r.Context()).Errorf("failed to decode put journal: %s", err)
 		w.WriteHeader(500)
 		w.Write([]byte("{}"))
 		return
 	}
 
-	if err := s.updateJournal(r.Context(), s.DB, journal); err != nil {
-		logrus.Errorf("failed to update journal: %s", err)
+	if err := s.updateJournal(r.Context(), journal); err != nil {
+		Logger(r.Context()).Errorf("failed to update journal: %s", err)
 		w.WriteHeader(500)
 		w.Write([]byte("{}"))
 		return
@@ -307,10 +557,10 @@ func (s *NotifyAppServer) PutJournal(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("{}"))
 }
 
-func (s *NotifyAppServer) DeleteJournal(w http.ResponseWriter, r *http.Request) {
+func (s *NotifyAppServer) DeleteJournalPage(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(userKey).(*pb.User)
 	if !ok {
-		logrus.Errorf("failed to get user")
+		Logger(r.Context()).Errorf("failed to get user")
 		w.WriteHeader(500)
 		w.Write([]byte("{}"))
 		return
@@ -320,8 +570,8 @@ func (s *NotifyAppServer) DeleteJournal(w http.ResponseWriter, r *http.Request)
 		PhoneNumber: user.PhoneNumber,
 		JournalId:   vestigo.Param(r, "journal_id"),
 	}
-	if err := s.deleteJournal(r.Context(), s.DB, journal); err != nil {
-		logrus.Errorf("failed to update journal: %s", err)
+	if err := s.repo.DeleteJournal(r.Context(), journal.PhoneNumber, journal.JournalId); err != nil {
+		Logger(r.Context()).Errorf("failed to update journal: %s", err)
 		w.WriteHeader(500)
 		w.Write([]byte("{}"))
 		return
@@ -329,3 +579,85 @@ func (s *NotifyAppServer) DeleteJournal(w http.ResponseWriter, r *http.Request)
 
 	w.Write([]byte("{}"))
 }
+
+func (s *NotifyAppServer) GetAccount(w http.ResponseWriter, r *http.Request) {
+	s.renderAccount(w, r, "")
+}
+
+func (s *NotifyAppServer) renderAccount(w http.ResponseWriter, r *http.Request, newToken string) {
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	tokens, err := s.repo.ListApiTokens(r.Context(), user.PhoneNumber)
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to get api tokens: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
+	payload := struct {
+		PhoneNumber string
+		TwoFactor   bool
+		Tokens      []*pb.ApiToken
+		NewToken    string
+	}{user.PhoneNumber, user.TwoFactor, tokens, newToken}
+	s.renderTemplate(w, r, "account", payload)
+}
+
+func (s *NotifyAppServer) PostApiToken(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to parse form: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	token, err := newApiToken()
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to generate api token: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
+	t := &pb.ApiToken{
+		PhoneNumber: user.PhoneNumber,
+		Name:        r.PostForm.Get("token_name"),
+	}
+	if err := s.repo.InsertApiToken(r.Context(), t, hashApiToken(token)); err != nil {
+		Logger(r.Context()).Errorf("failed to insert api token: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
+	//the plaintext token is only ever shown on this response
+	s.renderAccount(w, r, token)
+}
+
+func (s *NotifyAppServer) DeleteApiToken(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	tokenID := vestigo.Param(r, "token_id")
+	if err := s.repo.DeleteApiToken(r.Context(), user.PhoneNumber, tokenID); err != nil {
+		Logger(r.Context()).Errorf("failed to delete api token: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
+	http.Redirect(w, r, "/account", http.StatusFound)
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ttacon/libphonenumber"
+)
+
+// testNumberPrefix marks fake numbers used by the feature tests.  country
+// code 0 is never assigned, so these can't collide with a real subscriber.
+const testNumberPrefix = "+000"
+
+func isTestNumber(phoneNumber string) bool {
+	return strings.HasPrefix(phoneNumber, testNumberPrefix)
+}
+
+// normalizePhoneNumber parses a phone number as typed by a user or sent by
+// twilio and returns it in E.164 form, eg +14155551234.  numbers without a
+// leading + are parsed as national numbers of the given region.
+func normalizePhoneNumber(phoneNumber, region string) (string, error) {
+	phoneNumber = strings.TrimSpace(phoneNumber)
+	if phoneNumber == "" {
+		return "", fmt.Errorf("phone number is empty")
+	}
+
+	//test numbers may be typed without the +
+	digits := strings.TrimPrefix(phoneNumber, "+")
+	if isTestNumber("+" + digits) {
+		for _, r := range digits {
+			if r < '0' || r > '9' {
+				return "", fmt.Errorf("test number '%s' is invalid", phoneNumber)
+			}
+		}
+		return "+" + digits, nil
+	}
+
+	num, err := libphonenumber.Parse(phoneNumber, strings.ToUpper(region))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse '%s'", phoneNumber)
+	}
+	if !libphonenumber.IsValidNumber(num) {
+		return "", fmt.Errorf("'%s' is not a valid number for region '%s'", phoneNumber, region)
+	}
+	return libphonenumber.Format(num, libphonenumber.E164), nil
+}
@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"context"
+	"html"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/husobee/vestigo"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	"github.com/twitchtv/twirp"
+)
+
+// getJournalRevisions returns a journal's prior entries, newest first.
+func (s *NotifyAppServer) getJournalRevisions(ctx context.Context, phoneNumber, journalID string) ([]*pb.JournalRevision, error) {
+	revisions, err := s.repo.ListJournalRevisions(ctx, phoneNumber, journalID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range revisions {
+		if r.Entry, err = s.decrypt(ctx, r.PhoneNumber, journalField(r.JournalId, "entry"), r.Entry); err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt revision '%s'", r.RevisionId)
+		}
+	}
+	return revisions, nil
+}
+
+func (s *NotifyAppServer) getJournalRevision(ctx context.Context, phoneNumber, revisionID string) (*pb.JournalRevision, error) {
+	r, err := s.repo.GetJournalRevision(ctx, phoneNumber, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if r.Entry, err = s.decrypt(ctx, r.PhoneNumber, journalField(r.JournalId, "entry"), r.Entry); err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt revision '%s'", r.RevisionId)
+	}
+	return r, nil
+}
+
+// restoreJournalRevision puts a revision's entry back on its journal.  the
+// entry being replaced becomes a revision itself, so a restore can be undone.
+func (s *NotifyAppServer) restoreJournalRevision(ctx context.Context, phoneNumber, revisionID string) (*pb.Journal, error) {
+	r, err := s.getJournalRevision(ctx, phoneNumber, revisionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get revision")
+	}
+	j := &pb.Journal{JournalId: r.JournalId, PhoneNumber: phoneNumber, Entry: r.Entry}
+	if err := s.updateJournal(ctx, j); err != nil {
+		return nil, errors.Wrap(err, "failed to update journal")
+	}
+	return j, nil
+}
+
+type diffOp struct {
+	// Op is one of "=", "+" or "-"
+	Op   string
+	Text string
+}
+
+// maxDiffCells caps the lcs table wordDiff builds, len(a)*len(b) ints.
+// past it the changed words are shown as one delete and one insert.
+const maxDiffCells = 1 << 20
+
+// wordDiff returns the word level edits that turn before into after, using
+// the longest common subsequence of their words.
+func wordDiff(before, after string) []diffOp {
+	a, b := strings.Fields(before), strings.Fields(after)
+	ops := []diffOp{}
+	push := func(op, word string) {
+		if n := len(ops); n > 0 && ops[n-1].Op == op {
+			ops[n-1].Text += " " + word
+			return
+		}
+		ops = append(ops, diffOp{Op: op, Text: word})
+	}
+
+	//the words both share at either end don't need the table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		push("=", a[prefix])
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	if len(a)*len(b) > maxDiffCells {
+		for _, word := range a {
+			push("-", word)
+		}
+		for _, word := range b {
+			push("+", word)
+		}
+	} else {
+		lcsDiff(a, b, push)
+	}
+	for _, word := range common {
+		push("=", word)
+	}
+	return ops
+}
+
+// lcsDiff pushes the edits that turn a into b.
+func lcsDiff(a, b []string, push func(op, word string)) {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			push("=", a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			push("-", a[i])
+			i++
+		default:
+			push("+", b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		push("-", a[i])
+	}
+	for ; j < len(b); j++ {
+		push("+", b[j])
+	}
+}
+
+// diffHTML renders a word diff with <del> and <ins> around the changes.
+func diffHTML(ops []diffOp) template.HTML {
+	parts := []string{}
+	for _, op := range ops {
+		text := html.EscapeString(op.Text)
+		switch op.Op {
+		case "+":
+			text = "<ins>" + text + "</ins>"
+		case "-":
+			text = "<del>" + text + "</del>"
+		}
+		parts = append(parts, text)
+	}
+	return template.HTML(strings.Join(parts, " "))
+}
+
+// revisionOwner is the phone number revision rpcs act on.  unlike
+// authorizePhoneNumber admins get no access to other users' history.
+func revisionOwner(ctx context.Context, phoneNumber string) (string, error) {
+	user, ok := ctx.Value(userKey).(*pb.User)
+	if !ok {
+		return "", twirp.NewError(twirp.Unauthenticated, "api token required")
+	}
+	if phoneNumber != "" && phoneNumber != user.PhoneNumber {
+		return "", twirp.NewError(twirp.PermissionDenied, "revisions are only visible to their owner")
+	}
+	return user.PhoneNumber, nil
+}
+
+func (s *NotifyAppServer) ListJournalRevisions(ctx context.Context, req *pb.Journal) (*pb.JournalRevisionList, error) {
+	phoneNumber, err := revisionOwner(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.getJournalRevisions(ctx, phoneNumber, req.JournalId)
+	if err != nil {
+		Logger(ctx).Errorf("failed to get revisions: %s", err)
+		return nil, twirp.InternalError("failed to list revisions")
+	}
+	return &pb.JournalRevisionList{Revisions: revisions}, nil
+}
+
+func (s *NotifyAppServer) RestoreJournalRevision(ctx context.Context, req *pb.JournalRevision) (*pb.Journal, error) {
+	phoneNumber, err := revisionOwner(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	j, err := s.restoreJournalRevision(ctx, phoneNumber, req.RevisionId)
+	if err != nil {
+		if errors.Cause(err) == errNotFound {
+			return nil, twirp.NotFoundError("revision not found")
+		}
+		Logger(ctx).Errorf("failed to restore revision: %s", err)
+		return nil, twirp.InternalError("failed to restore revision")
+	}
+	return s.GetJournal(ctx, j)
+}
+
+type revisionView struct {
+	Revision *pb.JournalRevision
+	// Diff is from this revision to the version that replaced it
+	Diff template.HTML
+}
+
+func (s *NotifyAppServer) GetJournalHistory(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	journal, err := s.getJournal(r.Context(), user.PhoneNumber, vestigo.Param(r, "journal_id"))
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to get journal: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	revisions, err := s.getJournalRevisions(r.Context(), user.PhoneNumber, journal.JournalId)
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to get revisions: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
+	views := []revisionView{}
+	newer := journal.Entry
+	for _, rev := range revisions {
+		views = append(views, revisionView{Revision: rev, Diff: diffHTML(wordDiff(rev.Entry, newer))})
+		newer = rev.Entry
+	}
+
+	payload := struct {
+		Journal   *pb.Journal
+		Revisions []revisionView
+	}{journal, views}
+	s.renderTemplate(w, r, "history", payload)
+}
+
+func (s *NotifyAppServer) PostRestoreRevision(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	j, err := s.restoreJournalRevision(r.Context(), user.PhoneNumber, vestigo.Param(r, "revision_id"))
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to restore revision: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	http.Redirect(w, r, "/journal/"+j.JournalId+"/history", http.StatusFound)
+}
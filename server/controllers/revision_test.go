@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWordDiff(t *testing.T) {
+	cases := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{"identical", "coffee with friends", "coffee with friends", "=coffee with friends"},
+		{"both empty", "", "", ""},
+		{"all inserted", "", "coffee with friends", "+coffee with friends"},
+		{"all deleted", "coffee with friends", "", "-coffee with friends"},
+		{"middle changed", "the quick brown fox", "the slow brown fox", "=the|-quick|+slow|=brown fox"},
+		{"prefix only", "coffee with friends", "coffee", "=coffee|-with friends"},
+		{"suffix only", "coffee with friends", "friends", "-coffee with|=friends"},
+		{"prefix and suffix overlap", "a a", "a", "=a|-a"},
+		{"whitespace ignored", " coffee\n\twith  friends ", "coffee with friends", "=coffee with friends"},
+		{"lcs inside trimmed ends", "a b c d e", "a c b d e", "=a|-b|=c|+b|=d e"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := formatOps(wordDiff(c.before, c.after)); got != c.want {
+				t.Errorf("wordDiff(%q, %q) = %q, want %q", c.before, c.after, got, c.want)
+			}
+		})
+	}
+}
+
+func TestWordDiffFallback(t *testing.T) {
+	//enough changed words on each side to pass maxDiffCells, with one
+	//word in common the lcs would otherwise keep
+	words := func(prefix string, n int) string {
+		w := make([]string, n)
+		for i := range w {
+			w[i] = fmt.Sprintf("%s%d", prefix, i)
+		}
+		return strings.Join(w, " ")
+	}
+	n := 1025
+	if n*(n+1) <= maxDiffCells {
+		t.Fatalf("%d words don't pass maxDiffCells", n)
+	}
+	before := "start " + words("x", n) + " keep end"
+	after := "start keep " + words("y", n) + " end"
+
+	ops := wordDiff(before, after)
+	got := []string{}
+	for _, op := range ops {
+		got = append(got, op.Op)
+	}
+	if want := "= - + ="; strings.Join(got, " ") != want {
+		t.Fatalf("ops = %q, want %q", strings.Join(got, " "), want)
+	}
+	if ops[1].Text != words("x", n)+" keep" || ops[2].Text != "keep "+words("y", n) {
+		t.Errorf("fallback didn't delete and insert every changed word")
+	}
+	if ops[0].Text != "start" || ops[3].Text != "end" {
+		t.Errorf("common ends = %q, %q, want start, end", ops[0].Text, ops[3].Text)
+	}
+}
+
+func formatOps(ops []diffOp) string {
+	parts := []string{}
+	for _, op := range ops {
+		parts = append(parts, op.Op+op.Text)
+	}
+	return strings.Join(parts, "|")
+}
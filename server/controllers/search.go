@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	"github.com/twitchtv/twirp"
+)
+
+var (
+	defaultSearchResults = 20
+	maxSearchResults     = 100
+	// maxSearchCandidates caps the journals matched on the blind index that
+	// are decrypted and ranked
+	maxSearchCandidates = 500
+	snippetWords        = 24
+)
+
+// the blind index hashes every word of a journal as a wordTerm, and the words
+// of its title again as titleTerms for the title filter.
+const (
+	wordTerm  = "word"
+	titleTerm = "title"
+)
+
+// termHash is a word's term in the blind index, a keyed hash so the store can
+// match words it can't read.  128 bits is plenty to tell words apart.
+func (s *NotifyAppServer) termHash(phoneNumber, kind, word string) string {
+	return s.keys.hash(phoneNumber, kind, word)[:32]
+}
+
+// journalTerms returns the blind index terms of a journal, with how often
+// each occurs.
+func (s *NotifyAppServer) journalTerms(phoneNumber, title, entry string) map[string]int {
+	terms := map[string]int{}
+	for _, t := range tokenize(title + " " + entry) {
+		terms[s.termHash(phoneNumber, wordTerm, t)]++
+	}
+	for _, t := range tokenize(title) {
+		terms[s.termHash(phoneNumber, titleTerm, t)]++
+	}
+	return terms
+}
+
+// searchJournals finds a user's journals with a query term on the blind
+// index, and ranks them with an in memory inverted index of their decrypted
+// text.
+func (s *NotifyAppServer) searchJournals(ctx context.Context, phoneNumber, query string, limit int) ([]*pb.SearchHit, error) {
+	terms := []string{}
+	for _, t := range tokenize(query) {
+		terms = append(terms, s.termHash(phoneNumber, wordTerm, t))
+	}
+	candidates, err := s.repo.SearchJournals(ctx, phoneNumber, terms, maxSearchCandidates)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search")
+	}
+	total, err := s.repo.CountJournals(ctx, phoneNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to count journals")
+	}
+
+	index := newInvertedIndex(total)
+	for _, j := range candidates {
+		if err := s.decryptJournal(ctx, j); err != nil {
+			return nil, err
+		}
+		index.Add(j)
+	}
+	return index.Search(query, limit), nil
+}
+
+func (s *NotifyAppServer) SearchJournals(ctx context.Context, req *pb.SearchJournalsReq) (*pb.SearchJournalsResp, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokenize(req.Query)) == 0 {
+		return nil, twirp.InvalidArgumentError("query", "must contain a word")
+	}
+	limit := int(req.PageSize)
+	if limit <= 0 {
+		limit = defaultSearchResults
+	}
+	if limit > maxSearchResults {
+		limit = maxSearchResults
+	}
+
+	hits, err := s.searchJournals(ctx, phoneNumber, req.Query, limit)
+	if err != nil {
+		Logger(ctx).Errorf("failed to search journals: %s", err)
+		return nil, twirp.InternalError("failed to search journals")
+	}
+	return &pb.SearchJournalsResp{Hits: hits}, nil
+}
+
+// tokenize lowercases text and splits it into words.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// snippet returns an html escaped window of text around the first query term,
+// with every query term wrapped in <mark>.
+func snippet(text string, terms []string) string {
+	words := strings.Fields(text)
+	isTerm := func(word string) bool {
+		for _, t := range tokenize(word) {
+			if Contains(terms, t) {
+				return true
+			}
+		}
+		return false
+	}
+
+	start := 0
+	for i, word := range words {
+		if isTerm(word) {
+			start = i - snippetWords/3
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+
+	out := make([]string, 0, end-start+2)
+	if start > 0 {
+		out = append(out, "...")
+	}
+	for _, word := range words[start:end] {
+		if isTerm(word) {
+			out = append(out, "<mark>"+html.EscapeString(word)+"</mark>")
+		} else {
+			out = append(out, html.EscapeString(word))
+		}
+	}
+	if end < len(words) {
+		out = append(out, "...")
+	}
+	return strings.Join(out, " ")
+}
+
+// invertedIndex is a pure go tf-idf index over the journals a search matched,
+// used to rank them because the store only has hashes of their words.  it's
+// built for one search and thrown away, so it has no locking or removal.
+type invertedIndex struct {
+	// total is how many journals the user has, for the idf
+	total    int
+	journals map[string]*pb.Journal
+	// postings maps a term to journal ids and the term's count in each
+	postings map[string]map[string]int
+	lengths  map[string]int
+}
+
+func newInvertedIndex(total int) *invertedIndex {
+	return &invertedIndex{
+		total:    total,
+		journals: map[string]*pb.Journal{},
+		postings: map[string]map[string]int{},
+		lengths:  map[string]int{},
+	}
+}
+
+func (idx *invertedIndex) Add(j *pb.Journal) {
+	terms := tokenize(j.Title + " " + j.Entry)
+	idx.journals[j.JournalId] = j
+	idx.lengths[j.JournalId] = len(terms)
+	for _, t := range terms {
+		if idx.postings[t] == nil {
+			idx.postings[t] = map[string]int{}
+		}
+		idx.postings[t][j.JournalId]++
+	}
+}
+
+// Search returns the journals matching any query term, best first.
+func (idx *invertedIndex) Search(query string, limit int) []*pb.SearchHit {
+	terms := tokenize(query)
+	scores := map[string]float64{}
+	for _, t := range terms {
+		docs := idx.postings[t]
+		if len(docs) == 0 {
+			continue
+		}
+		total := idx.total
+		if total < len(idx.journals) {
+			total = len(idx.journals)
+		}
+		idf := math.Log(1 + float64(total)/float64(len(docs)))
+		for id, n := range docs {
+			scores[id] += float64(n) / float64(idx.lengths[id]) * idf
+		}
+	}
+
+	hits := make([]*pb.SearchHit, 0, len(scores))
+	for id, score := range scores {
+		j := idx.journals[id]
+		hits = append(hits, &pb.SearchHit{Journal: j, Score: score, Snippet: snippet(j.Entry, terms)})
+	}
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].Journal.Created > hits[b].Journal.Created
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"Hello, World!", "hello world"},
+		{"don't", "don t"},
+		{"Café NAÏVE", "café naïve"},
+		{"v2 on 2020-01-01", "v2 on 2020 01 01"},
+		{"#tag @me", "tag me"},
+		{"  \n\t", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := strings.Join(tokenize(c.text), " "); got != c.want {
+			t.Errorf("tokenize(%q) = %q, want %q", c.text, got, c.want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	defer func(n int) { snippetWords = n }(snippetWords)
+	snippetWords = 6
+
+	cases := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{
+			name:  "marks terms",
+			text:  "Coffee with friends",
+			terms: []string{"coffee"},
+			want:  "<mark>Coffee</mark> with friends",
+		},
+		{
+			name:  "escapes html",
+			text:  "<script>alert(1)</script> & coffee",
+			terms: []string{"coffee"},
+			want:  "&lt;script&gt;alert(1)&lt;/script&gt; &amp; <mark>coffee</mark>",
+		},
+		{
+			name:  "escapes a marked word",
+			text:  `<b>coffee</b> "black"`,
+			terms: []string{"coffee"},
+			want:  "<mark>&lt;b&gt;coffee&lt;/b&gt;</mark> &#34;black&#34;",
+		},
+		{
+			name:  "marks words with punctuation",
+			text:  "tea, coffee.",
+			terms: []string{"tea", "coffee"},
+			want:  "<mark>tea,</mark> <mark>coffee.</mark>",
+		},
+		{
+			name:  "window around the first term",
+			text:  "a b c d e f g h i j",
+			terms: []string{"f"},
+			want:  "... d e <mark>f</mark> g h i ...",
+		},
+		{
+			name:  "no term starts at the beginning",
+			text:  "a b c d e f g h",
+			terms: []string{"z"},
+			want:  "a b c d e f ...",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := snippet(c.text, c.terms); got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestInvertedIndexSearch(t *testing.T) {
+	journals := []*pb.Journal{
+		{JournalId: "all coffee", Entry: "coffee coffee coffee", Created: "2020-01-01 00:00:00"},
+		{JournalId: "some coffee", Entry: "coffee and tea with friends", Created: "2020-01-03 00:00:00"},
+		{JournalId: "no coffee", Entry: "tea only", Created: "2020-01-04 00:00:00"},
+		{JournalId: "all coffee, newer", Entry: "coffee coffee coffee", Created: "2020-01-02 00:00:00"},
+		{JournalId: "titled coffee", Title: "Coffee", Entry: "a b c d e f g h i", Created: "2020-01-05 00:00:00"},
+	}
+	index := newInvertedIndex(10)
+	for _, j := range journals {
+		index.Add(j)
+	}
+
+	cases := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		//denser matches first, ties newest first
+		{"coffee", 10, []string{"all coffee, newer", "all coffee", "some coffee", "titled coffee"}},
+		{"COFFEE!", 2, []string{"all coffee, newer", "all coffee"}},
+		//matching either term is enough
+		{"tea coffee", 10, []string{"all coffee, newer", "all coffee", "no coffee", "some coffee", "titled coffee"}},
+		{"cake", 10, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			hits := index.Search(c.query, c.limit)
+			got := []string{}
+			for _, h := range hits {
+				got = append(got, h.Journal.JournalId)
+			}
+			if strings.Join(got, "|") != strings.Join(c.want, "|") {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/husobee/vestigo"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	"github.com/twitchtv/twirp"
+)
+
+// a journal_tags row's source says how the tag got there, so re-parsing
+// hashtags after an edit doesn't drop tags set by hand or by a prompt.
+const (
+	tagSourceHashtag = "hashtag"
+	tagSourceManual  = "manual"
+	tagSourcePrompt  = "prompt"
+	maxTagLength     = 64
+)
+
+var (
+	hashtagRegexp = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&])#([\p{L}\p{N}_-]+)`)
+	tagRegexp     = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+)
+
+// normalizeTag lowercases a tag and strips a leading #, it returns "" for
+// anything that isn't a valid tag.
+func normalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if len(tag) > maxTagLength || !tagRegexp.MatchString(tag) {
+		return ""
+	}
+	return tag
+}
+
+// parseTags splits a comma or space separated list of tags, like the one
+// typed into the journal page.
+func parseTags(list string) []string {
+	tags := []string{}
+	for _, tag := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if tag = normalizeTag(tag); tag != "" && !Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// parseHashtags returns the #tags written in text.
+func parseHashtags(text string) []string {
+	tags := []string{}
+	for _, match := range hashtagRegexp.FindAllStringSubmatch(text, -1) {
+		if tag := normalizeTag(match[1]); tag != "" && !Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (s *NotifyAppServer) addJournalTags(ctx context.Context, j *pb.Journal, tags []string, source string) error {
+	return s.repo.AddJournalTags(ctx, j.PhoneNumber, j.JournalId, tags, source)
+}
+
+// setJournalTags replaces the tags on j that came from source.
+func (s *NotifyAppServer) setJournalTags(ctx context.Context, j *pb.Journal, tags []string, source string) error {
+	if err := s.repo.DeleteJournalTags(ctx, j.JournalId, source); err != nil {
+		return errors.Wrap(err, "failed to delete tags")
+	}
+	return s.addJournalTags(ctx, j, tags, source)
+}
+
+type cloudTag struct {
+	*pb.TagCount
+	// Size is a font size in em, from 1 to 2 by count
+	Size float64
+}
+
+func tagCloud(counts []*pb.TagCount) []cloudTag {
+	max := int32(1)
+	for _, c := range counts {
+		if c.Count > max {
+			max = c.Count
+		}
+	}
+	cloud := []cloudTag{}
+	for _, c := range counts {
+		cloud = append(cloud, cloudTag{TagCount: c, Size: 1 + float64(c.Count-1)/float64(max)})
+	}
+	return cloud
+}
+
+func (s *NotifyAppServer) ListTags(ctx context.Context, req *pb.ListTagsReq) (*pb.TagList, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.ListTagCounts(ctx, phoneNumber)
+	if err != nil {
+		Logger(ctx).Errorf("failed to get tags: %s", err)
+		return nil, twirp.InternalError("failed to list tags")
+	}
+	return &pb.TagList{Tags: counts}, nil
+}
+
+// SetJournalTags replaces the tags set by hand on a journal, hashtags in the
+// entry and prompt tags are kept.
+func (s *NotifyAppServer) SetJournalTags(ctx context.Context, req *pb.Journal) (*pb.Journal, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	tags := []string{}
+	for _, tag := range req.Tags {
+		normalized := normalizeTag(tag)
+		if normalized == "" {
+			return nil, twirp.InvalidArgumentError("tags", "invalid tag '"+tag+"'")
+		}
+		tags = append(tags, normalized)
+	}
+
+	j, err := s.getJournal(ctx, phoneNumber, req.JournalId)
+	if err != nil {
+		if errors.Cause(err) == errNotFound {
+			return nil, twirp.NotFoundError("journal not found")
+		}
+		Logger(ctx).Errorf("failed to get journal: %s", err)
+		return nil, twirp.InternalError("failed to set tags")
+	}
+	if err := s.setJournalTags(ctx, j, tags, tagSourceManual); err != nil {
+		Logger(ctx).Errorf("failed to set tags: %s", err)
+		return nil, twirp.InternalError("failed to set tags")
+	}
+	return s.GetJournal(ctx, j)
+}
+
+func (s *NotifyAppServer) PostJournalTags(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to parse form: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	j, err := s.getJournal(r.Context(), user.PhoneNumber, vestigo.Param(r, "journal_id"))
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to get journal: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	if err := s.setJournalTags(r.Context(), j, parseTags(r.PostForm.Get("journal_tags")), tagSourceManual); err != nil {
+		Logger(r.Context()).Errorf("failed to set tags: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	http.Redirect(w, r, "/journal", http.StatusFound)
+}
@@ -10,22 +10,24 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (s *NotifyAppServer) populateTemplateByID(ctx context.Context, db Database, notificationID string, payload interface{}) (string, error) {
-	notification, err := s.getNotification(ctx, db, notificationID)
+func (s *NotifyAppServer) populateTemplateByID(ctx context.Context, notificationID string, payload interface{}) (string, error) {
+	notification, err := s.repo.GetNotification(ctx, notificationID)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get notification")
 	}
-	return s.populateTemplate(ctx, db, notification, payload)
+	return s.populateTemplate(ctx, notification, payload)
 }
 
-func (s *NotifyAppServer) populateTemplate(ctx context.Context, db Database, notification *pb.Notification, payload interface{}) (string, error) {
+func (s *NotifyAppServer) populateTemplate(ctx context.Context, notification *pb.Notification, payload interface{}) (string, error) {
 	switch notification.Type {
 	case "registration":
-		return s.populateRegistrationTemplate(ctx, db, notification, payload)
+		return s.populateRegistrationTemplate(ctx, notification, payload)
 	case "reminder":
 		return notification.Template, nil
 	case "prompt":
 		return notification.Template, nil
+	case "alert":
+		return s.populateAlertTemplate(ctx, notification, payload)
 	default:
 		return "", fmt.Errorf("notification type: '%s' is unhandled", notification.Type)
 	}
@@ -35,11 +37,11 @@ type regAckPayload struct {
 	Name string
 }
 
-func (s *NotifyAppServer) populateRegistrationTemplate(ctx context.Context, db Database, notification *pb.Notification, payload interface{}) (string, error) {
+func (s *NotifyAppServer) populateRegistrationTemplate(ctx context.Context, notification *pb.Notification, payload interface{}) (string, error) {
 	if notification.Name == "register" {
 		return notification.Template, nil
 	} else if notification.Name != "register-ack" {
-		return "", fmt.Errorf("reg notification name: '%s' is unhandled")
+		return "", fmt.Errorf("reg notification name: '%s' is unhandled", notification.Name)
 	}
 
 	buf := &bytes.Buffer{}
@@ -48,3 +50,15 @@ func (s *NotifyAppServer) populateRegistrationTemplate(ctx context.Context, db D
 	}
 	return buf.String(), nil
 }
+
+func (s *NotifyAppServer) populateAlertTemplate(ctx context.Context, notification *pb.Notification, payload interface{}) (string, error) {
+	tmpl, err := template.New(notification.Name).Parse(notification.Template)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse %s", notification.Name)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, payload); err != nil {
+		return "", errors.Wrapf(err, "failed to execute %s", notification.Name)
+	}
+	return buf.String(), nil
+}
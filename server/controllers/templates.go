@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikerjacobi/notify-app/client"
+	"github.com/pkg/errors"
+)
+
+// templates is every page parsed with base.html, keyed by page name.  pages
+// are parsed once at startup, or again whenever the files change when reload
+// is set for development.
+type templates struct {
+	fsys     fs.FS
+	reload   bool
+	mu       sync.Mutex
+	pages    map[string]*template.Template
+	modified time.Time
+}
+
+// clientFiles is the embedded client, or the one in dir if it's set.
+func clientFiles(dir string) fs.FS {
+	if dir == "" {
+		return client.Files
+	}
+	return os.DirFS(dir)
+}
+
+func loadTemplates(fsys fs.FS, reload bool) (*templates, error) {
+	t := &templates{fsys: fsys, reload: reload}
+	pages, err := t.parse()
+	if err != nil {
+		return nil, err
+	}
+	t.pages, t.modified = pages, t.lastModified()
+	return t, nil
+}
+
+func (t *templates) parse() (map[string]*template.Template, error) {
+	names, err := fs.Glob(t.fsys, "*.html")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list templates")
+	}
+	pages := map[string]*template.Template{}
+	for _, name := range names {
+		if name == "base.html" {
+			continue
+		}
+		tmpl, err := template.ParseFS(t.fsys, "base.html", name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", name)
+		}
+		pages[strings.TrimSuffix(name, ".html")] = tmpl
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no page templates found")
+	}
+	return pages, nil
+}
+
+// lastModified is when the newest template changed, zero for embedded files.
+func (t *templates) lastModified() time.Time {
+	latest := time.Time{}
+	names, _ := fs.Glob(t.fsys, "*.html")
+	for _, name := range names {
+		if info, err := fs.Stat(t.fsys, name); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// page returns the named page's template, reparsing first if reload is set
+// and a file changed.  a failed reparse is returned, and retried next time.
+func (t *templates) page(name string) (*template.Template, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.reload {
+		if modified := t.lastModified(); !modified.Equal(t.modified) {
+			pages, err := t.parse()
+			if err != nil {
+				return nil, err
+			}
+			t.pages, t.modified = pages, modified
+		}
+	}
+	tmpl, ok := t.pages[name]
+	if !ok {
+		return nil, fmt.Errorf("page '%s' has no template", name)
+	}
+	return tmpl, nil
+}
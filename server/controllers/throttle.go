@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// attempt kinds tracked in the login_attempts table
+const (
+	loginAttempt = "login"
+	otpAttempt   = "otp"
+)
+
+// all throttling state lives in login_attempts, so it is shared by every
+// replica.  failures only count if they are inside attemptWindow and, for a
+// phone number, after its last success.
+var (
+	attemptWindow   = 30 * time.Minute
+	freeAttempts    = 3
+	baseDelay       = 2 * time.Second
+	maxDelay        = 5 * time.Minute
+	lockoutDuration = 30 * time.Minute
+
+	phoneLockoutAttempts = 10
+	ipLockoutAttempts    = 50
+
+	lockoutNotificationID = "4f9b3c1e-8f1a-4a53-9d0a-6c2f2f7e9b12"
+)
+
+type throttledError struct {
+	retryAfter time.Duration
+}
+
+func (e *throttledError) Error() string {
+	return fmt.Sprintf("too many failed attempts, retry in %s", e.retryAfter)
+}
+
+type lockoutPayload struct {
+	Minutes int
+}
+
+// clientIP returns the address of the caller.  X-Forwarded-For is only
+// believed from a trusted proxy, and then only back to the first entry no
+// trusted proxy wrote, the ones before it are supplied by the client.
+func (s *NotifyAppServer) clientIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	if !s.trustedProxy(ip) {
+		return ip
+	}
+	fwd := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(fwd) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(fwd[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !s.trustedProxy(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// trustedProxy says whether ip is one of the configured proxies, whose
+// X-Forwarded-* headers we believe.
+func (s *NotifyAppServer) trustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, proxy := range s.proxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// throttleDelay is how long to wait after the most recent of n failures.
+func throttleDelay(n, lockoutAttempts int) time.Duration {
+	if n >= lockoutAttempts {
+		return lockoutDuration
+	}
+	if n < freeAttempts {
+		return 0
+	}
+	delay := baseDelay << uint(n-freeAttempts)
+	if delay > maxDelay || delay <= 0 {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// claimAttempt records an attempt by the phone number and ip as a failure
+// before it is verified, unless either has failed too often recently to be
+// allowed another yet, when it returns a *throttledError.  the check and the
+// claim are atomic, so concurrent guesses count against each other.
+// succeedAttempt clears the claim of an attempt that turns out right.
+func (s *NotifyAppServer) claimAttempt(ctx context.Context, kind, phoneNumber, ip string) (string, error) {
+	current := s.now(ctx)
+	attemptID, err := s.repo.ClaimLoginAttempt(ctx, kind, phoneNumber, ip, current.Add(-attemptWindow), checkThrottle(current))
+	if terr, ok := errors.Cause(err).(*throttledError); ok {
+		return "", terr
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to claim attempt")
+	}
+	return attemptID, nil
+}
+
+// checkThrottle returns the check for a claim at current, which fails with a
+// *throttledError if the latest of n failures by column was too recent.
+func checkThrottle(current time.Time) func(column string, n int, last time.Time) error {
+	return func(column string, n int, last time.Time) error {
+		if n == 0 {
+			return nil
+		}
+		lockoutAttempts := phoneLockoutAttempts
+		if column == "ip" {
+			lockoutAttempts = ipLockoutAttempts
+		}
+		if wait := last.Add(throttleDelay(n, lockoutAttempts)).Sub(current); wait > 0 {
+			return &throttledError{retryAfter: wait.Round(time.Second)}
+		}
+		return nil
+	}
+}
+
+// succeedAttempt marks a claimed attempt a success, which resets the
+// failures counted against its phone number.
+func (s *NotifyAppServer) succeedAttempt(ctx context.Context, attemptID string) error {
+	return errors.Wrap(s.repo.SucceedLoginAttempt(ctx, attemptID), "failed to succeed attempt")
+}
+
+// failAttempt leaves a claimed attempt a failure.  the failure that locks a
+// phone number out also texts its owner.
+func (s *NotifyAppServer) failAttempt(ctx context.Context, kind, phoneNumber string) error {
+	if phoneNumber == "" {
+		return nil
+	}
+	n, _, err := s.getFailedAttempts(ctx, kind, "phone_number", phoneNumber)
+	if err != nil {
+		return errors.Wrap(err, "failed to get failed attempts")
+	}
+	if n != phoneLockoutAttempts {
+		return nil
+	}
+	Logger(ctx).WithFields(logrus.Fields{"phone_number": phoneNumber, "kind": kind}).Warnf("locked out after %d failed attempts", n)
+	if err := s.sendLockoutAlert(ctx, phoneNumber); err != nil {
+		return errors.Wrap(err, "failed to send lockout alert")
+	}
+	return nil
+}
+
+func (s *NotifyAppServer) sendLockoutAlert(ctx context.Context, phoneNumber string) error {
+	if _, err := s.repo.GetUser(ctx, phoneNumber); err != nil {
+		//nobody to alert
+		return nil
+	}
+
+	payload := &lockoutPayload{Minutes: int(lockoutDuration / time.Minute)}
+	msg, err := s.populateTemplateByID(ctx, lockoutNotificationID, payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to populate lockout tmpl")
+	}
+	if err := s.sendSMS(ctx, phoneNumber, msg); err != nil {
+		return errors.Wrap(err, "failed to send sms")
+	}
+	comm := &pb.Communication{From: s.config.From, To: phoneNumber, Message: msg, NotificationId: lockoutNotificationID}
+	if err := s.insertCommunication(ctx, comm); err != nil {
+		Logger(ctx).Warnf("failed to insert comms: %s", err)
+	}
+	return nil
+}
+
+// getFailedAttempts counts failures by phone_number or ip since the later of
+// the window start and the last success, and returns the latest failure time.
+func (s *NotifyAppServer) getFailedAttempts(ctx context.Context, kind, column, value string) (int, time.Time, error) {
+	return s.repo.CountFailedLoginAttempts(ctx, kind, column, value, s.now(ctx).Add(-attemptWindow))
+}
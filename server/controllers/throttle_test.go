@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestThrottleDelay(t *testing.T) {
+	cases := []struct {
+		n, lockoutAttempts int
+		want               time.Duration
+	}{
+		{0, phoneLockoutAttempts, 0},
+		{freeAttempts - 1, phoneLockoutAttempts, 0},
+		{freeAttempts, phoneLockoutAttempts, baseDelay},
+		{freeAttempts + 1, phoneLockoutAttempts, 2 * baseDelay},
+		{freeAttempts + 2, phoneLockoutAttempts, 4 * baseDelay},
+		{phoneLockoutAttempts - 1, phoneLockoutAttempts, baseDelay << uint(phoneLockoutAttempts-1-freeAttempts)},
+		{phoneLockoutAttempts, phoneLockoutAttempts, lockoutDuration},
+		{20, ipLockoutAttempts, maxDelay},
+		//big enough to shift past the top of a Duration
+		{ipLockoutAttempts - 1, ipLockoutAttempts, maxDelay},
+		{ipLockoutAttempts, ipLockoutAttempts, lockoutDuration},
+	}
+	for _, c := range cases {
+		if got := throttleDelay(c.n, c.lockoutAttempts); got != c.want {
+			t.Errorf("throttleDelay(%d, %d) = %s, want %s", c.n, c.lockoutAttempts, got, c.want)
+		}
+	}
+}
+
+func TestCheckThrottle(t *testing.T) {
+	current := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name   string
+		column string
+		n      int
+		ago    time.Duration
+		// want is the retry after, 0 if the attempt is allowed
+		want time.Duration
+	}{
+		{name: "no failures", column: "phone_number"},
+		{name: "free failures", column: "phone_number", n: freeAttempts - 1},
+		{name: "delayed", column: "phone_number", n: freeAttempts, ago: time.Second, want: baseDelay - time.Second},
+		{name: "delay passed", column: "phone_number", n: freeAttempts, ago: baseDelay},
+		{name: "phone locked out", column: "phone_number", n: phoneLockoutAttempts, ago: time.Minute, want: lockoutDuration - time.Minute},
+		{name: "ip not locked out at the phone's limit", column: "ip", n: phoneLockoutAttempts, ago: time.Hour},
+		{name: "ip locked out", column: "ip", n: ipLockoutAttempts, ago: time.Minute, want: lockoutDuration - time.Minute},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := checkThrottle(current)(c.column, c.n, current.Add(-c.ago))
+			if c.want == 0 {
+				if err != nil {
+					t.Fatalf("got %s, want the attempt allowed", err)
+				}
+				return
+			}
+			terr, ok := err.(*throttledError)
+			if !ok {
+				t.Fatalf("got %v, want a *throttledError", err)
+			}
+			if terr.retryAfter != c.want {
+				t.Errorf("got retry after %s, want %s", terr.retryAfter, c.want)
+			}
+		})
+	}
+}
+
+func TestClaimAttempt(t *testing.T) {
+	const (
+		phone = "+15555550100"
+		other = "+15555550101"
+		ip    = "192.0.2.1"
+	)
+	ctx := context.Background()
+	s := newTestServer(t)
+
+	claim := func(phoneNumber string) (string, error) {
+		t.Helper()
+		attemptID, err := s.claimAttempt(ctx, loginAttempt, phoneNumber, ip)
+		if _, ok := err.(*throttledError); err != nil && !ok {
+			t.Fatalf("failed to claim attempt: %s", err)
+		}
+		return attemptID, err
+	}
+
+	var last string
+	for i := 0; i < freeAttempts; i++ {
+		attemptID, err := claim(phone)
+		if err != nil {
+			t.Fatalf("got %s for free attempt %d", err, i+1)
+		}
+		last = attemptID
+	}
+	if _, err := claim(phone); err == nil {
+		t.Fatalf("claimed attempt %d without waiting", freeAttempts+1)
+	}
+
+	//a success clears the phone number's failures but not the ip's
+	if err := s.succeedAttempt(ctx, last); err != nil {
+		t.Fatalf("failed to succeed attempt: %s", err)
+	}
+	if _, err := s.claimAttempt(ctx, loginAttempt, phone, ""); err != nil {
+		t.Errorf("got %s claiming by phone number after a success", err)
+	}
+	//the success was one of the ip's claims, it has one more to go
+	if _, err := claim(other); err != nil {
+		t.Fatalf("got %s for the ip's last free attempt", err)
+	}
+	if _, err := claim(other); err == nil {
+		t.Errorf("claimed another number from an ip with %d failures", freeAttempts)
+	}
+	if _, err := s.claimAttempt(ctx, otpAttempt, phone, ip); err != nil {
+		t.Errorf("got %s for a login code, whose failures are counted apart", err)
+	}
+}
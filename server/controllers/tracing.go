@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/mikerjacobi/notify-app/server/controllers")
+
+// InitTracing exports spans to config.TraceExporter: otlp over http to
+// TraceEndpoint, or the OTEL_EXPORTER_OTLP_* env if that's empty, stdout for
+// local use, or none.  the returned func flushes and stops the exporter.
+func InitTracing(ctx context.Context, config Configuration) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.TraceExporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "otlp":
+		opts := []otlptracehttp.Option{}
+		if config.TraceEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(config.TraceEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("trace exporter '%s' is unhandled", config.TraceExporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.TraceSampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "notify"),
+			attribute.String("service.version", Version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// endSpan ends span, marking it failed if err isn't nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TracingMiddleware continues the caller's trace, or starts one, with a span
+// per request named by its route.
+func TracingMiddleware(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		route := routeOf(r)
+		ctx, span := tracer.Start(ctx, r.Method+" "+route, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			attribute.String("http.method", r.Method),
+			attribute.String("http.route", route),
+		))
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		f(rec, r.WithContext(ctx))
+		span.SetAttributes(attribute.Int("http.status_code", rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	}
+}
+
+type twirpSpanKey struct{}
+
+// startTwirpSpan starts the span for a routed twirp call, ended by
+// endTwirpSpan once the response is sent.
+func startTwirpSpan(ctx context.Context) context.Context {
+	method, _ := twirp.MethodName(ctx)
+	ctx, span := tracer.Start(ctx, "twirp "+method, trace.WithAttributes(
+		attribute.String("rpc.system", "twirp"),
+		attribute.String("rpc.method", method),
+	))
+	return context.WithValue(ctx, twirpSpanKey{}, span)
+}
+
+func twirpSpanError(ctx context.Context, err twirp.Error) {
+	if span, ok := ctx.Value(twirpSpanKey{}).(trace.Span); ok {
+		span.SetAttributes(attribute.String("rpc.twirp.code", string(err.Code())))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Msg())
+	}
+}
+
+func endTwirpSpan(ctx context.Context) {
+	if span, ok := ctx.Value(twirpSpanKey{}).(trace.Span); ok {
+		span.End()
+	}
+}
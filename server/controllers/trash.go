@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/husobee/vestigo"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/twitchtv/twirp"
+)
+
+var purgeInterval = time.Hour
+
+func (s *NotifyAppServer) getTrashedJournals(ctx context.Context, phoneNumber string) ([]*pb.Journal, error) {
+	journals, err := s.repo.ListTrashedJournals(ctx, phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range journals {
+		if err := s.decryptJournal(ctx, j); err != nil {
+			return nil, err
+		}
+	}
+	return journals, nil
+}
+
+// purgeTrash hard deletes everything that has been in the trash longer than
+// retention, along with the revisions, tags and attachments of purged
+// journals.
+func (s *NotifyAppServer) purgeTrash(ctx context.Context, retention time.Duration) error {
+	cutoff := s.now(ctx).Add(-retention)
+	attachmentIDs, err := s.repo.ListPurgeableAttachments(ctx, cutoff)
+	if err != nil {
+		return errors.Wrap(err, "failed to list attachments")
+	}
+	for _, id := range attachmentIDs {
+		if err := s.blobs.Delete(ctx, attachmentKey(id)); err != nil {
+			return errors.Wrap(err, "failed to delete attachment")
+		}
+		if err := s.blobs.Delete(ctx, thumbnailKey(id)); err != nil {
+			return errors.Wrap(err, "failed to delete thumbnail")
+		}
+	}
+	return s.repo.PurgeTrash(ctx, cutoff)
+}
+
+// PurgeLoop purges expired trash every purgeInterval until ctx is done or the
+// server is shut down.
+func (s *NotifyAppServer) PurgeLoop(ctx context.Context) {
+	for {
+		runCtx := WithRequestID(ctx, uuid.NewV4().String())
+		if err := s.purgeTrash(runCtx, s.config.TrashRetention); err != nil {
+			Logger(runCtx).Errorf("failed to purge trash: %s", err)
+		}
+		if !s.wait(ctx, purgeInterval) {
+			return
+		}
+	}
+}
+
+func (s *NotifyAppServer) ListTrash(ctx context.Context, req *pb.ListTrashReq) (*pb.Trash, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	journals, err := s.getTrashedJournals(ctx, phoneNumber)
+	if err != nil {
+		Logger(ctx).Errorf("failed to get trashed journals: %s", err)
+		return nil, twirp.InternalError("failed to list trash")
+	}
+	userNotifications, err := s.repo.ListTrashedUserNotifications(ctx, phoneNumber)
+	if err != nil {
+		Logger(ctx).Errorf("failed to get trashed user notifications: %s", err)
+		return nil, twirp.InternalError("failed to list trash")
+	}
+	return &pb.Trash{Journals: journals, UserNotifications: userNotifications}, nil
+}
+
+func (s *NotifyAppServer) RestoreJournal(ctx context.Context, req *pb.Journal) (*pb.Journal, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RestoreJournal(ctx, phoneNumber, req.JournalId); err != nil {
+		if errors.Cause(err) == errNotFound {
+			return nil, twirp.NotFoundError("journal not in trash")
+		}
+		Logger(ctx).Errorf("failed to restore journal: %s", err)
+		return nil, twirp.InternalError("failed to restore journal")
+	}
+	return s.GetJournal(ctx, &pb.Journal{PhoneNumber: phoneNumber, JournalId: req.JournalId})
+}
+
+func (s *NotifyAppServer) RestoreUserNotification(ctx context.Context, req *pb.UserNotification) (*pb.UserNotification, error) {
+	phoneNumber, err := s.authorizePhoneNumber(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !govalidator.IsUUID(req.NotificationId) {
+		return nil, twirp.InvalidArgumentError("notification_id", "invalid")
+	}
+
+	if err := s.repo.RestoreUserNotification(ctx, phoneNumber, req.NotificationId); err != nil {
+		if errors.Cause(err) == errNotFound {
+			return nil, twirp.NotFoundError("user notification not in trash")
+		}
+		Logger(ctx).Errorf("failed to restore user notification: %s", err)
+		return nil, twirp.InternalError("failed to restore user notification")
+	}
+
+	up, err := s.repo.GetUserNotification(ctx, phoneNumber, req.NotificationId)
+	if err != nil {
+		Logger(ctx).Errorf("failed to get user notification: %s", err)
+		return nil, twirp.InternalError("failed to restore user notification")
+	}
+	return up, nil
+}
+
+func (s *NotifyAppServer) GetTrash(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	journals, err := s.getTrashedJournals(r.Context(), user.PhoneNumber)
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to get trashed journals: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	userNotifications, err := s.repo.ListTrashedUserNotifications(r.Context(), user.PhoneNumber)
+	if err != nil {
+		Logger(r.Context()).Errorf("failed to get trashed user notifications: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+
+	payload := struct {
+		Journals          []*pb.Journal
+		UserNotifications []*pb.UserNotification
+		RetentionDays     int
+	}{journals, userNotifications, int(s.config.TrashRetention.Hours() / 24)}
+	s.renderTemplate(w, r, "trash", payload)
+}
+
+func (s *NotifyAppServer) PostRestoreJournal(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	if err := s.repo.RestoreJournal(r.Context(), user.PhoneNumber, vestigo.Param(r, "journal_id")); err != nil {
+		Logger(r.Context()).Errorf("failed to restore journal: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	http.Redirect(w, r, "/trash", http.StatusFound)
+}
+
+func (s *NotifyAppServer) PostRestoreUserNotification(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(*pb.User)
+	if !ok {
+		Logger(r.Context()).Errorf("failed to get user")
+		http.Redirect(w, r, "/logout", http.StatusFound)
+		return
+	}
+
+	if err := s.repo.RestoreUserNotification(r.Context(), user.PhoneNumber, vestigo.Param(r, "notification_id")); err != nil {
+		Logger(r.Context()).Errorf("failed to restore user notification: %s", err)
+		s.renderTemplate(w, r, "error", nil)
+		return
+	}
+	http.Redirect(w, r, "/trash", http.StatusFound)
+}
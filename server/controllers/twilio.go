@@ -3,16 +3,24 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/gorilla/schema"
 	pb "github.com/mikerjacobi/notify-app/server/rpc"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type TwilioConfig struct {
@@ -22,15 +30,25 @@ type TwilioConfig struct {
 	Password string `json:"password"`
 }
 
+// TwilioInboundReq is an inbound sms or mms, its MediaUrlN fields are read
+// by parseInboundMedia.
 type TwilioInboundReq struct {
-	From string `schema:"From"`
-	Body string `schema:"Body"`
+	From        string `schema:"From"`
+	FromCountry string `schema:"FromCountry"`
+	Body        string `schema:"Body"`
+	NumMedia    int    `schema:"NumMedia"`
 }
 
-func (s *NotifyAppServer) sendSMS(ctx context.Context, to string, body string) error {
+func (s *NotifyAppServer) sendSMS(ctx context.Context, to string, body string) (err error) {
+	ctx, span := tracer.Start(ctx, "twilio send sms", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() {
+		observeSend(channelSMS, err)
+		endSpan(span, err)
+	}()
 
-	if to[0:3] == "000" {
-		logrus.Infof("TEST: sent '%s' to %s", body, to)
+	//bodies aren't logged, they can be login codes or someone's journal
+	if isTestNumber(to) {
+		Logger(ctx).Infof("TEST: sent %d chars to %s", len(body), to)
 		return nil
 	}
 
@@ -38,7 +56,7 @@ func (s *NotifyAppServer) sendSMS(ctx context.Context, to string, body string) e
 	v.Add("To", to)
 	v.Add("From", s.config.From)
 	v.Add("Body", body)
-	req, err := http.NewRequest(http.MethodPost, s.config.API+"/Messages.json", bytes.NewBufferString(v.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.API+"/Messages.json", bytes.NewBufferString(v.Encode()))
 	if err != nil {
 		return errors.Wrap(err, "failed to create request")
 	}
@@ -49,6 +67,7 @@ func (s *NotifyAppServer) sendSMS(ctx context.Context, to string, body string) e
 		return errors.Wrap(err, "failed to execute request")
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
 
 	if resp.StatusCode != http.StatusCreated {
 		if respBody, err := ioutil.ReadAll(resp.Body); err == nil {
@@ -56,46 +75,119 @@ func (s *NotifyAppServer) sendSMS(ctx context.Context, to string, body string) e
 		}
 		return fmt.Errorf("received http %d from twilio", resp.StatusCode)
 	}
-	logrus.Infof("sent '%s' to %s", body, to)
+	Logger(ctx).Infof("sent %d chars to %s", len(body), to)
 	return nil
 }
 
+// twilioSignatureHeader carries twilio's signature of a webhook request.
+const twilioSignatureHeader = "X-Twilio-Signature"
+
+// twilioSignature is how twilio signs a webhook request: base64 of the
+// hmac-sha1, keyed by the auth token, of the url it called followed by each
+// post param's name and value, sorted by name.
+func twilioSignature(authToken, webhookURL string, form url.Values) string {
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	mac := hmac.New(sha1.New, []byte(authToken))
+	io.WriteString(mac, webhookURL)
+	for _, name := range names {
+		values := append([]string{}, form[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			io.WriteString(mac, name+value)
+		}
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// baseURL is the scheme and host r reached us at, PublicURL if it's set.
+func (s *NotifyAppServer) baseURL(r *http.Request) string {
+	base := strings.TrimSuffix(s.config.PublicURL, "/")
+	if base == "" {
+		scheme := "http"
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		if r.TLS != nil {
+			scheme = "https"
+		} else if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" && s.trustedProxy(host) {
+			scheme = proto
+		}
+		base = scheme + "://" + r.Host
+	}
+	return base
+}
+
+// webhookURL is the url twilio called for r.
+func (s *NotifyAppServer) webhookURL(r *http.Request) string {
+	return s.baseURL(r) + r.URL.RequestURI()
+}
+
+// validTwilioRequest checks r, its form already parsed, was signed by twilio
+// with our auth token.
+func (s *NotifyAppServer) validTwilioRequest(r *http.Request) bool {
+	signature := r.Header.Get(twilioSignatureHeader)
+	if signature == "" || s.config.Password == "" {
+		return false
+	}
+	want := twilioSignature(s.config.Password, s.webhookURL(r), r.PostForm)
+	return hmac.Equal([]byte(signature), []byte(want))
+}
+
 func (s *NotifyAppServer) TwilioInboundHandler(w http.ResponseWriter, r *http.Request) {
 	lf := logrus.Fields{}
-	ctx := context.Background()
+	ctx := r.Context()
 	if err := r.ParseForm(); err != nil {
-		logrus.Errorf("failed to parse form: %s", err)
+		Logger(ctx).Errorf("failed to parse form: %s", err)
 		w.WriteHeader(400)
 		return
 	}
+	if !s.validTwilioRequest(r) {
+		Logger(ctx).Errorf("invalid twilio signature for %s", s.webhookURL(r))
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
 
 	payload := TwilioInboundReq{}
 	decoder := schema.NewDecoder()
 	decoder.IgnoreUnknownKeys(true)
 	if err := decoder.Decode(&payload, r.PostForm); err != nil {
-		logrus.Errorf("failed to decode body: %s", err)
+		Logger(ctx).Errorf("failed to decode body: %s", err)
 		w.WriteHeader(400)
 		return
 	}
-	payload.From = strings.Replace(payload.From, "+1", "", -1)
 	lf["from"] = payload.From
+	inboundMessages.WithLabelValues(inboundCommand(payload.Body)).Inc()
+	region := payload.FromCountry
+	if region == "" {
+		region = s.config.DefaultRegion
+	}
+	from, err := normalizePhoneNumber(payload.From, region)
+	if err != nil {
+		Logger(ctx).WithFields(lf).Errorf("failed to normalize from: %s", err)
+		w.WriteHeader(400)
+		return
+	}
+	payload.From = from
+	setLogUser(ctx, from)
 
 	recv := &pb.Communication{To: s.config.From, From: payload.From, Message: payload.Body}
-	if err := s.insertCommunication(ctx, s.DB, recv); err != nil {
-		logrus.WithFields(lf).Warn("failed to insert comms: %s", err)
+	if err := s.insertCommunication(ctx, recv); err != nil {
+		Logger(ctx).WithFields(lf).Warnf("failed to insert comms: %s", err)
 	}
 
-	user, err := s.getUser(ctx, s.DB, payload.From)
+	user, err := s.repo.GetUser(ctx, payload.From)
 	if err != nil {
-		logrus.WithFields(lf).Errorf("failed to get user: %+v", err)
+		Logger(ctx).WithFields(lf).Errorf("failed to get user: %+v", err)
 		w.WriteHeader(404)
 		return
 	}
 
 	//handle the incoming message
-	if payload.Body == "reg" {
+	if inboundCommand(payload.Body) == "reg" {
 		if err := s.verifyUser(ctx, user); err != nil {
-			logrus.WithFields(lf).Errorf("failed to register user: %s", err)
+			Logger(ctx).WithFields(lf).Errorf("failed to register user: %s", err)
 			w.WriteHeader(404)
 			return
 		}
@@ -103,23 +195,43 @@ func (s *NotifyAppServer) TwilioInboundHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	prompt, err := s.getMostRecentPrompt(ctx, s.DB, payload.From)
+	prompt, err := s.repo.GetMostRecentPrompt(ctx, payload.From)
 	if err != nil {
-		logrus.WithFields(lf).Errorf("failed to get last notification: %s", err)
+		Logger(ctx).WithFields(lf).Errorf("failed to get last notification: %s", err)
 		w.WriteHeader(500)
 		return
 	}
 	journal := &pb.Journal{
 		CommsId:     recv.CommsId,
 		PhoneNumber: payload.From,
-		Title:       prompt,
+		Title:       prompt.Template,
 		Entry:       recv.Message,
 	}
-	if err := s.insertJournal(ctx, s.DB, journal); err != nil {
-		logrus.WithFields(lf).Errorf("failed to insert journal: %s", err)
+	if err := s.insertJournal(ctx, s.repo, journal); err != nil {
+		Logger(ctx).WithFields(lf).Errorf("failed to insert journal: %s", err)
 		w.WriteHeader(500)
 		return
 	}
+	if media := parseInboundMedia(r.PostForm); len(media) > 0 {
+		//downloading and scaling can outlast twilio's webhook timeout, so
+		//it's done after we've answered
+		attachTo := &pb.Journal{JournalId: journal.JournalId, PhoneNumber: journal.PhoneNumber}
+		s.goBackground(ctx, func(ctx context.Context) { s.attachMedia(ctx, attachTo, media) })
+	}
+	if prompt.DefaultTag != "" {
+		if err := s.addJournalTags(ctx, journal, []string{prompt.DefaultTag}, tagSourcePrompt); err != nil {
+			Logger(ctx).WithFields(lf).Errorf("failed to tag journal: %s", err)
+		}
+	}
 
 	w.WriteHeader(200)
 }
+
+// inboundCommand is what an inbound message asks for: reg verifies the
+// account and anything else is a journal entry.
+func inboundCommand(body string) string {
+	if body == "reg" {
+		return "reg"
+	}
+	return "journal"
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestTwilioSignature(t *testing.T) {
+	//the worked example from twilio's security docs
+	form := url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+12349013030"},
+		"Digits":  {"1234"},
+		"From":    {"+12349013030"},
+		"To":      {"+18005551212"},
+	}
+	got := twilioSignature("12345", "https://mycompany.com/myapp.php?foo=1&bar=2", form)
+	if want := "0/KCTR6DLpKmkAf8muzZqo1nDgQ="; got != want {
+		t.Errorf("twilioSignature = %q, want %q", got, want)
+	}
+}
+
+func TestValidTwilioRequest(t *testing.T) {
+	const token = "authtoken"
+	form := url.Values{"From": {"+15551234567"}, "Body": {"hello"}}
+	sign := func(u string, f url.Values) string { return twilioSignature(token, u, f) }
+
+	proxies, err := parseProxies("10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name      string
+		publicURL string
+		password  string
+		remote    string
+		header    map[string]string
+		form      url.Values
+		signature string
+		want      bool
+	}{
+		{
+			name:      "signed for the request host",
+			password:  token,
+			signature: sign("http://notify.test/twilio/inbound?x=1", form),
+			want:      true,
+		},
+		{
+			name:      "signed for the public url",
+			publicURL: "https://notify.example.com/",
+			password:  token,
+			signature: sign("https://notify.example.com/twilio/inbound?x=1", form),
+			want:      true,
+		},
+		{
+			name:      "forwarded proto from a trusted proxy",
+			password:  token,
+			remote:    "10.0.0.1:4000",
+			header:    map[string]string{"X-Forwarded-Proto": "https"},
+			signature: sign("https://notify.test/twilio/inbound?x=1", form),
+			want:      true,
+		},
+		{
+			name:      "forwarded proto from anyone else",
+			password:  token,
+			header:    map[string]string{"X-Forwarded-Proto": "https"},
+			signature: sign("https://notify.test/twilio/inbound?x=1", form),
+		},
+		{
+			name:      "query left out",
+			password:  token,
+			signature: sign("http://notify.test/twilio/inbound", form),
+		},
+		{
+			name:      "body changed",
+			password:  token,
+			form:      url.Values{"From": {"+15551234567"}, "Body": {"goodbye"}},
+			signature: sign("http://notify.test/twilio/inbound?x=1", form),
+		},
+		{
+			name:      "wrong token",
+			password:  "othertoken",
+			signature: sign("http://notify.test/twilio/inbound?x=1", form),
+		},
+		{
+			name:     "no signature",
+			password: token,
+		},
+		{
+			name:      "no token configured",
+			signature: twilioSignature("", "http://notify.test/twilio/inbound?x=1", form),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestServer(t)
+			s.config.PublicURL = c.publicURL
+			s.config.Password = c.password
+			s.proxies = proxies
+
+			body := form
+			if c.form != nil {
+				body = c.form
+			}
+			r := httptest.NewRequest("POST", "http://notify.test/twilio/inbound?x=1", strings.NewReader(body.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if c.remote != "" {
+				r.RemoteAddr = c.remote
+			}
+			for name, value := range c.header {
+				r.Header.Set(name, value)
+			}
+			if c.signature != "" {
+				r.Header.Set(twilioSignatureHeader, c.signature)
+			}
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := s.validTwilioRequest(r); got != c.want {
+				t.Errorf("validTwilioRequest = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
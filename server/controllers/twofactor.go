@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikerjacobi/notify-app/server/repository"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+)
+
+type SessionConfig struct {
+	DeviceKey string `json:"device_key"`
+}
+
+const (
+	challengeCookie = "login_challenge"
+	deviceCookie    = "device"
+)
+
+var (
+	loginCodeLifetime       = 10 * time.Minute
+	trustedDeviceTTL        = 30 * 24 * time.Hour
+	loginCodeDigits         = 6
+	loginCodeMisses         = 5
+	loginCodeNotificationID = "c3d1f0a2-6b7e-4f25-8a4e-1d9b7c2e5f30"
+)
+
+type loginCodePayload struct {
+	Code    string
+	Minutes int
+}
+
+// only the sha256 of a login code is stored.
+func hashLoginCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+func loginCodeMatches(c *repository.LoginCode, code string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashLoginCode(code)), []byte(c.CodeHash)) == 1
+}
+
+func newLoginCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < loginCodeDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read random int")
+	}
+	return fmt.Sprintf("%0*d", loginCodeDigits, n), nil
+}
+
+// startLoginChallenge texts a one time code to the user and sets the cookie
+// that ties the browser to it until the code is entered on /login/verify.
+func (s *NotifyAppServer) startLoginChallenge(ctx context.Context, w http.ResponseWriter, user *pb.User) error {
+	code, err := newLoginCode()
+	if err != nil {
+		return errors.Wrap(err, "failed to generate code")
+	}
+	codeID, err := s.repo.InsertLoginCode(ctx, user.PhoneNumber, hashLoginCode(code), loginCodeLifetime)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert code")
+	}
+
+	payload := &loginCodePayload{Code: code, Minutes: int(loginCodeLifetime / time.Minute)}
+	msg, err := s.populateTemplateByID(ctx, loginCodeNotificationID, payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to populate login code tmpl")
+	}
+	//the code isn't written to communications, it would be readable there
+	if err := s.sendSMS(ctx, user.PhoneNumber, msg); err != nil {
+		return errors.Wrap(err, "failed to send sms")
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     challengeCookie,
+		Value:    codeID,
+		Path:     "/login",
+		MaxAge:   int(loginCodeLifetime / time.Second),
+		HttpOnly: true,
+	})
+	return nil
+}
+
+// device cookies are "<base64 phone_number:expiry>.<hex hmac>", signed with
+// the device_key from the session secrets.
+func (s *NotifyAppServer) signDevice(value string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.DeviceKey))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *NotifyAppServer) newDeviceCookie(phoneNumber string) *http.Cookie {
+	expires := time.Now().Add(trustedDeviceTTL)
+	value := base64.RawURLEncoding.EncodeToString([]byte(phoneNumber + ":" + strconv.FormatInt(expires.Unix(), 10)))
+	return &http.Cookie{
+		Name:     deviceCookie,
+		Value:    value + "." + s.signDevice(value),
+		Path:     "/login",
+		Expires:  expires,
+		HttpOnly: true,
+	}
+}
+
+// trustedDevice reports whether the request carries a valid remember-this-
+// device cookie for phoneNumber.
+func (s *NotifyAppServer) trustedDevice(r *http.Request, phoneNumber string) bool {
+	c, err := r.Cookie(deviceCookie)
+	if err != nil {
+		return false
+	}
+	parts := strings.SplitN(c.Value, ".", 2)
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(s.signDevice(parts[0]))) {
+		return false
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	fields := strings.SplitN(string(decoded), ":", 2)
+	if len(fields) != 2 || fields[0] != phoneNumber {
+		return false
+	}
+	expires, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return false
+	}
+	return time.Now().Unix() < expires
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikerjacobi/notify-app/server/repository"
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+)
+
+func TestLoginCodeMatches(t *testing.T) {
+	stored := &repository.LoginCode{CodeHash: hashLoginCode("042317")}
+	cases := []struct {
+		code string
+		want bool
+	}{
+		{"042317", true},
+		{" 042317\n", true},
+		{"42317", false},
+		{"042318", false},
+		{"", false},
+	}
+	for _, c := range cases {
+		if got := loginCodeMatches(stored, c.code); got != c.want {
+			t.Errorf("loginCodeMatches(%q) = %v, want %v", c.code, got, c.want)
+		}
+	}
+}
+
+func TestLoginCodeOneTimeUse(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+	const phone = "+15555550100"
+	if err := s.repo.InsertUser(ctx, &pb.User{PhoneNumber: phone, TwoFactor: true}); err != nil {
+		t.Fatalf("failed to insert user: %s", err)
+	}
+	codeID, err := s.repo.InsertLoginCode(ctx, phone, hashLoginCode("123456"), time.Minute)
+	if err != nil {
+		t.Fatalf("failed to insert code: %s", err)
+	}
+
+	w := postVerifyLogin(s, codeID, "123456")
+	if loc := w.Header().Get("Location"); loc != "/journal" {
+		t.Fatalf("got redirect to %q, want the code to log in", loc)
+	}
+	w = postVerifyLogin(s, codeID, "123456")
+	if loc := w.Header().Get("Location"); loc == "/journal" {
+		t.Fatalf("the code logged in twice")
+	}
+}
+
+func TestTrustedDevice(t *testing.T) {
+	s := newTestServer(t)
+	const phone = "+15555550100"
+	valid := s.newDeviceCookie(phone).Value
+	sign := func(srv *NotifyAppServer, payload string) string {
+		value := base64.RawURLEncoding.EncodeToString([]byte(payload))
+		return value + "." + srv.signDevice(value)
+	}
+	otherKey := newTestServer(t)
+	otherKey.config.DeviceKey = "fedcba9876543210fedcba9876543210"
+	future := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	past := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	cases := []struct {
+		name   string
+		cookie string
+		want   bool
+	}{
+		{name: "valid", cookie: valid, want: true},
+		{name: "missing"},
+		{name: "another number", cookie: sign(s, "+15555550101:"+future)},
+		{name: "expired", cookie: sign(s, phone+":"+past)},
+		{name: "signed with another key", cookie: sign(otherKey, phone+":"+future)},
+		{name: "bad signature", cookie: strings.SplitN(valid, ".", 2)[0] + ".00"},
+		{name: "value swapped under the signature", cookie: base64.RawURLEncoding.EncodeToString([]byte(phone+":"+future)) + "." + strings.SplitN(valid, ".", 2)[1]},
+		{name: "unsigned", cookie: strings.SplitN(valid, ".", 2)[0]},
+		{name: "signed garbage", cookie: sign(s, "garbage")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/login", nil)
+			if c.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: deviceCookie, Value: c.cookie})
+			}
+			if got := s.trustedDevice(r, phone); got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func postVerifyLogin(s *NotifyAppServer, codeID, code string) *httptest.ResponseRecorder {
+	form := url.Values{"code": {code}}
+	r := httptest.NewRequest("POST", "/login/verify", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.AddCookie(&http.Cookie{Name: challengeCookie, Value: codeID})
+	w := httptest.NewRecorder()
+	s.PostVerifyLogin(w, r)
+	return w
+}
+
+func TestLoginCodeBurnedAfterMisses(t *testing.T) {
+	//let every guess through the throttle, the code's own count has to stop them
+	defer func(n int) { freeAttempts = n }(freeAttempts)
+	freeAttempts = phoneLockoutAttempts
+
+	ctx := context.Background()
+	s := newTestServer(t)
+	const phone = "+15555550100"
+	if err := s.repo.InsertUser(ctx, &pb.User{PhoneNumber: phone, TwoFactor: true}); err != nil {
+		t.Fatalf("failed to insert user: %s", err)
+	}
+	codeID, err := s.repo.InsertLoginCode(ctx, phone, hashLoginCode("123456"), time.Minute)
+	if err != nil {
+		t.Fatalf("failed to insert code: %s", err)
+	}
+
+	for i := 1; i <= loginCodeMisses; i++ {
+		w := postVerifyLogin(s, codeID, "000000")
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d for miss %d, want the page re-rendered", w.Code, i)
+		}
+	}
+	w := postVerifyLogin(s, codeID, "123456")
+	if loc := w.Header().Get("Location"); loc == "/journal" {
+		t.Fatalf("the right code logged in after %d misses", loginCodeMisses)
+	}
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "session" {
+			t.Fatalf("got a session after %d misses", loginCodeMisses)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/husobee/vestigo"
@@ -11,42 +16,119 @@ import (
 )
 
 func logMiddleware(f http.HandlerFunc) http.HandlerFunc {
+	f = controllers.TracingMiddleware(controllers.MetricsMiddleware(f))
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		f(w, r)
-		logrus.Infof("%s: %dns", r.URL.String(), time.Now().Sub(start))
+		controllers.Logger(r.Context()).WithFields(logrus.Fields{
+			"method":   r.Method,
+			"path":     r.URL.Path,
+			"duration": time.Now().Sub(start),
+		}).Info("handled request")
 	}
 }
 
 func main() {
-	config := controllers.Configuration{
-		TwilioSecretsPath: "/etc/secrets/twilio.json",
-		DBSecretsPath:     "/etc/secrets/notify-db.json",
+	config, args, err := controllers.LoadConfiguration(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	} else if err != nil {
+		logrus.Fatalf("failed to load configuration: %s", err)
+	}
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := controllers.Migrate(config, args[1:], os.Stdout); err != nil {
+			logrus.Fatalf("failed to migrate: %+v", err)
+		}
+		return
+	}
+	ctx := context.Background()
+	stopTracing, err := controllers.InitTracing(ctx, config)
+	if err != nil {
+		logrus.Fatalf("failed to initialize tracing: %s", err)
 	}
 	c, err := controllers.NewNotifyAppServer(config)
 	if err != nil {
 		logrus.Panicf("failed to initialize notify service: %+v", err)
 	}
-	go c.NotifyLoop()
-	handler := pb.NewNotifyAppServer(c, nil)
+	c.Start(ctx)
+	c.RegisterMetrics()
+	handler := pb.NewNotifyAppServer(c, c.TwirpHooks())
 	router := vestigo.NewRouter()
 
+	//probes
+	router.Get("/healthz", c.GetHealthz)
+	router.Get("/readyz", c.GetReadyz)
+	router.Get("/version", c.GetVersion)
+
 	//frontend routes
+	router.Get("/static/*", c.StaticHandler())
 	router.Get("/login", c.GetLogin, logMiddleware)
 	router.Post("/login", c.PostLogin, logMiddleware)
+	router.Get("/login/verify", c.GetVerifyLogin, logMiddleware)
+	router.Post("/login/verify", c.PostVerifyLogin, logMiddleware)
 	router.Post("/twilio", c.TwilioInboundHandler, logMiddleware)
-	router.Get("/journal", c.GetJournal, logMiddleware, c.AuthMiddleware)
+	router.Get("/journal", c.GetJournalPage, logMiddleware, c.AuthMiddleware)
 	router.Post("/journal", c.PostJournal, logMiddleware, c.AuthMiddleware)
 	router.Put("/journal/:journal_id", c.PutJournal, logMiddleware, c.AuthMiddleware)
-	router.Delete("/journal/:journal_id", c.DeleteJournal, logMiddleware, c.AuthMiddleware)
+	router.Delete("/journal/:journal_id", c.DeleteJournalPage, logMiddleware, c.AuthMiddleware)
+	router.Post("/journal/:journal_id/tags", c.PostJournalTags, logMiddleware, c.AuthMiddleware)
+	router.Get("/journal/:journal_id/history", c.GetJournalHistory, logMiddleware, c.AuthMiddleware)
+	router.Post("/journal/:journal_id/revisions/:revision_id/restore", c.PostRestoreRevision, logMiddleware, c.AuthMiddleware)
+	router.Get("/trash", c.GetTrash, logMiddleware, c.AuthMiddleware)
+	router.Post("/trash/journal/:journal_id/restore", c.PostRestoreJournal, logMiddleware, c.AuthMiddleware)
+	router.Post("/trash/user-notification/:notification_id/restore", c.PostRestoreUserNotification, logMiddleware, c.AuthMiddleware)
+	router.Get("/attachment/:attachment_id", c.GetAttachment, logMiddleware, c.AuthMiddleware)
+	router.Get("/attachment/:attachment_id/thumb", c.GetAttachmentThumbnail, logMiddleware, c.AuthMiddleware)
+	router.Get("/export", c.GetExport, logMiddleware, c.AuthMiddleware)
+	router.Post("/import", c.PostImport, logMiddleware, c.ImportBodyMiddleware, c.AuthMiddleware)
 	router.Get("/configure", c.GetConfigure, logMiddleware, c.AuthMiddleware)
 	router.Post("/user-notification", c.PostUserNotification, logMiddleware, c.AuthMiddleware)
-	router.Post("/user-notification/:notification_id/delete", c.DeleteUserNotification, logMiddleware, c.AuthMiddleware)
+	router.Post("/user-notification/:notification_id/delete", c.DeleteUserNotificationPage, logMiddleware, c.AuthMiddleware)
+	router.Get("/account", c.GetAccount, logMiddleware, c.AuthMiddleware)
+	router.Post("/account/two-factor", c.PostTwoFactor, logMiddleware, c.AuthMiddleware)
+	router.Post("/api-token", c.PostApiToken, logMiddleware, c.AuthMiddleware)
+	router.Post("/api-token/:token_id/delete", c.DeleteApiToken, logMiddleware, c.AuthMiddleware)
 	router.Get("/logout", c.Logout, logMiddleware, c.AuthMiddleware)
 
 	//twirp setup
-	router.HandleFunc(pb.NotifyAppPathPrefix+"*", handler.ServeHTTP, logMiddleware)
+	router.HandleFunc(pb.NotifyAppPathPrefix+"*", handler.ServeHTTP, logMiddleware, c.ApiTokenMiddleware)
+	router.Get("/api/export", c.GetApiExport, logMiddleware, c.ApiTokenMiddleware)
+
+	servers := []*http.Server{{Addr: config.Addr, Handler: controllers.RequestIDMiddleware(router.ServeHTTP)}}
+
+	//operator endpoints, kept off the public listener
+	if config.MetricsAddr != "" {
+		metrics := vestigo.NewRouter()
+		metrics.Get("/status/scheduler", c.GetSchedulerStatus, logMiddleware)
+		metrics.Get("/metrics", controllers.MetricsHandler().ServeHTTP)
+		servers = append(servers, &http.Server{Addr: config.MetricsAddr, Handler: controllers.RequestIDMiddleware(metrics.ServeHTTP)})
+	}
 
-	logrus.Infof("starting server...")
-	logrus.Fatal(http.ListenAndServe("0.0.0.0:8080", router))
+	for _, server := range servers {
+		go func(server *http.Server) {
+			logrus.Infof("starting server on %s...", server.Addr)
+			if err := server.ListenAndServe(); err != http.ErrServerClosed {
+				logrus.Fatal(err)
+			}
+		}(server)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	logrus.Infof("received %s, shutting down...", <-signals)
+
+	ctx, cancel := context.WithTimeout(ctx, config.ShutdownTimeout)
+	defer cancel()
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			logrus.Errorf("failed to shut down server on %s: %s", server.Addr, err)
+		}
+	}
+	if err := c.Shutdown(ctx); err != nil {
+		logrus.Errorf("failed to shut down notify service: %s", err)
+	}
+	if err := stopTracing(ctx); err != nil {
+		logrus.Errorf("failed to flush traces: %s", err)
+	}
+	logrus.Infof("shut down")
 }
@@ -0,0 +1,676 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// memoryRepository keeps everything in maps, for tests and trying the app out.
+// stored values are never modified in place, writes replace them with a
+// changed copy, so a transaction can work on a shallow copy of the maps and
+// tell what it changed by comparing pointers.
+type memoryRepository struct {
+	mu sync.Mutex
+	memoryState
+	// parent is set on a transaction, which commits what changed from base
+	// into it
+	parent *memoryRepository
+	base   memoryState
+}
+
+type memoryState struct {
+	users             map[string]*memoryUser
+	apiTokens         map[string]*memoryApiToken
+	loginAttempts     map[string]*memoryLoginAttempt
+	loginCodes        map[string]*memoryLoginCode
+	userKeys          map[string]*memoryUserKey
+	notifications     map[string]*memoryNotification
+	userNotifications map[string]*pb.UserNotification
+	communications    map[string]*pb.Communication
+	journals          map[string]*memoryJournal
+	journalImports    map[string]*pb.ImportReport
+	revisions         map[string]*pb.JournalRevision
+	tags              map[string]*memoryTag
+	journalTags       map[string]*memoryJournalTag
+	journalTerms      map[string]*memoryJournalTerms
+	attachments       map[string]*memoryAttachment
+}
+
+type memoryUser struct {
+	pb.User
+	created, updated string
+}
+
+type memoryApiToken struct {
+	pb.ApiToken
+	hash string
+}
+
+type memoryLoginAttempt struct {
+	kind, phoneNumber, ip string
+	success               bool
+	created               string
+}
+
+type memoryLoginCode struct {
+	LoginCode
+	used     bool
+	attempts int
+	expires  string
+}
+
+type memoryUserKey struct {
+	UserKey
+	created, retired string
+}
+
+type memoryNotification struct {
+	pb.Notification
+	created string
+}
+
+type memoryJournal struct {
+	pb.Journal
+	contentHash string
+}
+
+type memoryTag struct {
+	tagID, phoneNumber, name string
+}
+
+type memoryJournalTag struct {
+	journalID, tagID, source string
+}
+
+// memoryJournalTerms are a journal's search terms, keyed by journal id.
+type memoryJournalTerms struct {
+	phoneNumber string
+	terms       map[string]int
+}
+
+type memoryAttachment struct {
+	pb.Attachment
+	phoneNumber string
+}
+
+// NewMemory returns an empty in memory store with the seed notifications.
+func NewMemory() Repository {
+	m := &memoryRepository{memoryState: memoryState{
+		users:             map[string]*memoryUser{},
+		apiTokens:         map[string]*memoryApiToken{},
+		loginAttempts:     map[string]*memoryLoginAttempt{},
+		loginCodes:        map[string]*memoryLoginCode{},
+		userKeys:          map[string]*memoryUserKey{},
+		notifications:     map[string]*memoryNotification{},
+		userNotifications: map[string]*pb.UserNotification{},
+		communications:    map[string]*pb.Communication{},
+		journals:          map[string]*memoryJournal{},
+		journalImports:    map[string]*pb.ImportReport{},
+		revisions:         map[string]*pb.JournalRevision{},
+		tags:              map[string]*memoryTag{},
+		journalTags:       map[string]*memoryJournalTag{},
+		journalTerms:      map[string]*memoryJournalTerms{},
+		attachments:       map[string]*memoryAttachment{},
+	}}
+	now := m.now()
+	for _, n := range seedNotifications {
+		m.notifications[n.NotificationId] = &memoryNotification{Notification: *n, created: now}
+	}
+	return m
+}
+
+// copy returns new maps of the same rows for a transaction to change, a
+// table added to memoryState needs adding here and to apply.
+func (s memoryState) copy() memoryState {
+	return memoryState{
+		users:             copyMap(s.users),
+		apiTokens:         copyMap(s.apiTokens),
+		loginAttempts:     copyMap(s.loginAttempts),
+		loginCodes:        copyMap(s.loginCodes),
+		userKeys:          copyMap(s.userKeys),
+		notifications:     copyMap(s.notifications),
+		userNotifications: copyMap(s.userNotifications),
+		communications:    copyMap(s.communications),
+		journals:          copyMap(s.journals),
+		journalImports:    copyMap(s.journalImports),
+		revisions:         copyMap(s.revisions),
+		tags:              copyMap(s.tags),
+		journalTags:       copyMap(s.journalTags),
+		journalTerms:      copyMap(s.journalTerms),
+		attachments:       copyMap(s.attachments),
+	}
+}
+
+// apply makes the changes a transaction made to its copy of base.
+func (s *memoryState) apply(base, changed memoryState) {
+	applyMap(s.users, base.users, changed.users)
+	applyMap(s.apiTokens, base.apiTokens, changed.apiTokens)
+	applyMap(s.loginAttempts, base.loginAttempts, changed.loginAttempts)
+	applyMap(s.loginCodes, base.loginCodes, changed.loginCodes)
+	applyMap(s.userKeys, base.userKeys, changed.userKeys)
+	applyMap(s.notifications, base.notifications, changed.notifications)
+	applyMap(s.userNotifications, base.userNotifications, changed.userNotifications)
+	applyMap(s.communications, base.communications, changed.communications)
+	applyMap(s.journals, base.journals, changed.journals)
+	applyMap(s.journalImports, base.journalImports, changed.journalImports)
+	applyMap(s.revisions, base.revisions, changed.revisions)
+	applyMap(s.tags, base.tags, changed.tags)
+	applyMap(s.journalTags, base.journalTags, changed.journalTags)
+	applyMap(s.journalTerms, base.journalTerms, changed.journalTerms)
+	applyMap(s.attachments, base.attachments, changed.attachments)
+}
+
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// applyMap writes to dst the entries changed added or replaced since base,
+// and deletes the ones it removed.  values are pointers, so a changed entry
+// is one that points somewhere else.
+func applyMap[K comparable, V comparable](dst, base, changed map[K]V) {
+	for k, v := range changed {
+		if base[k] != v {
+			dst[k] = v
+		}
+	}
+	for k := range base {
+		if _, ok := changed[k]; !ok {
+			delete(dst, k)
+		}
+	}
+}
+
+func (m *memoryRepository) now() string {
+	return formatTime(time.Now().UTC())
+}
+
+func (m *memoryRepository) Now(ctx context.Context) (time.Time, error) {
+	return time.Now().UTC(), nil
+}
+
+// InTx runs fn on its own copy of the maps, which is dropped if fn fails and
+// otherwise has its changes applied to m.  fn's writes aren't seen outside it
+// until then, and a nested InTx is part of the outer one.  the last write to
+// a row wins, there's no conflict detection.
+func (m *memoryRepository) InTx(ctx context.Context, fn func(Repository) error) error {
+	if m.parent != nil {
+		return fn(m)
+	}
+	m.mu.Lock()
+	base := m.memoryState.copy()
+	m.mu.Unlock()
+
+	tx := &memoryRepository{memoryState: base.copy(), parent: m, base: base}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.memoryState.apply(tx.base, tx.memoryState)
+	return nil
+}
+
+func (m *memoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *memoryRepository) Stats() sql.DBStats {
+	return sql.DBStats{}
+}
+
+func (m *memoryRepository) Close() error {
+	return nil
+}
+
+// the memory store starts out with its schema, there's nothing to migrate.
+func (m *memoryRepository) MigrateUp(ctx context.Context) ([]*Migration, error) {
+	return nil, nil
+}
+
+func (m *memoryRepository) MigrateDown(ctx context.Context, steps int) ([]*Migration, error) {
+	return nil, nil
+}
+
+func (m *memoryRepository) MigrationStatus(ctx context.Context) ([]*MigrationStatus, error) {
+	return []*MigrationStatus{}, nil
+}
+
+func (m *memoryRepository) Baseline(ctx context.Context, version int) error {
+	return nil
+}
+
+// paginate sorts rows newest first, drops those outside the page and returns
+// the page and the next cursor.
+func paginate(page *Page, n int, createdID func(i int) (string, string)) ([]int, string) {
+	after, before := normalizeTime(page.CreatedAfter), normalizeTime(page.CreatedBefore)
+	cursorCreated, cursorID, _ := DecodeCursor(page.Cursor)
+	idx := []int{}
+	for i := 0; i < n; i++ {
+		created, id := createdID(i)
+		if page.CreatedAfter != "" && created < after {
+			continue
+		}
+		if page.CreatedBefore != "" && created >= before {
+			continue
+		}
+		if page.Cursor != "" && !(created < cursorCreated || (created == cursorCreated && id < cursorID)) {
+			continue
+		}
+		idx = append(idx, i)
+	}
+	sort.Slice(idx, func(a, b int) bool {
+		ca, ia := createdID(idx[a])
+		cb, ib := createdID(idx[b])
+		if ca != cb {
+			return ca > cb
+		}
+		return ia > ib
+	})
+	nextCursor := ""
+	if len(idx) > page.Limit {
+		idx = idx[:page.Limit]
+		created, id := createdID(idx[len(idx)-1])
+		nextCursor = EncodeCursor(created, id)
+	}
+	return idx, nextCursor
+}
+
+func (m *memoryRepository) InsertUser(ctx context.Context, user *pb.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[user.PhoneNumber]; ok {
+		return fmt.Errorf("duplicate user '%s'", user.PhoneNumber)
+	}
+	now := m.now()
+	u := &memoryUser{User: pb.User{
+		PhoneNumber: user.PhoneNumber,
+		Password:    user.Password,
+		Name:        user.Name,
+		Birthday:    user.Birthday,
+		Role:        "user",
+	}, created: now, updated: now}
+	m.users[user.PhoneNumber] = u
+	return nil
+}
+
+func (m *memoryRepository) GetUser(ctx context.Context, phoneNumber string) (*pb.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[phoneNumber]
+	if !ok {
+		return nil, errors.Wrapf(ErrNotFound, "number '%s'", phoneNumber)
+	}
+	user := u.User
+	return &user, nil
+}
+
+func (m *memoryRepository) UpdateUser(ctx context.Context, user *pb.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[user.PhoneNumber]
+	if !ok {
+		return nil
+	}
+	c := *u
+	c.Verified, c.SessionId, c.CsrfToken, c.TwoFactor = user.Verified, user.SessionId, user.CsrfToken, user.TwoFactor
+	c.updated = m.now()
+	m.users[user.PhoneNumber] = &c
+	return nil
+}
+
+func (m *memoryRepository) InsertApiToken(ctx context.Context, t *pb.ApiToken, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.apiTokens {
+		if existing.hash == tokenHash {
+			return fmt.Errorf("duplicate token hash")
+		}
+	}
+	t.TokenId = uuid.NewV4().String()
+	t.Created = m.now()
+	m.apiTokens[t.TokenId] = &memoryApiToken{ApiToken: pb.ApiToken{
+		TokenId:     t.TokenId,
+		PhoneNumber: t.PhoneNumber,
+		Name:        t.Name,
+		Created:     t.Created,
+	}, hash: tokenHash}
+	return nil
+}
+
+func (m *memoryRepository) ListApiTokens(ctx context.Context, phoneNumber string) ([]*pb.ApiToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tokens := []*pb.ApiToken{}
+	for _, t := range m.apiTokens {
+		if t.PhoneNumber == phoneNumber {
+			c := t.ApiToken
+			tokens = append(tokens, &c)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Created > tokens[j].Created })
+	return tokens, nil
+}
+
+func (m *memoryRepository) GetApiTokenUser(ctx context.Context, tokenHash string) (*pb.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, t := range m.apiTokens {
+		if t.hash != tokenHash {
+			continue
+		}
+		u, ok := m.users[t.PhoneNumber]
+		if !ok {
+			break
+		}
+		c := *t
+		c.LastUsed = m.now()
+		m.apiTokens[id] = &c
+		return &pb.User{PhoneNumber: u.PhoneNumber, Name: u.Name, Birthday: u.Birthday, Verified: u.Verified, Role: u.Role}, nil
+	}
+	return nil, errors.Wrap(ErrNotFound, "api token")
+}
+
+func (m *memoryRepository) DeleteApiToken(ctx context.Context, phoneNumber, tokenID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.apiTokens[tokenID]; ok && t.PhoneNumber == phoneNumber {
+		delete(m.apiTokens, tokenID)
+	}
+	return nil
+}
+
+func (m *memoryRepository) InsertLoginAttempt(ctx context.Context, kind, phoneNumber, ip string, success bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loginAttempts[uuid.NewV4().String()] = &memoryLoginAttempt{
+		kind: kind, phoneNumber: phoneNumber, ip: ip, success: success, created: m.now(),
+	}
+	return nil
+}
+
+func (m *memoryRepository) CountFailedLoginAttempts(ctx context.Context, kind, column, value string, since time.Time) (int, time.Time, error) {
+	if column != "phone_number" && column != "ip" {
+		return 0, time.Time{}, fmt.Errorf("column '%s' is unhandled", column)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.countFailedLoginAttempts(kind, column, value, since)
+}
+
+// countFailedLoginAttempts needs m.mu held.
+func (m *memoryRepository) countFailedLoginAttempts(kind, column, value string, since time.Time) (int, time.Time, error) {
+	matches := func(a *memoryLoginAttempt) bool {
+		if column == "ip" {
+			return a.kind == kind && a.ip == value
+		}
+		return a.kind == kind && a.phoneNumber == value
+	}
+	lastSuccess := ""
+	for _, a := range m.loginAttempts {
+		if column == "phone_number" && matches(a) && a.success && a.created > lastSuccess {
+			lastSuccess = a.created
+		}
+	}
+	n, last := 0, ""
+	for _, a := range m.loginAttempts {
+		if matches(a) && !a.success && a.created > formatTime(since) && a.created > lastSuccess {
+			n++
+			if a.created > last {
+				last = a.created
+			}
+		}
+	}
+	if n == 0 {
+		return 0, time.Time{}, nil
+	}
+	t, err := time.Parse(TimeFormat, last)
+	return n, t, errors.Wrap(err, "failed to parse last attempt")
+}
+
+func (m *memoryRepository) ClaimLoginAttempt(ctx context.Context, kind, phoneNumber, ip string, since time.Time, check func(column string, n int, last time.Time) error) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range []struct{ column, value string }{{"phone_number", phoneNumber}, {"ip", ip}} {
+		if c.value == "" {
+			continue
+		}
+		n, last, err := m.countFailedLoginAttempts(kind, c.column, c.value, since)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to count by %s", c.column)
+		}
+		if err := check(c.column, n, last); err != nil {
+			return "", err
+		}
+	}
+	attemptID := uuid.NewV4().String()
+	m.loginAttempts[attemptID] = &memoryLoginAttempt{
+		kind: kind, phoneNumber: phoneNumber, ip: ip, created: m.now(),
+	}
+	return attemptID, nil
+}
+
+func (m *memoryRepository) SucceedLoginAttempt(ctx context.Context, attemptID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.loginAttempts[attemptID]
+	if !ok {
+		return errors.Wrapf(ErrNotFound, "login attempt '%s'", attemptID)
+	}
+	succeeded := *a
+	succeeded.success = true
+	m.loginAttempts[attemptID] = &succeeded
+	return nil
+}
+
+func (m *memoryRepository) InsertLoginCode(ctx context.Context, phoneNumber, codeHash string, lifetime time.Duration) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	codeID := uuid.NewV4().String()
+	m.loginCodes[codeID] = &memoryLoginCode{
+		LoginCode: LoginCode{CodeID: codeID, PhoneNumber: phoneNumber, CodeHash: codeHash},
+		expires:   formatTime(time.Now().UTC().Add(lifetime)),
+	}
+	return codeID, nil
+}
+
+func (m *memoryRepository) GetLoginCode(ctx context.Context, codeID string) (*LoginCode, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.loginCodes[codeID]
+	if !ok || c.used || c.expires <= m.now() {
+		return nil, errors.Wrapf(ErrNotFound, "login code '%s'", codeID)
+	}
+	code := c.LoginCode
+	return &code, nil
+}
+
+func (m *memoryRepository) UseLoginCode(ctx context.Context, codeID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.loginCodes[codeID]
+	if !ok || c.used {
+		return errors.Wrapf(ErrNotFound, "unused login code '%s'", codeID)
+	}
+	used := *c
+	used.used = true
+	m.loginCodes[codeID] = &used
+	return nil
+}
+
+func (m *memoryRepository) MissLoginCode(ctx context.Context, codeID string, maxMisses int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.loginCodes[codeID]
+	if !ok || c.used {
+		return false, errors.Wrapf(ErrNotFound, "unused login code '%s'", codeID)
+	}
+	missed := *c
+	missed.attempts++
+	missed.used = missed.attempts >= maxMisses
+	m.loginCodes[codeID] = &missed
+	return missed.used, nil
+}
+
+func (m *memoryRepository) InsertUserKey(ctx context.Context, k *UserKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.userKeys[k.KeyID] = &memoryUserKey{UserKey: *k, created: m.now()}
+	return nil
+}
+
+func (m *memoryRepository) GetUserKey(ctx context.Context, phoneNumber, keyID string) (*UserKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k, ok := m.userKeys[keyID]
+	if !ok || k.PhoneNumber != phoneNumber {
+		return nil, errors.Wrapf(ErrNotFound, "data key '%s'", keyID)
+	}
+	c := k.UserKey
+	return &c, nil
+}
+
+func (m *memoryRepository) GetActiveUserKeyID(ctx context.Context, phoneNumber string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var active *memoryUserKey
+	for _, k := range m.userKeys {
+		if k.PhoneNumber == phoneNumber && k.retired == "" && (active == nil || k.created > active.created) {
+			active = k
+		}
+	}
+	if active == nil {
+		return "", nil
+	}
+	return active.KeyID, nil
+}
+
+func (m *memoryRepository) ListUserKeysWrappedByOthers(ctx context.Context, masterKeyID string) ([]*UserKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := []*UserKey{}
+	for _, k := range m.userKeys {
+		if k.MasterKeyID != masterKeyID {
+			c := k.UserKey
+			keys = append(keys, &c)
+		}
+	}
+	return keys, nil
+}
+
+func (m *memoryRepository) UpdateUserKeyWrap(ctx context.Context, k *UserKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.userKeys[k.KeyID]; ok {
+		c := *existing
+		c.MasterKeyID, c.WrappedKey = k.MasterKeyID, k.WrappedKey
+		m.userKeys[k.KeyID] = &c
+	}
+	return nil
+}
+
+func (m *memoryRepository) RetireUserKeys(ctx context.Context, phoneNumber string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	for id, k := range m.userKeys {
+		if k.PhoneNumber == phoneNumber && k.retired == "" {
+			c := *k
+			c.retired = now
+			m.userKeys[id] = &c
+		}
+	}
+	return nil
+}
+
+func (m *memoryRepository) ListUnsealedRows(ctx context.Context, prefix, phoneNumber, inboundTo string, limit int) ([]*SealedRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pending := func(values ...string) bool {
+		for _, v := range values {
+			if v != "" && !strings.HasPrefix(v, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	rows := []*SealedRow{}
+	for _, j := range m.journals {
+		if (phoneNumber == "" || j.PhoneNumber == phoneNumber) && pending(j.Title, j.Entry) {
+			rows = append(rows, &SealedRow{Table: "journals", ID: j.JournalId, PhoneNumber: j.PhoneNumber, Values: []string{j.Title, j.Entry},
+				SealedTable: "journals", SealedID: j.JournalId, Columns: []string{"title", "entry"}, Hashed: true})
+		}
+	}
+	for _, r := range m.revisions {
+		if (phoneNumber == "" || r.PhoneNumber == phoneNumber) && pending(r.Entry) {
+			rows = append(rows, &SealedRow{Table: "journal_revisions", ID: r.RevisionId, PhoneNumber: r.PhoneNumber, Values: []string{r.Entry},
+				SealedTable: "journals", SealedID: r.JournalId, Columns: []string{"entry"}})
+		}
+	}
+	for _, c := range m.communications {
+		if (phoneNumber == "" || c.From == phoneNumber) && c.To == inboundTo && pending(c.Message) {
+			rows = append(rows, &SealedRow{Table: "communications", ID: c.CommsId, PhoneNumber: c.From, Values: []string{c.Message},
+				SealedTable: "communications", SealedID: c.CommsId, Columns: []string{"message"}})
+		}
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+func (m *memoryRepository) UpdateSealedRow(ctx context.Context, row *SealedRow, sealed []string, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	unchanged := func(values ...string) bool {
+		if len(values) != len(row.Values) || len(sealed) != len(row.Values) {
+			return false
+		}
+		for i, v := range values {
+			if v != row.Values[i] {
+				return false
+			}
+		}
+		return true
+	}
+	switch row.Table {
+	case "journals":
+		if j, ok := m.journals[row.ID]; ok && unchanged(j.Title, j.Entry) {
+			c := *j
+			c.Title, c.Entry, c.contentHash = sealed[0], sealed[1], hash
+			m.journals[row.ID] = &c
+			return nil
+		}
+	case "journal_revisions":
+		if r, ok := m.revisions[row.ID]; ok && unchanged(r.Entry) {
+			c := *r
+			c.Entry = sealed[0]
+			m.revisions[row.ID] = &c
+			return nil
+		}
+	case "communications":
+		if comm, ok := m.communications[row.ID]; ok && unchanged(comm.Message) {
+			c := *comm
+			c.Message = sealed[0]
+			m.communications[row.ID] = &c
+			return nil
+		}
+	default:
+		return fmt.Errorf("table '%s' has no sealed columns", row.Table)
+	}
+	return errors.Wrapf(ErrNotFound, "unchanged %s '%s'", row.Table, row.ID)
+}
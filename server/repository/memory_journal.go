@@ -0,0 +1,426 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+func (m *memoryRepository) InsertJournal(ctx context.Context, j *pb.Journal, contentHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if j.JournalId == "" {
+		j.JournalId = uuid.NewV4().String()
+	}
+	now := m.now()
+	created := now
+	if j.Created != "" {
+		created = normalizeTime(j.Created)
+	}
+	m.journals[j.JournalId] = &memoryJournal{Journal: pb.Journal{
+		JournalId:   j.JournalId,
+		CommsId:     j.CommsId,
+		PhoneNumber: j.PhoneNumber,
+		Title:       j.Title,
+		Entry:       j.Entry,
+		Created:     created,
+		Updated:     now,
+	}, contentHash: contentHash}
+	return nil
+}
+
+// journal returns a copy of a stored journal with its tags and attachments.
+func (m *memoryRepository) journal(stored *memoryJournal) *pb.Journal {
+	j := stored.Journal
+	j.Tags, j.Attachments = nil, nil
+	names := map[string]bool{}
+	for _, jt := range m.journalTags {
+		if t, ok := m.tags[jt.tagID]; ok && jt.journalID == j.JournalId && !names[t.name] {
+			names[t.name] = true
+			j.Tags = append(j.Tags, t.name)
+		}
+	}
+	sort.Strings(j.Tags)
+	for _, a := range m.attachments {
+		if a.JournalId == j.JournalId {
+			c := a.Attachment
+			j.Attachments = append(j.Attachments, &c)
+		}
+	}
+	sort.Slice(j.Attachments, func(a, b int) bool { return j.Attachments[a].Created < j.Attachments[b].Created })
+	return &j
+}
+
+func (m *memoryRepository) GetJournal(ctx context.Context, phoneNumber, journalID string) (*pb.Journal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.journals[journalID]
+	if !ok || stored.PhoneNumber != phoneNumber || stored.Deleted != "" {
+		return nil, errors.Wrapf(ErrNotFound, "journal '%s'", journalID)
+	}
+	return m.journal(stored), nil
+}
+
+// LockJournal is GetJournal, the memory store's transactions don't lock.
+func (m *memoryRepository) LockJournal(ctx context.Context, phoneNumber, journalID string) (*pb.Journal, error) {
+	return m.GetJournal(ctx, phoneNumber, journalID)
+}
+
+// tagged reports whether a journal has a user's tag.
+func (m *memoryRepository) tagged(journalID, phoneNumber, tag string) bool {
+	for _, jt := range m.journalTags {
+		if t, ok := m.tags[jt.tagID]; ok && jt.journalID == journalID && t.phoneNumber == phoneNumber && t.name == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTerms reports whether a journal has every one of terms.
+func (m *memoryRepository) hasTerms(journalID string, terms []string) bool {
+	for _, t := range terms {
+		jt, ok := m.journalTerms[journalID]
+		if !ok || jt.terms[t] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *memoryRepository) ListJournals(ctx context.Context, phoneNumber string, page *Page, tag string, terms []string) ([]*pb.Journal, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	matches := []*memoryJournal{}
+	for _, j := range m.journals {
+		if j.PhoneNumber == phoneNumber && j.Deleted == "" && (tag == "" || m.tagged(j.JournalId, phoneNumber, tag)) && m.hasTerms(j.JournalId, terms) {
+			matches = append(matches, j)
+		}
+	}
+	idx, nextCursor := paginate(page, len(matches), func(i int) (string, string) {
+		return matches[i].Created, matches[i].JournalId
+	})
+	journals := []*pb.Journal{}
+	for _, i := range idx {
+		journals = append(journals, m.journal(matches[i]))
+	}
+	return journals, nextCursor, nil
+}
+
+func (m *memoryRepository) SetJournalTerms(ctx context.Context, phoneNumber, journalID string, terms map[string]int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := map[string]int{}
+	for t, n := range terms {
+		c[t] = n
+	}
+	m.journalTerms[journalID] = &memoryJournalTerms{phoneNumber: phoneNumber, terms: c}
+	return nil
+}
+
+func (m *memoryRepository) SearchJournals(ctx context.Context, phoneNumber string, terms []string, limit int) ([]*pb.Journal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	type match struct {
+		j           *memoryJournal
+		occurrences int
+	}
+	matches := []match{}
+	for id, jt := range m.journalTerms {
+		j, ok := m.journals[id]
+		if !ok || jt.phoneNumber != phoneNumber || j.Deleted != "" {
+			continue
+		}
+		n := 0
+		for _, t := range distinct(terms) {
+			n += jt.terms[t]
+		}
+		if n > 0 {
+			matches = append(matches, match{j, n})
+		}
+	}
+	sort.Slice(matches, func(a, b int) bool { return matches[a].occurrences > matches[b].occurrences })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	journals := []*pb.Journal{}
+	for _, match := range matches {
+		journals = append(journals, m.journal(match.j))
+	}
+	return journals, nil
+}
+
+func (m *memoryRepository) CountJournals(ctx context.Context, phoneNumber string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, j := range m.journals {
+		if j.PhoneNumber == phoneNumber && j.Deleted == "" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryRepository) UpdateJournalEntry(ctx context.Context, j *pb.Journal, contentHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored, ok := m.journals[j.JournalId]; ok && stored.PhoneNumber == j.PhoneNumber {
+		c := *stored
+		c.Entry, c.contentHash, c.Updated = j.Entry, contentHash, m.now()
+		m.journals[j.JournalId] = &c
+	}
+	return nil
+}
+
+func (m *memoryRepository) DeleteJournal(ctx context.Context, phoneNumber, journalID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored, ok := m.journals[journalID]; ok && stored.PhoneNumber == phoneNumber && stored.Deleted == "" {
+		c := *stored
+		c.Deleted = m.now()
+		m.journals[journalID] = &c
+	}
+	return nil
+}
+
+func (m *memoryRepository) RestoreJournal(ctx context.Context, phoneNumber, journalID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.journals[journalID]
+	if !ok || stored.PhoneNumber != phoneNumber || stored.Deleted == "" {
+		return errors.Wrapf(ErrNotFound, "trashed journal '%s'", journalID)
+	}
+	c := *stored
+	c.Deleted = ""
+	m.journals[journalID] = &c
+	return nil
+}
+
+func (m *memoryRepository) ListTrashedJournals(ctx context.Context, phoneNumber string) ([]*pb.Journal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	journals := []*pb.Journal{}
+	for _, stored := range m.journals {
+		if stored.PhoneNumber == phoneNumber && stored.Deleted != "" {
+			j := stored.Journal
+			journals = append(journals, &j)
+		}
+	}
+	sort.Slice(journals, func(i, j int) bool { return journals[i].Deleted > journals[j].Deleted })
+	return journals, nil
+}
+
+func (m *memoryRepository) JournalExists(ctx context.Context, phoneNumber, contentHash, created string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	day := normalizeTime(created)[:len("2006-01-02")]
+	for _, j := range m.journals {
+		if j.PhoneNumber == phoneNumber && j.contentHash == contentHash && j.Deleted == "" && j.Created[:len(day)] == day {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryRepository) InsertJournalImport(ctx context.Context, phoneNumber, format string, report *pb.ImportReport) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.journalImports[report.ImportId] = &pb.ImportReport{
+		ImportId:   report.ImportId,
+		Imported:   report.Imported,
+		Duplicates: report.Duplicates,
+		Invalid:    report.Invalid,
+	}
+	return nil
+}
+
+func (m *memoryRepository) InsertJournalRevision(ctx context.Context, phoneNumber, journalID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.journals[journalID]
+	if !ok || j.PhoneNumber != phoneNumber {
+		return nil
+	}
+	r := &pb.JournalRevision{
+		RevisionId:  uuid.NewV4().String(),
+		JournalId:   journalID,
+		PhoneNumber: phoneNumber,
+		Entry:       j.Entry,
+		Created:     m.now(),
+	}
+	m.revisions[r.RevisionId] = r
+	return nil
+}
+
+func (m *memoryRepository) ListJournalRevisions(ctx context.Context, phoneNumber, journalID string) ([]*pb.JournalRevision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	revisions := []*pb.JournalRevision{}
+	for _, r := range m.revisions {
+		if r.JournalId == journalID && r.PhoneNumber == phoneNumber {
+			c := *r
+			revisions = append(revisions, &c)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		if revisions[i].Created != revisions[j].Created {
+			return revisions[i].Created > revisions[j].Created
+		}
+		return revisions[i].RevisionId > revisions[j].RevisionId
+	})
+	return revisions, nil
+}
+
+func (m *memoryRepository) GetJournalRevision(ctx context.Context, phoneNumber, revisionID string) (*pb.JournalRevision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.revisions[revisionID]
+	if !ok || r.PhoneNumber != phoneNumber {
+		return nil, errors.Wrapf(ErrNotFound, "revision '%s'", revisionID)
+	}
+	c := *r
+	return &c, nil
+}
+
+func journalTagKey(journalID, tagID, source string) string {
+	return journalID + "|" + tagID + "|" + source
+}
+
+func (m *memoryRepository) AddJournalTags(ctx context.Context, phoneNumber, journalID string, tags []string, source string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, name := range tags {
+		var tag *memoryTag
+		for _, t := range m.tags {
+			if t.phoneNumber == phoneNumber && t.name == name {
+				tag = t
+				break
+			}
+		}
+		if tag == nil {
+			tag = &memoryTag{tagID: uuid.NewV4().String(), phoneNumber: phoneNumber, name: name}
+			m.tags[tag.tagID] = tag
+		}
+		m.journalTags[journalTagKey(journalID, tag.tagID, source)] = &memoryJournalTag{journalID: journalID, tagID: tag.tagID, source: source}
+	}
+	return nil
+}
+
+func (m *memoryRepository) DeleteJournalTags(ctx context.Context, journalID, source string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, jt := range m.journalTags {
+		if jt.journalID == journalID && jt.source == source {
+			delete(m.journalTags, key)
+		}
+	}
+	return nil
+}
+
+func (m *memoryRepository) ListTagCounts(ctx context.Context, phoneNumber string) ([]*pb.TagCount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	journalsByTag := map[string]map[string]bool{}
+	for _, jt := range m.journalTags {
+		t, ok := m.tags[jt.tagID]
+		if !ok || t.phoneNumber != phoneNumber {
+			continue
+		}
+		if j, ok := m.journals[jt.journalID]; !ok || j.Deleted != "" {
+			continue
+		}
+		if journalsByTag[t.name] == nil {
+			journalsByTag[t.name] = map[string]bool{}
+		}
+		journalsByTag[t.name][jt.journalID] = true
+	}
+	counts := []*pb.TagCount{}
+	for name, journals := range journalsByTag {
+		counts = append(counts, &pb.TagCount{Name: name, Count: int32(len(journals))})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Name < counts[j].Name })
+	return counts, nil
+}
+
+func (m *memoryRepository) InsertAttachment(ctx context.Context, phoneNumber string, a *pb.Attachment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a.Created = m.now()
+	m.attachments[a.AttachmentId] = &memoryAttachment{Attachment: *a, phoneNumber: phoneNumber}
+	return nil
+}
+
+func (m *memoryRepository) GetAttachment(ctx context.Context, phoneNumber, attachmentID string) (*pb.Attachment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.attachments[attachmentID]
+	if !ok || a.phoneNumber != phoneNumber {
+		return nil, errors.Wrapf(ErrNotFound, "attachment '%s'", attachmentID)
+	}
+	if j, ok := m.journals[a.JournalId]; !ok || j.Deleted != "" {
+		return nil, errors.Wrapf(ErrNotFound, "attachment '%s'", attachmentID)
+	}
+	c := a.Attachment
+	return &c, nil
+}
+
+// purgeable reports whether a journal has been in the trash since before
+// cutoff.
+func (m *memoryRepository) purgeable(journalID, cutoff string) bool {
+	j, ok := m.journals[journalID]
+	return ok && j.Deleted != "" && j.Deleted < cutoff
+}
+
+func (m *memoryRepository) ListPurgeableAttachments(ctx context.Context, before time.Time) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := formatTime(before)
+	ids := []string{}
+	for id, a := range m.attachments {
+		if m.purgeable(a.JournalId, cutoff) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *memoryRepository) PurgeTrash(ctx context.Context, before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := formatTime(before)
+	for id, a := range m.attachments {
+		if m.purgeable(a.JournalId, cutoff) {
+			delete(m.attachments, id)
+		}
+	}
+	for key, jt := range m.journalTags {
+		if m.purgeable(jt.journalID, cutoff) {
+			delete(m.journalTags, key)
+		}
+	}
+	for id, r := range m.revisions {
+		if m.purgeable(r.JournalId, cutoff) {
+			delete(m.revisions, id)
+		}
+	}
+	for id := range m.journalTerms {
+		if m.purgeable(id, cutoff) {
+			delete(m.journalTerms, id)
+		}
+	}
+	for id := range m.journals {
+		if m.purgeable(id, cutoff) {
+			delete(m.journals, id)
+		}
+	}
+	for key, up := range m.userNotifications {
+		if up.Deleted != "" && up.Deleted < cutoff {
+			delete(m.userNotifications, key)
+		}
+	}
+	return nil
+}
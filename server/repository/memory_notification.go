@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+func (m *memoryRepository) GetNotification(ctx context.Context, notificationID string) (*pb.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, ok := m.notifications[notificationID]
+	if !ok {
+		return nil, errors.Wrapf(ErrNotFound, "notification id '%s'", notificationID)
+	}
+	c := n.Notification
+	return &c, nil
+}
+
+func (m *memoryRepository) ListNotifications(ctx context.Context) ([]*pb.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := []*memoryNotification{}
+	for _, n := range m.notifications {
+		stored = append(stored, n)
+	}
+	sort.Slice(stored, func(i, j int) bool {
+		if stored[i].created != stored[j].created {
+			return stored[i].created < stored[j].created
+		}
+		return stored[i].NotificationId < stored[j].NotificationId
+	})
+	notifications := []*pb.Notification{}
+	for _, n := range stored {
+		c := n.Notification
+		notifications = append(notifications, &c)
+	}
+	return notifications, nil
+}
+
+func (m *memoryRepository) InsertNotification(ctx context.Context, n *pb.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n.NotificationId = uuid.NewV4().String()
+	m.notifications[n.NotificationId] = &memoryNotification{Notification: *n, created: m.now()}
+	return nil
+}
+
+func (m *memoryRepository) UpdateNotification(ctx context.Context, n *pb.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.notifications[n.NotificationId]; ok {
+		c := *existing
+		c.Name, c.Template, c.DefaultTag = n.Name, n.Template, n.DefaultTag
+		m.notifications[n.NotificationId] = &c
+	}
+	return nil
+}
+
+func (m *memoryRepository) GetMostRecentPrompt(ctx context.Context, phoneNumber string) (*pb.Notification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var last *pb.Communication
+	var prompt *memoryNotification
+	for _, c := range m.communications {
+		n, ok := m.notifications[c.NotificationId]
+		if c.To != phoneNumber || c.Status != CommsSent || !ok || n.Type != "prompt" {
+			continue
+		}
+		if last == nil || c.Created > last.Created {
+			last, prompt = c, n
+		}
+	}
+	if prompt == nil {
+		return nil, errors.Wrapf(ErrNotFound, "sent message to %s", phoneNumber)
+	}
+	return &pb.Notification{NotificationId: prompt.NotificationId, Template: prompt.Template, DefaultTag: prompt.DefaultTag}, nil
+}
+
+func userNotificationKey(phoneNumber, notificationID string) string {
+	return phoneNumber + "|" + notificationID
+}
+
+func (m *memoryRepository) InsertUserNotification(ctx context.Context, up *pb.UserNotification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := userNotificationKey(up.PhoneNumber, up.NotificationId)
+	if existing, ok := m.userNotifications[key]; ok && existing.Deleted == "" {
+		return errors.Errorf("duplicate user notification '%s'", up.NotificationId)
+	}
+	m.userNotifications[key] = &pb.UserNotification{
+		NotificationId:       up.NotificationId,
+		PhoneNumber:          up.PhoneNumber,
+		NextNotificationTime: normalizeTime(up.NextNotificationTime),
+		Frequency:            up.Frequency,
+	}
+	return nil
+}
+
+// listUserNotifications returns copies of the stored user notifications
+// keep matches, joined with their notification.
+func (m *memoryRepository) listUserNotifications(keep func(up *pb.UserNotification) bool) []*pb.UserNotification {
+	userNotifications := []*pb.UserNotification{}
+	for _, up := range m.userNotifications {
+		n, ok := m.notifications[up.NotificationId]
+		if !ok || !keep(up) {
+			continue
+		}
+		c := *up
+		c.Notification = &pb.Notification{NotificationId: n.NotificationId, Template: n.Template, Type: n.Type, Name: n.Name}
+		userNotifications = append(userNotifications, &c)
+	}
+	sort.Slice(userNotifications, func(i, j int) bool {
+		a, b := userNotifications[i], userNotifications[j]
+		if a.NextNotificationTime != b.NextNotificationTime {
+			return a.NextNotificationTime < b.NextNotificationTime
+		}
+		return a.NotificationId < b.NotificationId
+	})
+	return userNotifications
+}
+
+func (m *memoryRepository) GetUserNotification(ctx context.Context, phoneNumber, notificationID string) (*pb.UserNotification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	userNotifications := m.listUserNotifications(func(up *pb.UserNotification) bool {
+		return up.PhoneNumber == phoneNumber && up.NotificationId == notificationID && up.Deleted == ""
+	})
+	if len(userNotifications) == 0 {
+		return nil, errors.Wrapf(ErrNotFound, "user notification '%s'", notificationID)
+	}
+	return userNotifications[0], nil
+}
+
+func (m *memoryRepository) ListUserNotifications(ctx context.Context, phoneNumber string) ([]*pb.UserNotification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.listUserNotifications(func(up *pb.UserNotification) bool {
+		return up.PhoneNumber == phoneNumber && up.Deleted == ""
+	}), nil
+}
+
+func (m *memoryRepository) ListDueUserNotifications(ctx context.Context, before time.Time) ([]*pb.UserNotification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cutoff := formatTime(before)
+	return m.listUserNotifications(func(up *pb.UserNotification) bool {
+		return up.NextNotificationTime <= cutoff && up.Deleted == ""
+	}), nil
+}
+
+func (m *memoryRepository) ListTrashedUserNotifications(ctx context.Context, phoneNumber string) ([]*pb.UserNotification, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	userNotifications := m.listUserNotifications(func(up *pb.UserNotification) bool {
+		return up.PhoneNumber == phoneNumber && up.Deleted != ""
+	})
+	sort.SliceStable(userNotifications, func(i, j int) bool {
+		return userNotifications[i].Deleted > userNotifications[j].Deleted
+	})
+	return userNotifications, nil
+}
+
+// updateUserNotification replaces the stored user notification with a copy
+// changed by fn, it reports whether there was one.
+func (m *memoryRepository) updateUserNotification(phoneNumber, notificationID string, fn func(up *pb.UserNotification) bool) bool {
+	key := userNotificationKey(phoneNumber, notificationID)
+	up, ok := m.userNotifications[key]
+	if !ok {
+		return false
+	}
+	c := *up
+	if !fn(&c) {
+		return false
+	}
+	m.userNotifications[key] = &c
+	return true
+}
+
+func (m *memoryRepository) AdvanceUserNotification(ctx context.Context, phoneNumber, notificationID string, due, next time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := userNotificationKey(phoneNumber, notificationID)
+	up, ok := m.userNotifications[key]
+	if !ok || up.Deleted != "" || normalizeTime(up.NextNotificationTime) != formatTime(due) {
+		return errors.Wrapf(ErrNotFound, "user notification '%s' due at %s", notificationID, formatTime(due))
+	}
+	if next.IsZero() {
+		delete(m.userNotifications, key)
+		return nil
+	}
+	c := *up
+	c.NextNotificationTime = formatTime(next)
+	m.userNotifications[key] = &c
+	return nil
+}
+
+func (m *memoryRepository) UpdateUserNotificationSchedule(ctx context.Context, up *pb.UserNotification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updateUserNotification(up.PhoneNumber, up.NotificationId, func(c *pb.UserNotification) bool {
+		c.NextNotificationTime, c.Frequency = normalizeTime(up.NextNotificationTime), up.Frequency
+		return true
+	})
+	return nil
+}
+
+func (m *memoryRepository) DeleteUserNotification(ctx context.Context, phoneNumber, notificationID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	m.updateUserNotification(phoneNumber, notificationID, func(up *pb.UserNotification) bool {
+		if up.Deleted != "" {
+			return false
+		}
+		up.Deleted = now
+		return true
+	})
+	return nil
+}
+
+func (m *memoryRepository) RestoreUserNotification(ctx context.Context, phoneNumber, notificationID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	restored := m.updateUserNotification(phoneNumber, notificationID, func(up *pb.UserNotification) bool {
+		if up.Deleted == "" {
+			return false
+		}
+		up.Deleted = ""
+		return true
+	})
+	if !restored {
+		return errors.Wrapf(ErrNotFound, "trashed user notification '%s'", notificationID)
+	}
+	return nil
+}
+
+func (m *memoryRepository) InsertCommunication(ctx context.Context, comm *pb.Communication) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if comm.CommsId == "" {
+		comm.CommsId = uuid.NewV4().String()
+	}
+	if comm.Status == "" {
+		comm.Status = CommsSent
+	}
+	c := *comm
+	c.Created = m.now()
+	m.communications[c.CommsId] = &c
+	return nil
+}
+
+func (m *memoryRepository) ListCommunications(ctx context.Context, phoneNumber string, page *Page, notificationID string) ([]*pb.Communication, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	matches := []*pb.Communication{}
+	for _, c := range m.communications {
+		if (c.To == phoneNumber || c.From == phoneNumber) && (notificationID == "" || c.NotificationId == notificationID) {
+			matches = append(matches, c)
+		}
+	}
+	idx, nextCursor := paginate(page, len(matches), func(i int) (string, string) {
+		return matches[i].Created, matches[i].CommsId
+	})
+	comms := []*pb.Communication{}
+	for _, i := range idx {
+		c := *matches[i]
+		comms = append(comms, &c)
+	}
+	return comms, nextCursor, nil
+}
+
+func (m *memoryRepository) ListDeadLetters(ctx context.Context, phoneNumber string) ([]*pb.Communication, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	comms := []*pb.Communication{}
+	for _, c := range m.communications {
+		if c.Status == CommsFailed && (phoneNumber == "" || c.To == phoneNumber) {
+			copied := *c
+			comms = append(comms, &copied)
+		}
+	}
+	sort.Slice(comms, func(a, b int) bool {
+		if comms[a].Created != comms[b].Created {
+			return comms[a].Created < comms[b].Created
+		}
+		return comms[a].CommsId < comms[b].CommsId
+	})
+	return comms, nil
+}
+
+func (m *memoryRepository) ClaimDeadLetter(ctx context.Context, commsID string) (*pb.Communication, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.communications[commsID]
+	if !ok || c.Status != CommsFailed {
+		return nil, errors.Wrapf(ErrNotFound, "dead letter '%s'", commsID)
+	}
+	claimed := *c
+	claimed.Status = CommsReplayed
+	m.communications[commsID] = &claimed
+	copied := claimed
+	return &copied, nil
+}
@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// migrations/<dialect> holds the schema as numbered NNNN_name.up.sql and
+// NNNN_name.down.sql pairs, written in that dialect.  a change to the schema
+// is a new pair in every dialect's directory.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one numbered change to the schema.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is a migration and when it was applied, "" if it's pending.
+type MigrationStatus struct {
+	*Migration
+	Applied string
+}
+
+// Migrator moves the store's schema between versions, recording what's
+// applied in the schema_migrations table.
+type Migrator interface {
+	// MigrateUp applies every pending migration, oldest first.
+	MigrateUp(ctx context.Context) ([]*Migration, error)
+	// MigrateDown reverts the newest steps applied migrations.
+	MigrateDown(ctx context.Context, steps int) ([]*Migration, error)
+	MigrationStatus(ctx context.Context) ([]*MigrationStatus, error)
+	// Baseline records the migrations through version as applied without
+	// running them, for databases built by hand from the old scripts.
+	Baseline(ctx context.Context, version int) error
+}
+
+// loadMigrations reads a dialect's migrations, sorted by version.
+func loadMigrations(dialectName string) ([]*Migration, error) {
+	dir := path.Join("migrations", dialectName)
+	entries, err := migrationFiles.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", dir)
+	}
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		match := migrationName.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file '%s' is misnamed", e.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both '%s' and '%s'", version, m.Name, match[2])
+		}
+		b, err := migrationFiles.ReadFile(path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", e.Name())
+		}
+		if match[3] == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+
+	migrations := []*Migration{}
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d needs both an up and a down", m.Version)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitStatements splits a migration into statements, which end with a ; at
+// the end of a line.  -- comment lines are dropped.
+func splitStatements(script string) []string {
+	statements := []string{}
+	current := []string{}
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		current = append(current, line)
+		if strings.HasSuffix(strings.TrimSpace(line), ";") {
+			statement := strings.TrimSuffix(strings.TrimSpace(strings.Join(current, "\n")), ";")
+			if statement != "" {
+				statements = append(statements, statement)
+			}
+			current = current[:0]
+		}
+	}
+	if statement := strings.TrimSpace(strings.Join(current, "\n")); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+func (r *sqlRepository) createMigrationsTable(ctx context.Context) error {
+	_, err := r.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations(
+			version INT PRIMARY KEY,
+			name VARCHAR(255),
+			applied DATETIME(6)
+		)`)
+	return errors.Wrap(err, "failed to create schema_migrations")
+}
+
+// appliedMigrations returns when each applied version was applied.
+func (r *sqlRepository) appliedMigrations(ctx context.Context) (map[int]string, error) {
+	if err := r.createMigrationsTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := r.query(ctx, `SELECT version, applied FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := map[int]string{}
+	for rows.Next() {
+		version, at := 0, ""
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		applied[version] = normalizeTime(at)
+	}
+	return applied, nil
+}
+
+// runMigration runs script and records the change to schema_migrations in
+// one transaction.  mysql commits ddl as it goes, so a failed mysql migration
+// can leave part of its changes behind.
+func (r *sqlRepository) runMigration(ctx context.Context, m *Migration, script string, up bool) error {
+	return r.InTx(ctx, func(tx Repository) error {
+		t := tx.(*sqlRepository)
+		for _, statement := range splitStatements(script) {
+			if _, err := t.exec(ctx, statement); err != nil {
+				return err
+			}
+		}
+		var err error
+		if up {
+			_, err = t.exec(ctx, `INSERT INTO schema_migrations (version, name, applied) VALUES (?, ?, NOW(6))`, m.Version, m.Name)
+		} else {
+			_, err = t.exec(ctx, `DELETE FROM schema_migrations WHERE version=?`, m.Version)
+		}
+		return err
+	})
+}
+
+// migrationLock is the named lock held while migrating, and
+// migrationLockTimeout how long to wait for another process to finish.
+const (
+	migrationLock        = "schema_migrations"
+	migrationLockTimeout = 5 * time.Minute
+)
+
+// lockMigrations takes migrationLock, on a connection of its own since mysql
+// ties the lock to it, so replicas started together work out what's pending
+// and apply it one at a time.  unlock releases it.
+func (r *sqlRepository) lockMigrations(ctx context.Context) (unlock func(), err error) {
+	if !r.dialect.namedLocks {
+		return func() {}, nil
+	}
+	c, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get a connection")
+	}
+	var got sql.NullInt64
+	if err := c.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, migrationLock, int(migrationLockTimeout/time.Second)).Scan(&got); err != nil {
+		c.Close()
+		return nil, errors.Wrap(err, "failed to get migration lock")
+	}
+	if got.Int64 != 1 {
+		c.Close()
+		return nil, fmt.Errorf("timed out after %s waiting for another process's migration", migrationLockTimeout)
+	}
+	return func() {
+		if _, err := c.ExecContext(context.Background(), `DO RELEASE_LOCK(?)`, migrationLock); err != nil {
+			//drop the connection rather than pool it, that releases it too
+			c.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		c.Close()
+	}, nil
+}
+
+func (r *sqlRepository) MigrateUp(ctx context.Context) ([]*Migration, error) {
+	migrations, err := loadMigrations(r.dialect.name)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := r.lockMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	applied, err := r.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	done := []*Migration{}
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := r.runMigration(ctx, m, m.Up, true); err != nil {
+			return done, errors.Wrapf(err, "failed to apply migration %d_%s", m.Version, m.Name)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+func (r *sqlRepository) MigrateDown(ctx context.Context, steps int) ([]*Migration, error) {
+	migrations, err := loadMigrations(r.dialect.name)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := r.lockMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	applied, err := r.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	done := []*Migration{}
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if err := r.runMigration(ctx, m, m.Down, false); err != nil {
+			return done, errors.Wrapf(err, "failed to revert migration %d_%s", m.Version, m.Name)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+func (r *sqlRepository) MigrationStatus(ctx context.Context) ([]*MigrationStatus, error) {
+	migrations, err := loadMigrations(r.dialect.name)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := r.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := []*MigrationStatus{}
+	for _, m := range migrations {
+		status = append(status, &MigrationStatus{Migration: m, Applied: applied[m.Version]})
+	}
+	return status, nil
+}
+
+func (r *sqlRepository) Baseline(ctx context.Context, version int) error {
+	migrations, err := loadMigrations(r.dialect.name)
+	if err != nil {
+		return err
+	}
+	unlock, err := r.lockMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	applied, err := r.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	return r.InTx(ctx, func(tx Repository) error {
+		t := tx.(*sqlRepository)
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok || m.Version > version {
+				continue
+			}
+			if _, err := t.exec(ctx, `INSERT INTO schema_migrations (version, name, applied) VALUES (?, ?, NOW(6))`, m.Version, m.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
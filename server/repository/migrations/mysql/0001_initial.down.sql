@@ -0,0 +1,5 @@
+DROP TABLE journals;
+DROP TABLE communications;
+DROP TABLE user_prompts;
+DROP TABLE prompts;
+DROP TABLE users;
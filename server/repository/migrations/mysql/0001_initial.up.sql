@@ -1,5 +1,3 @@
-
-DROP TABLE IF EXISTS users; 
 CREATE TABLE users(
     phone_number VARCHAR(10) DEFAULT "",
     name VARCHAR(255) DEFAULT "",
@@ -17,7 +15,6 @@ CREATE TABLE users(
     INDEX updated_index (updated)
 );
 
-DROP TABLE IF EXISTS prompts; 
 CREATE TABLE prompts(
     prompt_id VARCHAR(36),
     name varchar(40),
@@ -36,7 +33,6 @@ INSERT INTO prompts(prompt_id, name, type, template) VALUES
 ("81a36dd3-8301-410c-af35-0b2a87cdd921", "register-ack","registration", "{{.Name}}, you successfully registered :)"),
 ("7b1ced70-a2a0-40c5-8aa5-1cc5cff3b04b", "", "question", "What did you have for lunch?");
 
-DROP TABLE IF EXISTS user_prompts; 
 CREATE TABLE user_prompts(
     prompt_id VARCHAR(36),
     phone_number VARCHAR(10),
@@ -51,7 +47,6 @@ CREATE TABLE user_prompts(
     INDEX updated_index (updated)
 );
 
-DROP TABLE IF EXISTS communications; 
 CREATE TABLE communications(
     comms_id VARCHAR(36),
     from_phone VARCHAR(10),
@@ -64,7 +59,6 @@ CREATE TABLE communications(
     INDEX created_index (created)
 );
 
-DROP TABLE IF EXISTS journals; 
 CREATE TABLE journals(
     journal_id VARCHAR(36),
     comms_id VARCHAR(36),
@@ -0,0 +1,6 @@
+RENAME TABLE user_notifications TO user_prompts;
+RENAME TABLE notifications TO prompts;
+ALTER TABLE user_prompts CHANGE next_notification_time next_prompt_time datetime(6);
+ALTER TABLE user_prompts CHANGE notification_id prompt_id varchar(36);
+ALTER TABLE prompts CHANGE notification_id prompt_id varchar(36);
+ALTER TABLE journals CHANGE notification prompt text;
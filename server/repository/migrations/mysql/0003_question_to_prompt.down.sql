@@ -0,0 +1 @@
+UPDATE notifications SET type="question" WHERE type="prompt";
@@ -1,2 +1 @@
-
 UPDATE notifications SET type="prompt" WHERE type="question";
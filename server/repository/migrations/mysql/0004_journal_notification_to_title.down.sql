@@ -0,0 +1 @@
+ALTER TABLE journals CHANGE title notification text;
@@ -1,3 +1 @@
-
 ALTER TABLE journals CHANGE notification title text;
-
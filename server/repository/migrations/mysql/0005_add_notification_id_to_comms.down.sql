@@ -0,0 +1,2 @@
+DROP INDEX notification_id_index ON communications;
+ALTER TABLE communications DROP COLUMN notification_id;
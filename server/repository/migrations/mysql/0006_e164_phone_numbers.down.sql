@@ -0,0 +1,15 @@
+UPDATE users SET phone_number=SUBSTRING(phone_number, 3) WHERE phone_number LIKE "+1%" AND LENGTH(phone_number)=12;
+UPDATE users SET phone_number=SUBSTRING(phone_number, 2) WHERE phone_number LIKE "+000%";
+UPDATE user_notifications SET phone_number=SUBSTRING(phone_number, 3) WHERE phone_number LIKE "+1%" AND LENGTH(phone_number)=12;
+UPDATE user_notifications SET phone_number=SUBSTRING(phone_number, 2) WHERE phone_number LIKE "+000%";
+UPDATE communications SET from_phone=SUBSTRING(from_phone, 3) WHERE from_phone LIKE "+1%" AND LENGTH(from_phone)=12;
+UPDATE communications SET from_phone=SUBSTRING(from_phone, 2) WHERE from_phone LIKE "+000%";
+UPDATE communications SET to_phone=SUBSTRING(to_phone, 3) WHERE to_phone LIKE "+1%" AND LENGTH(to_phone)=12;
+UPDATE communications SET to_phone=SUBSTRING(to_phone, 2) WHERE to_phone LIKE "+000%";
+UPDATE journals SET phone_number=SUBSTRING(phone_number, 3) WHERE phone_number LIKE "+1%" AND LENGTH(phone_number)=12;
+UPDATE journals SET phone_number=SUBSTRING(phone_number, 2) WHERE phone_number LIKE "+000%";
+
+ALTER TABLE users MODIFY phone_number VARCHAR(10) DEFAULT "";
+ALTER TABLE user_notifications MODIFY phone_number VARCHAR(10);
+ALTER TABLE communications MODIFY from_phone VARCHAR(10), MODIFY to_phone VARCHAR(10);
+ALTER TABLE journals MODIFY phone_number VARCHAR(10);
@@ -0,0 +1,15 @@
+ALTER TABLE users MODIFY phone_number VARCHAR(16) DEFAULT "";
+ALTER TABLE user_notifications MODIFY phone_number VARCHAR(16);
+ALTER TABLE communications MODIFY from_phone VARCHAR(16), MODIFY to_phone VARCHAR(16);
+ALTER TABLE journals MODIFY phone_number VARCHAR(16);
+
+UPDATE users SET phone_number=CONCAT("+", phone_number) WHERE phone_number LIKE "000%";
+UPDATE users SET phone_number=CONCAT("+1", phone_number) WHERE phone_number NOT LIKE "+%" AND LENGTH(phone_number)=10;
+UPDATE user_notifications SET phone_number=CONCAT("+", phone_number) WHERE phone_number LIKE "000%";
+UPDATE user_notifications SET phone_number=CONCAT("+1", phone_number) WHERE phone_number NOT LIKE "+%" AND LENGTH(phone_number)=10;
+UPDATE communications SET from_phone=CONCAT("+", from_phone) WHERE from_phone LIKE "000%";
+UPDATE communications SET from_phone=CONCAT("+1", from_phone) WHERE from_phone NOT LIKE "+%" AND LENGTH(from_phone)=10;
+UPDATE communications SET to_phone=CONCAT("+", to_phone) WHERE to_phone LIKE "000%";
+UPDATE communications SET to_phone=CONCAT("+1", to_phone) WHERE to_phone NOT LIKE "+%" AND LENGTH(to_phone)=10;
+UPDATE journals SET phone_number=CONCAT("+", phone_number) WHERE phone_number LIKE "000%";
+UPDATE journals SET phone_number=CONCAT("+1", phone_number) WHERE phone_number NOT LIKE "+%" AND LENGTH(phone_number)=10;
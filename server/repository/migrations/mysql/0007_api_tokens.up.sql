@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN role VARCHAR(16) DEFAULT "user" AFTER session_id;
+
+CREATE TABLE api_tokens(
+    token_id VARCHAR(36),
+    phone_number VARCHAR(16),
+    name VARCHAR(255) DEFAULT "",
+    token_hash VARCHAR(64),
+    created DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+    last_used DATETIME(6) NULL,
+    PRIMARY KEY (token_id),
+    UNIQUE INDEX token_hash_index (token_hash),
+    INDEX phone_number_index (phone_number),
+    INDEX created_index (created)
+);
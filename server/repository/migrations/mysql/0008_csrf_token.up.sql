@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN csrf_token VARCHAR(36) DEFAULT "" AFTER session_id;
@@ -0,0 +1,2 @@
+DELETE FROM notifications WHERE notification_id="4f9b3c1e-8f1a-4a53-9d0a-6c2f2f7e9b12";
+DROP TABLE login_attempts;
@@ -0,0 +1,15 @@
+CREATE TABLE login_attempts(
+    attempt_id VARCHAR(36),
+    kind VARCHAR(16),
+    phone_number VARCHAR(16) DEFAULT "",
+    ip VARCHAR(45) DEFAULT "",
+    success TINYINT DEFAULT 0,
+    created DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (attempt_id),
+    INDEX kind_phone_number_index (kind, phone_number, created),
+    INDEX kind_ip_index (kind, ip, created),
+    INDEX created_index (created)
+);
+
+INSERT INTO notifications(notification_id, name, type, template) VALUES
+("4f9b3c1e-8f1a-4a53-9d0a-6c2f2f7e9b12", "lockout", "alert", "Your notify account is locked for {{.Minutes}} minutes after too many failed sign in attempts. If this wasn't you, change your password.");
@@ -0,0 +1,3 @@
+DELETE FROM notifications WHERE notification_id="c3d1f0a2-6b7e-4f25-8a4e-1d9b7c2e5f30";
+DROP TABLE login_codes;
+ALTER TABLE users DROP COLUMN two_factor;
@@ -0,0 +1,16 @@
+ALTER TABLE users ADD COLUMN two_factor TINYINT DEFAULT 0 AFTER verified;
+
+CREATE TABLE login_codes(
+    code_id VARCHAR(36),
+    phone_number VARCHAR(16),
+    code_hash VARCHAR(64),
+    used TINYINT DEFAULT 0,
+    expires DATETIME(6),
+    created DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (code_id),
+    INDEX phone_number_index (phone_number),
+    INDEX expires_index (expires)
+);
+
+INSERT INTO notifications(notification_id, name, type, template) VALUES
+("c3d1f0a2-6b7e-4f25-8a4e-1d9b7c2e5f30", "login-code", "alert", "{{.Code}} is your notify login code. It expires in {{.Minutes}} minutes.");
@@ -0,0 +1,3 @@
+DROP INDEX from_created_index ON communications;
+DROP INDEX to_created_index ON communications;
+DROP INDEX phone_number_created_index ON journals;
@@ -0,0 +1,3 @@
+CREATE INDEX phone_number_created_index ON journals (phone_number, created, journal_id);
+CREATE INDEX to_created_index ON communications (to_phone, created, comms_id);
+CREATE INDEX from_created_index ON communications (from_phone, created, comms_id);
@@ -0,0 +1 @@
+DROP INDEX title_entry_fulltext ON journals;
@@ -0,0 +1 @@
+CREATE FULLTEXT INDEX title_entry_fulltext ON journals (title, entry);
@@ -0,0 +1,3 @@
+DROP TABLE journal_imports;
+DROP INDEX phone_number_content_hash_index ON journals;
+ALTER TABLE journals DROP COLUMN content_hash;
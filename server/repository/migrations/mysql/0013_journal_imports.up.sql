@@ -0,0 +1,15 @@
+ALTER TABLE journals ADD COLUMN content_hash VARCHAR(64) AFTER entry;
+UPDATE journals SET content_hash=SHA2(CONCAT(IFNULL(title, ''), '\n', entry), 256);
+CREATE INDEX phone_number_content_hash_index ON journals (phone_number, content_hash);
+
+CREATE TABLE journal_imports(
+    import_id VARCHAR(36),
+    phone_number VARCHAR(16),
+    format VARCHAR(16),
+    imported INT,
+    duplicates INT,
+    invalid INT,
+    created DATETIME(6),
+    PRIMARY KEY (import_id),
+    INDEX phone_number_index (phone_number)
+);
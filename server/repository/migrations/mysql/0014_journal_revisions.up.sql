@@ -0,0 +1,9 @@
+CREATE TABLE journal_revisions(
+    revision_id VARCHAR(36),
+    journal_id VARCHAR(36),
+    phone_number VARCHAR(16),
+    entry TEXT,
+    created DATETIME(6),
+    PRIMARY KEY (revision_id),
+    INDEX journal_id_created_index (journal_id, created)
+);
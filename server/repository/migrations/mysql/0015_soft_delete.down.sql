@@ -0,0 +1,5 @@
+DROP INDEX deleted_index ON user_notifications;
+ALTER TABLE user_notifications DROP COLUMN deleted;
+
+DROP INDEX deleted_index ON journals;
+ALTER TABLE journals DROP COLUMN deleted;
@@ -0,0 +1,5 @@
+ALTER TABLE journals ADD COLUMN deleted DATETIME(6) NULL DEFAULT NULL AFTER updated;
+CREATE INDEX deleted_index ON journals (deleted);
+
+ALTER TABLE user_notifications ADD COLUMN deleted DATETIME(6) NULL DEFAULT NULL AFTER updated;
+CREATE INDEX deleted_index ON user_notifications (deleted);
@@ -0,0 +1,3 @@
+ALTER TABLE notifications DROP COLUMN default_tag;
+DROP TABLE journal_tags;
+DROP TABLE tags;
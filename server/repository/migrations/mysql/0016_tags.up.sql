@@ -0,0 +1,19 @@
+CREATE TABLE tags(
+    tag_id VARCHAR(36),
+    phone_number VARCHAR(16),
+    name VARCHAR(64),
+    created DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (tag_id),
+    UNIQUE INDEX phone_number_name_index (phone_number, name)
+);
+
+CREATE TABLE journal_tags(
+    journal_id VARCHAR(36),
+    tag_id VARCHAR(36),
+    source VARCHAR(16),
+    created DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (journal_id, tag_id, source),
+    INDEX tag_id_index (tag_id)
+);
+
+ALTER TABLE notifications ADD COLUMN default_tag VARCHAR(64) DEFAULT "" AFTER template;
@@ -0,0 +1,10 @@
+CREATE TABLE attachments(
+    attachment_id VARCHAR(36),
+    journal_id VARCHAR(36),
+    phone_number VARCHAR(16),
+    content_type VARCHAR(64),
+    size INT,
+    created DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (attachment_id),
+    INDEX journal_id_index (journal_id)
+);
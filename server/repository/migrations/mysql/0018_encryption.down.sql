@@ -0,0 +1,3 @@
+-- only restores the index, rows already encrypted stay encrypted
+CREATE FULLTEXT INDEX title_entry_fulltext ON journals (title, entry);
+DROP TABLE user_keys;
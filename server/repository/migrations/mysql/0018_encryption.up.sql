@@ -0,0 +1,13 @@
+CREATE TABLE user_keys(
+    key_id VARCHAR(36),
+    phone_number VARCHAR(16),
+    master_key_id VARCHAR(64),
+    wrapped_key TEXT,
+    created DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6),
+    retired DATETIME(6) NULL DEFAULT NULL,
+    PRIMARY KEY (key_id),
+    INDEX phone_number_index (phone_number)
+);
+
+-- ciphertext can't be fulltext searched, search uses the in memory index
+ALTER TABLE journals DROP INDEX title_entry_fulltext;
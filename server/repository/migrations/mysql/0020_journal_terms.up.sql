@@ -0,0 +1,10 @@
+-- a blind index of journals for search: keyed hashes of their words, so
+-- journals can be matched without the store seeing what's in them
+CREATE TABLE journal_terms(
+    journal_id VARCHAR(36),
+    phone_number VARCHAR(16),
+    term CHAR(32),
+    occurrences INT,
+    PRIMARY KEY (journal_id, term),
+    INDEX phone_number_term_index (phone_number, term)
+);
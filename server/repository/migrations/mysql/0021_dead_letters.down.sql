@@ -0,0 +1,2 @@
+ALTER TABLE communications DROP INDEX status_index;
+ALTER TABLE communications DROP COLUMN error, DROP COLUMN status;
@@ -0,0 +1,3 @@
+-- failed sends are kept as dead letters for notifyctl dead-letters replay
+ALTER TABLE communications ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'sent' AFTER message, ADD COLUMN error TEXT AFTER status;
+CREATE INDEX status_index ON communications (status);
@@ -0,0 +1 @@
+ALTER TABLE login_codes DROP COLUMN attempts;
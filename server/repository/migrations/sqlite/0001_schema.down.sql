@@ -0,0 +1,14 @@
+DROP TABLE user_keys;
+DROP TABLE login_codes;
+DROP TABLE login_attempts;
+DROP TABLE api_tokens;
+DROP TABLE attachments;
+DROP TABLE journal_tags;
+DROP TABLE tags;
+DROP TABLE journal_imports;
+DROP TABLE journal_revisions;
+DROP TABLE journals;
+DROP TABLE communications;
+DROP TABLE user_notifications;
+DROP TABLE notifications;
+DROP TABLE users;
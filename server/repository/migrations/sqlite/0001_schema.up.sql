@@ -0,0 +1,154 @@
+-- the schema the mysql migrations build up, timestamps are text in
+-- TimeFormat so they sort and compare like DATETIME(6)
+CREATE TABLE IF NOT EXISTS users(
+    phone_number TEXT PRIMARY KEY,
+    name TEXT DEFAULT '',
+    birthday TEXT DEFAULT '',
+    hashword TEXT DEFAULT '',
+    verified INTEGER DEFAULT 0,
+    two_factor INTEGER DEFAULT 0,
+    session_id TEXT DEFAULT '',
+    csrf_token TEXT DEFAULT '',
+    role TEXT DEFAULT 'user',
+    created TEXT,
+    updated TEXT
+);
+
+CREATE TABLE IF NOT EXISTS notifications(
+    notification_id TEXT PRIMARY KEY,
+    name TEXT DEFAULT '',
+    type TEXT DEFAULT '',
+    template TEXT DEFAULT '',
+    default_tag TEXT DEFAULT '',
+    created TEXT,
+    updated TEXT
+);
+
+CREATE TABLE IF NOT EXISTS user_notifications(
+    notification_id TEXT,
+    phone_number TEXT,
+    next_notification_time TEXT,
+    frequency TEXT DEFAULT '',
+    created TEXT,
+    updated TEXT,
+    deleted TEXT NULL,
+    PRIMARY KEY (notification_id, phone_number)
+);
+CREATE INDEX IF NOT EXISTS user_notifications_next_index ON user_notifications (next_notification_time);
+
+CREATE TABLE IF NOT EXISTS communications(
+    comms_id TEXT PRIMARY KEY,
+    notification_id TEXT DEFAULT '',
+    from_phone TEXT,
+    to_phone TEXT,
+    message TEXT,
+    created TEXT
+);
+CREATE INDEX IF NOT EXISTS communications_to_index ON communications (to_phone, created, comms_id);
+CREATE INDEX IF NOT EXISTS communications_from_index ON communications (from_phone, created, comms_id);
+
+CREATE TABLE IF NOT EXISTS journals(
+    journal_id TEXT PRIMARY KEY,
+    comms_id TEXT DEFAULT '',
+    phone_number TEXT,
+    title TEXT DEFAULT '',
+    entry TEXT DEFAULT '',
+    content_hash TEXT DEFAULT '',
+    created TEXT,
+    updated TEXT,
+    deleted TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS journals_phone_number_index ON journals (phone_number, created, journal_id);
+CREATE INDEX IF NOT EXISTS journals_content_hash_index ON journals (phone_number, content_hash);
+
+CREATE TABLE IF NOT EXISTS journal_revisions(
+    revision_id TEXT PRIMARY KEY,
+    journal_id TEXT,
+    phone_number TEXT,
+    entry TEXT,
+    created TEXT
+);
+CREATE INDEX IF NOT EXISTS journal_revisions_journal_id_index ON journal_revisions (journal_id, created);
+
+CREATE TABLE IF NOT EXISTS journal_imports(
+    import_id TEXT PRIMARY KEY,
+    phone_number TEXT,
+    format TEXT,
+    imported INTEGER,
+    duplicates INTEGER,
+    invalid INTEGER,
+    created TEXT
+);
+
+CREATE TABLE IF NOT EXISTS tags(
+    tag_id TEXT PRIMARY KEY,
+    phone_number TEXT,
+    name TEXT,
+    created TEXT,
+    UNIQUE (phone_number, name)
+);
+
+CREATE TABLE IF NOT EXISTS journal_tags(
+    journal_id TEXT,
+    tag_id TEXT,
+    source TEXT,
+    created TEXT,
+    PRIMARY KEY (journal_id, tag_id, source)
+);
+
+CREATE TABLE IF NOT EXISTS attachments(
+    attachment_id TEXT PRIMARY KEY,
+    journal_id TEXT,
+    phone_number TEXT,
+    content_type TEXT,
+    size INTEGER,
+    created TEXT
+);
+CREATE INDEX IF NOT EXISTS attachments_journal_id_index ON attachments (journal_id);
+
+CREATE TABLE IF NOT EXISTS api_tokens(
+    token_id TEXT PRIMARY KEY,
+    phone_number TEXT,
+    name TEXT DEFAULT '',
+    token_hash TEXT UNIQUE,
+    created TEXT,
+    last_used TEXT NULL
+);
+
+CREATE TABLE IF NOT EXISTS login_attempts(
+    attempt_id TEXT PRIMARY KEY,
+    kind TEXT,
+    phone_number TEXT DEFAULT '',
+    ip TEXT DEFAULT '',
+    success INTEGER DEFAULT 0,
+    created TEXT
+);
+CREATE INDEX IF NOT EXISTS login_attempts_phone_number_index ON login_attempts (kind, phone_number, created);
+CREATE INDEX IF NOT EXISTS login_attempts_ip_index ON login_attempts (kind, ip, created);
+
+CREATE TABLE IF NOT EXISTS login_codes(
+    code_id TEXT PRIMARY KEY,
+    phone_number TEXT,
+    code_hash TEXT,
+    used INTEGER DEFAULT 0,
+    expires TEXT,
+    created TEXT
+);
+
+CREATE TABLE IF NOT EXISTS user_keys(
+    key_id TEXT PRIMARY KEY,
+    phone_number TEXT,
+    master_key_id TEXT,
+    wrapped_key TEXT,
+    created TEXT,
+    retired TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS user_keys_phone_number_index ON user_keys (phone_number);
+
+INSERT OR IGNORE INTO notifications(notification_id, name, type, template, created, updated) VALUES
+('0939c423-f3e2-468f-8e34-8e5b0c5391bc', '', 'reminder', 'hello world', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000'),
+('deaabd59-0d15-4f44-a3a8-1e3f920a3710', 'register', 'registration', 'respond with ''reg'' to register!', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000'),
+('81a36dd3-8301-410c-af35-0b2a87cdd921', 'register-ack', 'registration', '{{.Name}}, you successfully registered :)', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000'),
+('7b1ced70-a2a0-40c5-8aa5-1cc5cff3b04b', '', 'prompt', 'What did you have for lunch?', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000'),
+('4f9b3c1e-8f1a-4a53-9d0a-6c2f2f7e9b12', 'lockout', 'alert', 'Your notify account is locked for {{.Minutes}} minutes after too many failed sign in attempts. If this wasn''t you, change your password.', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000'),
+('c3d1f0a2-6b7e-4f25-8a4e-1d9b7c2e5f30', 'login-code', 'alert', '{{.Code}} is your notify login code. It expires in {{.Minutes}} minutes.', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000', strftime('%Y-%m-%d %H:%M:%f', 'now') || '000');
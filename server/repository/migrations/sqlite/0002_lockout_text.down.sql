@@ -0,0 +1 @@
+UPDATE notifications SET template='Your notify account is locked for {{.Minutes}} minutes after too many failed sign in attempts. If this wasn''t you, change your password.' WHERE notification_id='4f9b3c1e-8f1a-4a53-9d0a-6c2f2f7e9b12';
@@ -0,0 +1,2 @@
+-- there's no way to change a password, say what a lockout does mean instead
+UPDATE notifications SET template='Your notify account is locked for {{.Minutes}} minutes after too many failed sign in attempts. If this wasn''t you, nothing was accessed and you can sign in again after that.' WHERE notification_id='4f9b3c1e-8f1a-4a53-9d0a-6c2f2f7e9b12';
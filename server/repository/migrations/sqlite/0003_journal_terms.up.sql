@@ -0,0 +1,11 @@
+-- a blind index of journals for search: keyed hashes of their words, so
+-- journals can be matched without the store seeing what's in them
+CREATE TABLE IF NOT EXISTS journal_terms(
+    journal_id TEXT,
+    phone_number TEXT,
+    term TEXT,
+    occurrences INTEGER,
+    PRIMARY KEY (journal_id, term)
+);
+
+CREATE INDEX IF NOT EXISTS journal_terms_phone_number_term ON journal_terms (phone_number, term);
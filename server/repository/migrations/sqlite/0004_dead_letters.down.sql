@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS communications_status_index;
+ALTER TABLE communications DROP COLUMN error;
+ALTER TABLE communications DROP COLUMN status;
@@ -0,0 +1,4 @@
+-- failed sends are kept as dead letters for notifyctl dead-letters replay
+ALTER TABLE communications ADD COLUMN status TEXT NOT NULL DEFAULT 'sent';
+ALTER TABLE communications ADD COLUMN error TEXT;
+CREATE INDEX IF NOT EXISTS communications_status_index ON communications (status);
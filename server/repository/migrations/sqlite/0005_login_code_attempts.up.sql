@@ -0,0 +1,2 @@
+-- wrong guesses at a login code, it is burned after a few
+ALTER TABLE login_codes ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+)
+
+var mysqlDialect = dialect{name: "mysql", rewrite: strings.NewReplacer(), namedLocks: true, lockingReads: true}
+
+// Pool sizes the connection pool to the database.
+type Pool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// OpenMySQL connects to the mysql database at dsn, in go-sql-driver form.
+func OpenMySQL(dsn string, pool Pool) (Repository, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open dbconn")
+	}
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	return newSQLRepository(db, mysqlDialect), nil
+}
@@ -0,0 +1,322 @@
+// Package repository is the persistence layer of the notify app.  Repository
+// is implemented against mysql, which is what we run, against sqlite, for
+// running the server without the rds instance, and in memory.
+//
+// timestamps are strings in TimeFormat, like the DATETIME(6) columns come
+// back from mysql.  values are stored as given: journals and inbound messages
+// are encrypted by the caller.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+)
+
+const TimeFormat = "2006-01-02 15:04:05.000000"
+
+// ErrNotFound is the cause of errors from methods that look up a single row
+// which doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+type Repository interface {
+	Users
+	Notifications
+	UserNotifications
+	Communications
+	Journals
+	Keys
+	Migrator
+
+	// Now is the store's clock, the one created and updated times come from.
+	Now(ctx context.Context) (time.Time, error)
+	// InTx runs fn with a Repository whose writes are kept only if fn
+	// returns nil.
+	InTx(ctx context.Context, fn func(Repository) error) error
+	Ping(ctx context.Context) error
+	// Stats is the connection pool's, zero for stores without one.
+	Stats() sql.DBStats
+	Close() error
+}
+
+type Users interface {
+	// InsertUser stores user with user.Password as its hashword.
+	InsertUser(ctx context.Context, user *pb.User) error
+	// GetUser returns the user with their hashword in Password.
+	GetUser(ctx context.Context, phoneNumber string) (*pb.User, error)
+	// UpdateUser saves the verified, session, csrf and two factor fields.
+	UpdateUser(ctx context.Context, user *pb.User) error
+
+	InsertApiToken(ctx context.Context, t *pb.ApiToken, tokenHash string) error
+	ListApiTokens(ctx context.Context, phoneNumber string) ([]*pb.ApiToken, error)
+	// GetApiTokenUser returns the owner of a token and marks the token used.
+	GetApiTokenUser(ctx context.Context, tokenHash string) (*pb.User, error)
+	DeleteApiToken(ctx context.Context, phoneNumber, tokenID string) error
+
+	InsertLoginAttempt(ctx context.Context, kind, phoneNumber, ip string, success bool) error
+	// CountFailedLoginAttempts counts failures of kind by phone_number or ip
+	// since since, and returns the time of the latest.  a phone number's
+	// success resets its count, an ip's doesn't, one account of an attacker's
+	// mustn't clear the failures it has against others.
+	CountFailedLoginAttempts(ctx context.Context, kind, column, value string, since time.Time) (int, time.Time, error)
+	// ClaimLoginAttempt records an attempt of kind as a failure before it is
+	// verified, unless check fails for the failures counted, as
+	// CountFailedLoginAttempts would, by phone_number or by ip.  the counts
+	// and the insert are atomic, so concurrent attempts count against each
+	// other.  it returns the attempt's id.
+	ClaimLoginAttempt(ctx context.Context, kind, phoneNumber, ip string, since time.Time, check func(column string, n int, last time.Time) error) (string, error)
+	// SucceedLoginAttempt marks a claimed attempt a success.
+	SucceedLoginAttempt(ctx context.Context, attemptID string) error
+
+	InsertLoginCode(ctx context.Context, phoneNumber, codeHash string, lifetime time.Duration) (string, error)
+	// GetLoginCode returns an unused, unexpired code.
+	GetLoginCode(ctx context.Context, codeID string) (*LoginCode, error)
+	// UseLoginCode marks a code used, ErrNotFound if it already was, so
+	// only one login gets it.
+	UseLoginCode(ctx context.Context, codeID string) error
+	// MissLoginCode counts a wrong guess at an unused code, and uses it up
+	// once there have been maxMisses, which it then says.  it returns
+	// ErrNotFound if the code was already used.
+	MissLoginCode(ctx context.Context, codeID string, maxMisses int) (bool, error)
+}
+
+type Notifications interface {
+	GetNotification(ctx context.Context, notificationID string) (*pb.Notification, error)
+	ListNotifications(ctx context.Context) ([]*pb.Notification, error)
+	InsertNotification(ctx context.Context, n *pb.Notification) error
+	// UpdateNotification saves the name, template and default tag.
+	UpdateNotification(ctx context.Context, n *pb.Notification) error
+	// GetMostRecentPrompt returns the last prompt texted to phoneNumber.
+	GetMostRecentPrompt(ctx context.Context, phoneNumber string) (*pb.Notification, error)
+}
+
+// UserNotifications in the trash are left out of everything but the trash
+// methods.
+type UserNotifications interface {
+	// InsertUserNotification replaces a trashed copy, if there is one.
+	InsertUserNotification(ctx context.Context, up *pb.UserNotification) error
+	GetUserNotification(ctx context.Context, phoneNumber, notificationID string) (*pb.UserNotification, error)
+	ListUserNotifications(ctx context.Context, phoneNumber string) ([]*pb.UserNotification, error)
+	// ListDueUserNotifications returns every user notification whose next
+	// time is at or before before.
+	ListDueUserNotifications(ctx context.Context, before time.Time) ([]*pb.UserNotification, error)
+	// AdvanceUserNotification claims a user notification due at due, by
+	// moving it on to next, or deleting it if next is zero, for one that
+	// doesn't recur.  ErrNotFound means it's no longer due at due, another
+	// run claimed it first.
+	AdvanceUserNotification(ctx context.Context, phoneNumber, notificationID string, due, next time.Time) error
+	// UpdateUserNotificationSchedule saves the next time and frequency.
+	UpdateUserNotificationSchedule(ctx context.Context, up *pb.UserNotification) error
+	// DeleteUserNotification moves a user notification to the trash.
+	DeleteUserNotification(ctx context.Context, phoneNumber, notificationID string) error
+	RestoreUserNotification(ctx context.Context, phoneNumber, notificationID string) error
+	ListTrashedUserNotifications(ctx context.Context, phoneNumber string) ([]*pb.UserNotification, error)
+}
+
+// the statuses of a communication.  a scheduled send that fails is stored
+// CommsFailed with its error, a dead letter, until it's replayed.
+const (
+	CommsSent     = "sent"
+	CommsFailed   = "failed"
+	CommsReplayed = "replayed"
+)
+
+type Communications interface {
+	// InsertCommunication stores comm, CommsSent unless comm.Status is set.
+	InsertCommunication(ctx context.Context, comm *pb.Communication) error
+	// ListCommunications returns a page of the messages sent to or received
+	// from a user, optionally only those for one notification, and the next
+	// cursor.
+	ListCommunications(ctx context.Context, phoneNumber string, page *Page, notificationID string) ([]*pb.Communication, string, error)
+	// ListDeadLetters returns the failed sends, only those to phoneNumber if
+	// it's set, oldest first.
+	ListDeadLetters(ctx context.Context, phoneNumber string) ([]*pb.Communication, error)
+	// ClaimDeadLetter marks a failed send replayed and returns it, or
+	// ErrNotFound if it isn't a failed send, so it's only replayed once.
+	ClaimDeadLetter(ctx context.Context, commsID string) (*pb.Communication, error)
+}
+
+// Journals in the trash are left out of everything but the trash methods.
+// journals are returned with their tags and attachments.
+type Journals interface {
+	// InsertJournal inserts j, created now unless j.Created is set, with a
+	// new id unless j.JournalId is set.
+	InsertJournal(ctx context.Context, j *pb.Journal, contentHash string) error
+	GetJournal(ctx context.Context, phoneNumber, journalID string) (*pb.Journal, error)
+	// LockJournal is GetJournal without the tags and attachments, and in a
+	// transaction on a database with locking reads it locks the row until
+	// the transaction ends, so edits to it are serialized.
+	LockJournal(ctx context.Context, phoneNumber, journalID string) (*pb.Journal, error)
+	// ListJournals returns a page of a user's journals, optionally only those
+	// tagged tag and with every one of terms, and the next cursor.
+	ListJournals(ctx context.Context, phoneNumber string, page *Page, tag string, terms []string) ([]*pb.Journal, string, error)
+	UpdateJournalEntry(ctx context.Context, j *pb.Journal, contentHash string) error
+	// DeleteJournal moves a journal to the trash.
+	DeleteJournal(ctx context.Context, phoneNumber, journalID string) error
+	RestoreJournal(ctx context.Context, phoneNumber, journalID string) error
+	ListTrashedJournals(ctx context.Context, phoneNumber string) ([]*pb.Journal, error)
+	// JournalExists reports whether a user has a journal with contentHash
+	// created on the same day as created.
+	JournalExists(ctx context.Context, phoneNumber, contentHash, created string) (bool, error)
+	InsertJournalImport(ctx context.Context, phoneNumber, format string, report *pb.ImportReport) error
+
+	// SetJournalTerms replaces a journal's search terms, hashes of its words
+	// made by the app, with how often each occurs.
+	SetJournalTerms(ctx context.Context, phoneNumber, journalID string, terms map[string]int) error
+	// SearchJournals returns up to limit of a user's journals with any of
+	// terms, those with the most occurrences of them first.
+	SearchJournals(ctx context.Context, phoneNumber string, terms []string, limit int) ([]*pb.Journal, error)
+	CountJournals(ctx context.Context, phoneNumber string) (int, error)
+
+	// InsertJournalRevision copies a journal's current entry into its
+	// revisions.
+	InsertJournalRevision(ctx context.Context, phoneNumber, journalID string) error
+	// ListJournalRevisions returns a journal's prior entries, newest first.
+	ListJournalRevisions(ctx context.Context, phoneNumber, journalID string) ([]*pb.JournalRevision, error)
+	GetJournalRevision(ctx context.Context, phoneNumber, revisionID string) (*pb.JournalRevision, error)
+
+	// AddJournalTags tags a journal, creating the user's tags on first use.
+	AddJournalTags(ctx context.Context, phoneNumber, journalID string, tags []string, source string) error
+	// DeleteJournalTags removes the tags on a journal that came from source.
+	DeleteJournalTags(ctx context.Context, journalID, source string) error
+	// ListTagCounts returns how many of a user's journals carry each tag.
+	ListTagCounts(ctx context.Context, phoneNumber string) ([]*pb.TagCount, error)
+
+	InsertAttachment(ctx context.Context, phoneNumber string, a *pb.Attachment) error
+	GetAttachment(ctx context.Context, phoneNumber, attachmentID string) (*pb.Attachment, error)
+
+	// ListPurgeableAttachments returns the attachments of journals trashed
+	// before before, so their blobs can be removed ahead of PurgeTrash.
+	ListPurgeableAttachments(ctx context.Context, before time.Time) ([]string, error)
+	// PurgeTrash hard deletes journals and user notifications trashed before
+	// before, along with the revisions, tags and attachments of the journals.
+	PurgeTrash(ctx context.Context, before time.Time) error
+}
+
+// Keys holds the users' wrapped data keys, and finds the values still to be
+// encrypted under them.
+type Keys interface {
+	InsertUserKey(ctx context.Context, k *UserKey) error
+	GetUserKey(ctx context.Context, phoneNumber, keyID string) (*UserKey, error)
+	// GetActiveUserKeyID returns the id of a user's newest unretired key, or
+	// "" if they have none.
+	GetActiveUserKeyID(ctx context.Context, phoneNumber string) (string, error)
+	// ListUserKeysWrappedByOthers returns the keys not wrapped by masterKeyID.
+	ListUserKeysWrappedByOthers(ctx context.Context, masterKeyID string) ([]*UserKey, error)
+	UpdateUserKeyWrap(ctx context.Context, k *UserKey) error
+	RetireUserKeys(ctx context.Context, phoneNumber string) error
+
+	// ListUnsealedRows returns up to limit rows with a value that doesn't
+	// start with prefix.  phoneNumber, if set, limits it to one user's rows,
+	// and communications are only those sent to inboundTo.
+	ListUnsealedRows(ctx context.Context, prefix, phoneNumber, inboundTo string, limit int) ([]*SealedRow, error)
+	// UpdateSealedRow replaces row's values with sealed, and its content hash
+	// with hash if row.Hashed, unless the row no longer has row.Values, when
+	// it returns ErrNotFound so a concurrent edit isn't overwritten.
+	UpdateSealedRow(ctx context.Context, row *SealedRow, sealed []string, hash string) error
+}
+
+type LoginCode struct {
+	CodeID      string
+	PhoneNumber string
+	CodeHash    string
+}
+
+type UserKey struct {
+	KeyID       string
+	PhoneNumber string
+	MasterKeyID string
+	WrappedKey  string
+}
+
+// SealedRow is the encrypted columns of a row, in the order of its table's
+// sealedColumns.  the values are sealed at SealedTable, Columns and SealedID,
+// which are the row's own except for revisions, copies of their journal's
+// entry.  Hashed rows also have a content hash of their values.
+type SealedRow struct {
+	Table       string
+	ID          string
+	PhoneNumber string
+	Values      []string
+	SealedTable string
+	SealedID    string
+	Columns     []string
+	Hashed      bool
+}
+
+// sealedColumns are the columns the app encrypts.  inbound limits the rows to
+// messages users texted us.
+var sealedColumns = []struct {
+	table, idCol, phoneCol string
+	cols                   []string
+	sealedTable, sealedCol string
+	hashCol                string
+	inbound                bool
+}{
+	{table: "journals", idCol: "journal_id", phoneCol: "phone_number", cols: []string{"title", "entry"},
+		sealedTable: "journals", sealedCol: "journal_id", hashCol: "content_hash"},
+	{table: "journal_revisions", idCol: "revision_id", phoneCol: "phone_number", cols: []string{"entry"},
+		sealedTable: "journals", sealedCol: "journal_id"},
+	{table: "communications", idCol: "comms_id", phoneCol: "from_phone", cols: []string{"message"},
+		sealedTable: "communications", sealedCol: "comms_id", inbound: true},
+}
+
+// Page is the cursor pagination and created date range shared by the list
+// methods.  rows are returned newest first, and the cursor is the created
+// time and id of the last row of the previous page.
+type Page struct {
+	Cursor        string
+	Limit         int
+	CreatedAfter  string
+	CreatedBefore string
+}
+
+func EncodeCursor(created, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(created + "|" + id))
+}
+
+func DecodeCursor(cursor string) (string, string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to decode cursor")
+	}
+	parts := strings.SplitN(string(b), "|", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("cursor '%s' is invalid", cursor)
+	}
+	return parts[0], parts[1], nil
+}
+
+// formatTime writes t the way the stores keep timestamps.
+func formatTime(t time.Time) string {
+	return t.Format(TimeFormat)
+}
+
+// normalizeTime rewrites a timestamp with or without fractional seconds in
+// TimeFormat, so timestamps compare as strings.
+func normalizeTime(value string) string {
+	t, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		return value
+	}
+	return formatTime(t)
+}
+
+// distinct returns values without repeats, in their first order.
+func distinct(values []string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
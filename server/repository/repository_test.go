@@ -0,0 +1,572 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+)
+
+// stores are the Repository implementations every conformance case runs
+// against.  mysql needs a running server, so it isn't one of them.
+var stores = []struct {
+	name string
+	open func(t *testing.T) Repository
+}{
+	{"memory", func(t *testing.T) Repository { return NewMemory() }},
+	{"sqlite", func(t *testing.T) Repository {
+		r, err := OpenSQLite(filepath.Join(t.TempDir(), "notify.db"))
+		if err != nil {
+			t.Fatalf("failed to open sqlite: %s", err)
+		}
+		t.Cleanup(func() { r.Close() })
+		return r
+	}},
+}
+
+const testPhone = "+15555550100"
+
+var conformance = []struct {
+	name string
+	run  func(t *testing.T, ctx context.Context, r Repository)
+}{
+	{"pagination cursors", testPagination},
+	{"trash and purge", testTrashAndPurge},
+	{"tags", testTags},
+	{"InTx rollback", testInTxRollback},
+	{"failed login attempts", testFailedLoginAttempts},
+	{"claim login attempts", testClaimLoginAttempts},
+	{"login codes", testLoginCodes},
+	{"sealed rows", testSealedRows},
+	{"journal terms", testJournalTerms},
+	{"dead letters", testDeadLetters},
+	{"advance user notifications", testAdvanceUserNotification},
+}
+
+func TestRepositoryConformance(t *testing.T) {
+	for _, store := range stores {
+		t.Run(store.name, func(t *testing.T) {
+			for _, c := range conformance {
+				t.Run(c.name, func(t *testing.T) {
+					ctx := context.Background()
+					r := store.open(t)
+					if _, err := r.MigrateUp(ctx); err != nil {
+						t.Fatalf("failed to migrate: %s", err)
+					}
+					c.run(t, ctx, r)
+				})
+			}
+		})
+	}
+}
+
+func insertTestJournal(t *testing.T, ctx context.Context, r Repository, created, entry string) *pb.Journal {
+	t.Helper()
+	j := &pb.Journal{PhoneNumber: testPhone, Entry: entry, Created: created}
+	if err := r.InsertJournal(ctx, j, entry); err != nil {
+		t.Fatalf("failed to insert journal: %s", err)
+	}
+	return j
+}
+
+func testPagination(t *testing.T, ctx context.Context, r Repository) {
+	//two share a created time, so the id has to break the tie
+	want := map[string]bool{}
+	for i, created := range []string{"2020-01-01 00:00:00", "2020-01-02 00:00:00", "2020-01-02 00:00:00", "2020-01-03 00:00:00", "2020-01-04 00:00:00"} {
+		want[insertTestJournal(t, ctx, r, created, fmt.Sprintf("entry %d", i)).JournalId] = true
+	}
+
+	page := &Page{Limit: 2}
+	seen := map[string]bool{}
+	last := ""
+	for pages := 1; ; pages++ {
+		journals, cursor, err := r.ListJournals(ctx, testPhone, page, "", nil)
+		if err != nil {
+			t.Fatalf("failed to list page %d: %s", pages, err)
+		}
+		for _, j := range journals {
+			if seen[j.JournalId] {
+				t.Errorf("journal %s on more than one page", j.JournalId)
+			}
+			seen[j.JournalId] = true
+			key := j.Created + j.JournalId
+			if last != "" && key > last {
+				t.Errorf("page %d isn't newest first: %s after %s", pages, key, last)
+			}
+			last = key
+		}
+		if cursor == "" {
+			if pages != 3 {
+				t.Errorf("got %d pages, want 3", pages)
+			}
+			break
+		}
+		if pages > 3 {
+			t.Fatalf("cursor never ran out")
+		}
+		page.Cursor = cursor
+	}
+	if len(seen) != len(want) {
+		t.Errorf("listed %d journals, want %d", len(seen), len(want))
+	}
+
+	page = &Page{Limit: 10, CreatedAfter: "2020-01-02 00:00:00", CreatedBefore: "2020-01-04 00:00:00"}
+	journals, _, err := r.ListJournals(ctx, testPhone, page, "", nil)
+	if err != nil {
+		t.Fatalf("failed to list range: %s", err)
+	}
+	if len(journals) != 3 {
+		t.Errorf("listed %d journals in range, want 3", len(journals))
+	}
+}
+
+func testTrashAndPurge(t *testing.T, ctx context.Context, r Repository) {
+	j := insertTestJournal(t, ctx, r, "", "trash me")
+	if err := r.AddJournalTags(ctx, testPhone, j.JournalId, []string{"gone"}, "manual"); err != nil {
+		t.Fatalf("failed to tag: %s", err)
+	}
+
+	if err := r.DeleteJournal(ctx, testPhone, j.JournalId); err != nil {
+		t.Fatalf("failed to delete: %s", err)
+	}
+	if _, err := r.GetJournal(ctx, testPhone, j.JournalId); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v getting a trashed journal, want ErrNotFound", err)
+	}
+	if trashed, err := r.ListTrashedJournals(ctx, testPhone); err != nil || len(trashed) != 1 {
+		t.Errorf("got %d trashed journals and %v, want 1", len(trashed), err)
+	}
+
+	if err := r.RestoreJournal(ctx, testPhone, j.JournalId); err != nil {
+		t.Fatalf("failed to restore: %s", err)
+	}
+	if _, err := r.GetJournal(ctx, testPhone, j.JournalId); err != nil {
+		t.Errorf("failed to get a restored journal: %s", err)
+	}
+	if err := r.RestoreJournal(ctx, testPhone, j.JournalId); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v restoring a journal not in the trash, want ErrNotFound", err)
+	}
+
+	if err := r.DeleteJournal(ctx, testPhone, j.JournalId); err != nil {
+		t.Fatalf("failed to delete: %s", err)
+	}
+	if err := r.PurgeTrash(ctx, time.Now().UTC().Add(-time.Hour)); err != nil {
+		t.Fatalf("failed to purge: %s", err)
+	}
+	if trashed, _ := r.ListTrashedJournals(ctx, testPhone); len(trashed) != 1 {
+		t.Errorf("purged a journal trashed after the cutoff")
+	}
+	if err := r.PurgeTrash(ctx, time.Now().UTC().Add(time.Hour)); err != nil {
+		t.Fatalf("failed to purge: %s", err)
+	}
+	if trashed, _ := r.ListTrashedJournals(ctx, testPhone); len(trashed) != 0 {
+		t.Errorf("got %d trashed journals after purging, want 0", len(trashed))
+	}
+	if err := r.RestoreJournal(ctx, testPhone, j.JournalId); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v restoring a purged journal, want ErrNotFound", err)
+	}
+	counts, err := r.ListTagCounts(ctx, testPhone)
+	if err != nil {
+		t.Fatalf("failed to count tags: %s", err)
+	}
+	for _, c := range counts {
+		if c.Count != 0 {
+			t.Errorf("purged journal still counted in tag %s", c.Name)
+		}
+	}
+}
+
+func testTags(t *testing.T, ctx context.Context, r Repository) {
+	a := insertTestJournal(t, ctx, r, "", "a")
+	b := insertTestJournal(t, ctx, r, "", "b")
+	if err := r.AddJournalTags(ctx, testPhone, a.JournalId, []string{"work", "gym"}, "manual"); err != nil {
+		t.Fatalf("failed to tag: %s", err)
+	}
+	//adding one twice, from another source, still counts the journal once
+	if err := r.AddJournalTags(ctx, testPhone, a.JournalId, []string{"work"}, "hashtag"); err != nil {
+		t.Fatalf("failed to tag: %s", err)
+	}
+	if err := r.AddJournalTags(ctx, testPhone, b.JournalId, []string{"work"}, "manual"); err != nil {
+		t.Fatalf("failed to tag: %s", err)
+	}
+
+	got, err := r.GetJournal(ctx, testPhone, a.JournalId)
+	if err != nil {
+		t.Fatalf("failed to get journal: %s", err)
+	}
+	if fmt.Sprint(got.Tags) != "[gym work]" {
+		t.Errorf("got tags %v, want [gym work]", got.Tags)
+	}
+
+	counts, err := r.ListTagCounts(ctx, testPhone)
+	if err != nil {
+		t.Fatalf("failed to count tags: %s", err)
+	}
+	byName := map[string]int32{}
+	for _, c := range counts {
+		byName[c.Name] = c.Count
+	}
+	if byName["work"] != 2 || byName["gym"] != 1 {
+		t.Errorf("got tag counts %v, want work 2 and gym 1", byName)
+	}
+
+	tagged, _, err := r.ListJournals(ctx, testPhone, &Page{Limit: 10}, "gym", nil)
+	if err != nil {
+		t.Fatalf("failed to list by tag: %s", err)
+	}
+	if len(tagged) != 1 || tagged[0].JournalId != a.JournalId {
+		t.Errorf("got %d journals tagged gym, want only %s", len(tagged), a.JournalId)
+	}
+
+	if err := r.DeleteJournalTags(ctx, a.JournalId, "manual"); err != nil {
+		t.Fatalf("failed to delete tags: %s", err)
+	}
+	if got, _ = r.GetJournal(ctx, testPhone, a.JournalId); fmt.Sprint(got.Tags) != "[work]" {
+		t.Errorf("got tags %v after deleting the manual ones, want the hashtag's [work]", got.Tags)
+	}
+}
+
+func testInTxRollback(t *testing.T, ctx context.Context, r Repository) {
+	failed := errors.New("failed")
+	outside := &pb.User{PhoneNumber: "+15555550101", Name: "outside"}
+	var rolledBack *pb.Journal
+	err := r.InTx(ctx, func(tx Repository) error {
+		//a write that isn't part of the transaction survives its rollback
+		if err := r.InsertUser(ctx, outside); err != nil {
+			return err
+		}
+		rolledBack = &pb.Journal{PhoneNumber: testPhone, Entry: "rolled back"}
+		if err := tx.InsertJournal(ctx, rolledBack, ""); err != nil {
+			return err
+		}
+		return failed
+	})
+	if err != failed {
+		t.Fatalf("got %v from InTx, want fn's error", err)
+	}
+	if _, err := r.GetJournal(ctx, testPhone, rolledBack.JournalId); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v getting a rolled back journal, want ErrNotFound", err)
+	}
+	if _, err := r.GetUser(ctx, outside.PhoneNumber); err != nil {
+		t.Errorf("rollback lost a write made outside the transaction: %s", err)
+	}
+
+	//a nested InTx is part of the outer one, and doesn't deadlock
+	var committed *pb.Journal
+	err = r.InTx(ctx, func(tx Repository) error {
+		return tx.InTx(ctx, func(nested Repository) error {
+			committed = &pb.Journal{PhoneNumber: testPhone, Entry: "committed"}
+			return nested.InsertJournal(ctx, committed, "")
+		})
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %s", err)
+	}
+	if _, err := r.GetJournal(ctx, testPhone, committed.JournalId); err != nil {
+		t.Errorf("failed to get a committed journal: %s", err)
+	}
+
+	err = r.InTx(ctx, func(tx Repository) error {
+		if err := tx.InTx(ctx, func(nested Repository) error {
+			return nested.DeleteJournal(ctx, testPhone, committed.JournalId)
+		}); err != nil {
+			return err
+		}
+		return failed
+	})
+	if err != failed {
+		t.Fatalf("got %v from InTx, want fn's error", err)
+	}
+	if _, err := r.GetJournal(ctx, testPhone, committed.JournalId); err != nil {
+		t.Errorf("the outer rollback didn't undo the nested delete: %s", err)
+	}
+}
+
+func testFailedLoginAttempts(t *testing.T, ctx context.Context, r Repository) {
+	const ip = "192.0.2.1"
+	since := time.Now().UTC().Add(-time.Hour)
+	count := func(column, value string) int {
+		t.Helper()
+		n, last, err := r.CountFailedLoginAttempts(ctx, "login", column, value, since)
+		if err != nil {
+			t.Fatalf("failed to count by %s: %s", column, err)
+		}
+		if n > 0 && last.IsZero() {
+			t.Errorf("got %d failures by %s with no latest time", n, column)
+		}
+		return n
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := r.InsertLoginAttempt(ctx, "login", testPhone, ip, false); err != nil {
+			t.Fatalf("failed to insert attempt: %s", err)
+		}
+	}
+	if err := r.InsertLoginAttempt(ctx, "otp", testPhone, ip, false); err != nil {
+		t.Fatalf("failed to insert attempt: %s", err)
+	}
+	if n := count("phone_number", testPhone); n != 3 {
+		t.Errorf("got %d failures by phone number, want 3", n)
+	}
+	if n := count("ip", ip); n != 3 {
+		t.Errorf("got %d failures by ip, want 3", n)
+	}
+
+	//let the success sort after the failures, timestamps are to the microsecond
+	time.Sleep(2 * time.Millisecond)
+	if err := r.InsertLoginAttempt(ctx, "login", testPhone, ip, true); err != nil {
+		t.Fatalf("failed to insert attempt: %s", err)
+	}
+	if n := count("phone_number", testPhone); n != 0 {
+		t.Errorf("got %d failures by phone number after a success, want 0", n)
+	}
+	if n := count("ip", ip); n != 3 {
+		t.Errorf("got %d failures by ip after a success, want them kept", n)
+	}
+	if _, _, err := r.CountFailedLoginAttempts(ctx, "login", "name", "x", since); err == nil {
+		t.Errorf("counted by an unhandled column")
+	}
+}
+
+func testClaimLoginAttempts(t *testing.T, ctx context.Context, r Repository) {
+	const ip = "192.0.2.1"
+	since := time.Now().UTC().Add(-time.Hour)
+	errThrottled := errors.New("throttled")
+	check := func(column string, n int, last time.Time) error {
+		if n >= 3 {
+			return errThrottled
+		}
+		return nil
+	}
+
+	//every claim counts the ones before it, however many race
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	claimed := []string{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			attemptID, err := r.ClaimLoginAttempt(ctx, "login", testPhone, ip, since, check)
+			if errors.Cause(err) == errThrottled {
+				return
+			} else if err != nil {
+				t.Errorf("failed to claim attempt: %s", err)
+				return
+			}
+			mu.Lock()
+			claimed = append(claimed, attemptID)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if len(claimed) != 3 {
+		t.Fatalf("got %d claims, want 3", len(claimed))
+	}
+	if n, _, err := r.CountFailedLoginAttempts(ctx, "login", "phone_number", testPhone, since); err != nil || n != 3 {
+		t.Fatalf("got %d failures, %v, want the claims counted as 3", n, err)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	if err := r.SucceedLoginAttempt(ctx, claimed[0]); err != nil {
+		t.Fatalf("failed to succeed attempt: %s", err)
+	}
+	if _, err := r.ClaimLoginAttempt(ctx, "login", testPhone, "", since, check); err != nil {
+		t.Errorf("claim by phone number after a success: %s", err)
+	}
+	if n, _, err := r.CountFailedLoginAttempts(ctx, "login", "ip", ip, since); err != nil || n != 2 {
+		t.Errorf("got %d failures by ip, %v, want the other 2 claims kept", n, err)
+	}
+	if err := r.SucceedLoginAttempt(ctx, "missing"); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v succeeding a missing attempt, want ErrNotFound", err)
+	}
+}
+
+func testLoginCodes(t *testing.T, ctx context.Context, r Repository) {
+	id, err := r.InsertLoginCode(ctx, testPhone, "hash", time.Minute)
+	if err != nil {
+		t.Fatalf("failed to insert code: %s", err)
+	}
+	if code, err := r.GetLoginCode(ctx, id); err != nil || code.CodeHash != "hash" {
+		t.Fatalf("got %v and %v getting the code", code, err)
+	}
+	if err := r.UseLoginCode(ctx, id); err != nil {
+		t.Fatalf("failed to use code: %s", err)
+	}
+	if err := r.UseLoginCode(ctx, id); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v using a code twice, want ErrNotFound", err)
+	}
+	if _, err := r.GetLoginCode(ctx, id); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v getting a used code, want ErrNotFound", err)
+	}
+
+	id, err = r.InsertLoginCode(ctx, testPhone, "hash", time.Minute)
+	if err != nil {
+		t.Fatalf("failed to insert code: %s", err)
+	}
+	for i := 1; i <= 3; i++ {
+		if used, err := r.MissLoginCode(ctx, id, 3); err != nil || used != (i == 3) {
+			t.Fatalf("got %v and %v for miss %d of 3", used, err, i)
+		}
+	}
+	if _, err := r.GetLoginCode(ctx, id); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v getting a code missed too often, want ErrNotFound", err)
+	}
+	if _, err := r.MissLoginCode(ctx, id, 3); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v missing a used code, want ErrNotFound", err)
+	}
+}
+
+func testSealedRows(t *testing.T, ctx context.Context, r Repository) {
+	j := insertTestJournal(t, ctx, r, "", "plain")
+	rows, err := r.ListUnsealedRows(ctx, "enc:", testPhone, "", 10)
+	if err != nil || len(rows) != 1 || rows[0].ID != j.JournalId {
+		t.Fatalf("got %v and %v listing unsealed rows, want the journal", rows, err)
+	}
+	row := rows[0]
+
+	//an edit after listing is kept
+	j.Entry = "edited"
+	if err := r.UpdateJournalEntry(ctx, j, ""); err != nil {
+		t.Fatalf("failed to edit: %s", err)
+	}
+	if err := r.UpdateSealedRow(ctx, row, []string{"enc:title", "enc:plain"}, ""); errors.Cause(err) != ErrNotFound {
+		t.Fatalf("got %v sealing an edited row, want ErrNotFound", err)
+	}
+	if got, err := r.GetJournal(ctx, testPhone, j.JournalId); err != nil || got.Entry != "edited" {
+		t.Fatalf("got %v and %v, want the edit kept", got, err)
+	}
+
+	rows, err = r.ListUnsealedRows(ctx, "enc:", testPhone, "", 10)
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("got %v and %v relisting", rows, err)
+	}
+	if err := r.UpdateSealedRow(ctx, rows[0], []string{"", "enc:edited"}, "hash"); err != nil {
+		t.Fatalf("failed to seal: %s", err)
+	}
+	if rows, err := r.ListUnsealedRows(ctx, "enc:", testPhone, "", 10); err != nil || len(rows) != 0 {
+		t.Errorf("got %v and %v after sealing, want none", rows, err)
+	}
+}
+
+func testJournalTerms(t *testing.T, ctx context.Context, r Repository) {
+	run := insertTestJournal(t, ctx, r, "", "run run")
+	both := insertTestJournal(t, ctx, r, "", "run swim")
+	trashed := insertTestJournal(t, ctx, r, "", "run")
+	for _, terms := range []struct {
+		j     *pb.Journal
+		terms map[string]int
+	}{
+		{run, map[string]int{"run": 2}},
+		{both, map[string]int{"run": 1, "swim": 1}},
+		{trashed, map[string]int{"run": 1}},
+	} {
+		if err := r.SetJournalTerms(ctx, testPhone, terms.j.JournalId, terms.terms); err != nil {
+			t.Fatalf("failed to set terms: %s", err)
+		}
+	}
+	if err := r.DeleteJournal(ctx, testPhone, trashed.JournalId); err != nil {
+		t.Fatalf("failed to trash: %s", err)
+	}
+
+	found, err := r.SearchJournals(ctx, testPhone, []string{"run", "swim"}, 10)
+	if err != nil || len(found) != 2 {
+		t.Fatalf("got %v and %v searching, want the two untrashed journals", found, err)
+	}
+	if found, err := r.SearchJournals(ctx, testPhone, []string{"run"}, 1); err != nil || len(found) != 1 || found[0].JournalId != run.JournalId {
+		t.Errorf("got %v and %v, want the journal with the most occurrences", found, err)
+	}
+	if found, err := r.SearchJournals(ctx, "+15555550199", []string{"run"}, 10); err != nil || len(found) != 0 {
+		t.Errorf("got %v and %v searching another user's terms", found, err)
+	}
+
+	listed, _, err := r.ListJournals(ctx, testPhone, &Page{Limit: 10}, "", []string{"run", "swim", "swim"})
+	if err != nil || len(listed) != 1 || listed[0].JournalId != both.JournalId {
+		t.Errorf("got %v and %v listing by every term, want only the journal with both", listed, err)
+	}
+
+	//replacing drops the old terms
+	if err := r.SetJournalTerms(ctx, testPhone, both.JournalId, map[string]int{"bike": 1}); err != nil {
+		t.Fatalf("failed to replace terms: %s", err)
+	}
+	if found, err := r.SearchJournals(ctx, testPhone, []string{"swim"}, 10); err != nil || len(found) != 0 {
+		t.Errorf("got %v and %v after replacing the terms", found, err)
+	}
+	if n, err := r.CountJournals(ctx, testPhone); err != nil || n != 2 {
+		t.Errorf("counted %d and %v, want 2", n, err)
+	}
+}
+
+func testDeadLetters(t *testing.T, ctx context.Context, r Repository) {
+	sent := &pb.Communication{From: "+15555550000", To: testPhone, Message: "sent"}
+	failed := &pb.Communication{From: "+15555550000", To: testPhone, Message: "failed", Status: CommsFailed, Error: "twilio is down"}
+	for _, c := range []*pb.Communication{sent, failed} {
+		if err := r.InsertCommunication(ctx, c); err != nil {
+			t.Fatalf("failed to insert communication: %s", err)
+		}
+	}
+	if sent.Status != CommsSent {
+		t.Errorf("got status %q, want a default of %q", sent.Status, CommsSent)
+	}
+
+	dead, err := r.ListDeadLetters(ctx, testPhone)
+	if err != nil || len(dead) != 1 || dead[0].CommsId != failed.CommsId || dead[0].Error != "twilio is down" {
+		t.Fatalf("got %v and %v listing dead letters, want the failed send", dead, err)
+	}
+	if dead, err := r.ListDeadLetters(ctx, "+15555550199"); err != nil || len(dead) != 0 {
+		t.Errorf("got %v and %v for another number", dead, err)
+	}
+
+	if _, err := r.ClaimDeadLetter(ctx, sent.CommsId); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v claiming a sent message, want ErrNotFound", err)
+	}
+	claimed, err := r.ClaimDeadLetter(ctx, failed.CommsId)
+	if err != nil || claimed.Message != "failed" || claimed.Status != CommsReplayed {
+		t.Fatalf("got %v and %v claiming the dead letter", claimed, err)
+	}
+	if _, err := r.ClaimDeadLetter(ctx, failed.CommsId); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v claiming it twice, want ErrNotFound", err)
+	}
+	if dead, err := r.ListDeadLetters(ctx, ""); err != nil || len(dead) != 0 {
+		t.Errorf("got %v and %v after replaying, want none", dead, err)
+	}
+}
+
+func testAdvanceUserNotification(t *testing.T, ctx context.Context, r Repository) {
+	due := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	next := due.Add(24 * time.Hour)
+	for _, up := range []*pb.UserNotification{
+		{PhoneNumber: testPhone, NotificationId: seedNotifications[0].NotificationId, NextNotificationTime: formatTime(due), Frequency: "1d"},
+		{PhoneNumber: testPhone, NotificationId: seedNotifications[3].NotificationId, NextNotificationTime: formatTime(due)},
+	} {
+		if err := r.InsertUserNotification(ctx, up); err != nil {
+			t.Fatalf("failed to insert user notification: %s", err)
+		}
+	}
+	recurring, once := seedNotifications[0].NotificationId, seedNotifications[3].NotificationId
+
+	if err := r.AdvanceUserNotification(ctx, testPhone, recurring, due, next); err != nil {
+		t.Fatalf("failed to advance: %s", err)
+	}
+	//a second run claiming the same send loses
+	if err := r.AdvanceUserNotification(ctx, testPhone, recurring, due, next); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v advancing twice, want ErrNotFound", err)
+	}
+	if up, err := r.GetUserNotification(ctx, testPhone, recurring); err != nil || normalizeTime(up.NextNotificationTime) != formatTime(next) {
+		t.Errorf("got %v and %v, want it moved on to %s", up, err, formatTime(next))
+	}
+
+	if err := r.AdvanceUserNotification(ctx, testPhone, once, due, time.Time{}); err != nil {
+		t.Fatalf("failed to advance a one time notification: %s", err)
+	}
+	if _, err := r.GetUserNotification(ctx, testPhone, once); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v getting a sent one time notification, want it deleted", err)
+	}
+	if err := r.AdvanceUserNotification(ctx, testPhone, once, due, time.Time{}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("got %v advancing a deleted notification, want ErrNotFound", err)
+	}
+}
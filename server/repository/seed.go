@@ -0,0 +1,17 @@
+package repository
+
+import (
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+)
+
+// seedNotifications are the rows the migrations insert, the app looks the
+// registration, lockout and login code ones up by id.  the memory store, which
+// has no migrations, starts with them.
+var seedNotifications = []*pb.Notification{
+	{NotificationId: "0939c423-f3e2-468f-8e34-8e5b0c5391bc", Type: "reminder", Template: "hello world"},
+	{NotificationId: "deaabd59-0d15-4f44-a3a8-1e3f920a3710", Name: "register", Type: "registration", Template: "respond with 'reg' to register!"},
+	{NotificationId: "81a36dd3-8301-410c-af35-0b2a87cdd921", Name: "register-ack", Type: "registration", Template: "{{.Name}}, you successfully registered :)"},
+	{NotificationId: "7b1ced70-a2a0-40c5-8aa5-1cc5cff3b04b", Type: "prompt", Template: "What did you have for lunch?"},
+	{NotificationId: "4f9b3c1e-8f1a-4a53-9d0a-6c2f2f7e9b12", Name: "lockout", Type: "alert", Template: "Your notify account is locked for {{.Minutes}} minutes after too many failed sign in attempts. If this wasn't you, nothing was accessed and you can sign in again after that."},
+	{NotificationId: "c3d1f0a2-6b7e-4f25-8a4e-1d9b7c2e5f30", Name: "login-code", Type: "alert", Template: "{{.Code}} is your notify login code. It expires in {{.Minutes}} minutes."},
+}
@@ -0,0 +1,552 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// dialect is what differs between the sql stores.  queries are written for
+// mysql and rewritten for the others.
+type dialect struct {
+	name    string
+	rewrite *strings.Replacer
+	// namedLocks is whether the database has GET_LOCK, which migrations
+	// take so only one process runs them at a time
+	namedLocks bool
+	// lockingReads is whether the database has SELECT ... FOR UPDATE.
+	// sqlite doesn't need it, its transactions take the write lock when
+	// they begin
+	lockingReads bool
+}
+
+// conn is a *sql.DB, or a *sql.Tx inside InTx.
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sqlRepository implements Repository on database/sql, for mysql and sqlite.
+type sqlRepository struct {
+	db      *sql.DB
+	conn    conn
+	dialect dialect
+}
+
+func newSQLRepository(db *sql.DB, d dialect) *sqlRepository {
+	return &sqlRepository{db: db, conn: db, dialect: d}
+}
+
+func (r *sqlRepository) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query = r.dialect.rewrite.Replace(query)
+	ctx, span := r.startSpan(ctx, query)
+	res, err := r.conn.ExecContext(ctx, query, args...)
+	endSpan(span, err)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to exec")
+	}
+	return res, nil
+}
+
+func (r *sqlRepository) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query = r.dialect.rewrite.Replace(query)
+	ctx, span := r.startSpan(ctx, query)
+	rows, err := r.conn.QueryContext(ctx, query, args...)
+	endSpan(span, err)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+	return rows, nil
+}
+
+func (r *sqlRepository) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query = r.dialect.rewrite.Replace(query)
+	ctx, span := r.startSpan(ctx, query)
+	row := r.conn.QueryRowContext(ctx, query, args...)
+	endSpan(span, row.Err())
+	return row
+}
+
+// placeholders returns "?,?,..." for n args.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// where appends the page's conditions on the created and id columns.
+func (p *Page) where(createdCol, idCol string, clauses []string, args []interface{}) ([]string, []interface{}) {
+	if p.CreatedAfter != "" {
+		clauses = append(clauses, createdCol+" >= ?")
+		args = append(args, normalizeTime(p.CreatedAfter))
+	}
+	if p.CreatedBefore != "" {
+		clauses = append(clauses, createdCol+" < ?")
+		args = append(args, normalizeTime(p.CreatedBefore))
+	}
+	if p.Cursor != "" {
+		created, id, _ := DecodeCursor(p.Cursor)
+		clauses = append(clauses, fmt.Sprintf("(%[1]s < ? OR (%[1]s = ? AND %[2]s < ?))", createdCol, idCol))
+		args = append(args, created, created, id)
+	}
+	return clauses, args
+}
+
+// orderLimit sorts newest first and fetches one extra row, so the caller
+// knows whether there is a next page.
+func (p *Page) orderLimit(createdCol, idCol string) string {
+	return fmt.Sprintf("ORDER BY %s DESC, %s DESC LIMIT %d", createdCol, idCol, p.Limit+1)
+}
+
+func (r *sqlRepository) Now(ctx context.Context) (time.Time, error) {
+	now := ""
+	if err := r.queryRow(ctx, `SELECT NOW(6)`).Scan(&now); err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to scan")
+	}
+	t, err := time.Parse("2006-01-02 15:04:05", now)
+	return t, errors.Wrap(err, "failed to parse now")
+}
+
+func (r *sqlRepository) InTx(ctx context.Context, fn func(Repository) error) error {
+	if _, ok := r.conn.(*sql.Tx); ok {
+		return fn(r)
+	}
+	ctx, span := tracer.Start(ctx, "sql transaction", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", r.dialect.name)))
+	err := r.inTx(ctx, fn)
+	endSpan(span, err)
+	return err
+}
+
+func (r *sqlRepository) inTx(ctx context.Context, fn func(Repository) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin txn")
+	}
+	defer tx.Rollback()
+	if err := fn(&sqlRepository{db: r.db, conn: tx, dialect: r.dialect}); err != nil {
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "failed to commit")
+}
+
+func (r *sqlRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+func (r *sqlRepository) Stats() sql.DBStats {
+	return r.db.Stats()
+}
+
+func (r *sqlRepository) Close() error {
+	return r.db.Close()
+}
+
+func (r *sqlRepository) InsertUser(ctx context.Context, user *pb.User) error {
+	_, err := r.exec(ctx, `
+		INSERT INTO users (phone_number, name, hashword, birthday, verified, created, updated)
+		VALUES (?, ?, ?, ?, 0, NOW(6), NOW(6))
+	`, user.PhoneNumber, user.Name, user.Password, user.Birthday)
+	return err
+}
+
+func (r *sqlRepository) GetUser(ctx context.Context, phoneNumber string) (*pb.User, error) {
+	rows, err := r.query(ctx, `SELECT hashword,name,birthday,verified,session_id,csrf_token,role,two_factor FROM users WHERE phone_number=?`, phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, errors.Wrapf(ErrNotFound, "number '%s'", phoneNumber)
+	}
+	user := &pb.User{PhoneNumber: phoneNumber}
+	if err := rows.Scan(&user.Password, &user.Name, &user.Birthday, &user.Verified, &user.SessionId, &user.CsrfToken, &user.Role, &user.TwoFactor); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+	return user, nil
+}
+
+func (r *sqlRepository) UpdateUser(ctx context.Context, user *pb.User) error {
+	_, err := r.exec(ctx, `
+		UPDATE users SET verified=?,session_id=?,csrf_token=?,two_factor=?,updated=NOW(6)
+		WHERE phone_number=?
+	`, user.Verified, user.SessionId, user.CsrfToken, user.TwoFactor, user.PhoneNumber)
+	return err
+}
+
+func (r *sqlRepository) InsertApiToken(ctx context.Context, t *pb.ApiToken, tokenHash string) error {
+	t.TokenId = uuid.NewV4().String()
+	_, err := r.exec(ctx, `
+		INSERT INTO api_tokens (token_id, phone_number, name, token_hash, created)
+		VALUES (?, ?, ?, ?, NOW(6))
+	`, t.TokenId, t.PhoneNumber, t.Name, tokenHash)
+	return err
+}
+
+func (r *sqlRepository) ListApiTokens(ctx context.Context, phoneNumber string) ([]*pb.ApiToken, error) {
+	rows, err := r.query(ctx, `
+		SELECT token_id,phone_number,name,created,IFNULL(last_used, '')
+		FROM api_tokens
+		WHERE phone_number=?
+		ORDER BY created DESC`, phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tokens := []*pb.ApiToken{}
+	for rows.Next() {
+		t := &pb.ApiToken{}
+		if err := rows.Scan(&t.TokenId, &t.PhoneNumber, &t.Name, &t.Created, &t.LastUsed); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, nil
+}
+
+func (r *sqlRepository) GetApiTokenUser(ctx context.Context, tokenHash string) (*pb.User, error) {
+	rows, err := r.query(ctx, `
+		SELECT t.token_id,u.phone_number,u.name,u.birthday,u.verified,u.role
+		FROM api_tokens t, users u
+		WHERE t.token_hash=?
+		AND t.phone_number=u.phone_number`, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, errors.Wrap(ErrNotFound, "api token")
+	}
+	var tokenID string
+	user := &pb.User{}
+	if err := rows.Scan(&tokenID, &user.PhoneNumber, &user.Name, &user.Birthday, &user.Verified, &user.Role); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+	rows.Close()
+
+	if _, err := r.exec(ctx, `UPDATE api_tokens SET last_used=NOW(6) WHERE token_id=?`, tokenID); err != nil {
+		return nil, errors.Wrap(err, "failed to touch api token")
+	}
+	return user, nil
+}
+
+func (r *sqlRepository) DeleteApiToken(ctx context.Context, phoneNumber, tokenID string) error {
+	_, err := r.exec(ctx, `
+		DELETE FROM api_tokens
+		WHERE phone_number=?
+		AND token_id=?
+	`, phoneNumber, tokenID)
+	return err
+}
+
+func (r *sqlRepository) InsertLoginAttempt(ctx context.Context, kind, phoneNumber, ip string, success bool) error {
+	_, err := r.exec(ctx, `
+		INSERT INTO login_attempts (attempt_id, kind, phone_number, ip, success, created)
+		VALUES (?, ?, ?, ?, ?, NOW(6))
+	`, uuid.NewV4().String(), kind, phoneNumber, ip, success)
+	return err
+}
+
+func (r *sqlRepository) CountFailedLoginAttempts(ctx context.Context, kind, column, value string, since time.Time) (int, time.Time, error) {
+	return r.countFailedLoginAttempts(ctx, kind, column, value, since, false)
+}
+
+// countFailedLoginAttempts locks the rows it counts if lock and the database
+// has locking reads, which then serializes claims on them.
+func (r *sqlRepository) countFailedLoginAttempts(ctx context.Context, kind, column, value string, since time.Time, lock bool) (int, time.Time, error) {
+	if column != "phone_number" && column != "ip" {
+		return 0, time.Time{}, fmt.Errorf("column '%s' is unhandled", column)
+	}
+	stmt := fmt.Sprintf(`
+		SELECT COUNT(*), IFNULL(MAX(created), '')
+		FROM login_attempts
+		WHERE kind=? AND %s=? AND success=0
+		AND created > ?`, column)
+	args := []interface{}{kind, value, formatTime(since)}
+	if column == "phone_number" {
+		stmt += `
+		AND created > IFNULL((
+			SELECT MAX(created) FROM login_attempts
+			WHERE kind=? AND phone_number=? AND success=1
+		), '1970-01-01')`
+		args = append(args, kind, value)
+	}
+	if lock && r.dialect.lockingReads {
+		stmt += `
+		FOR UPDATE`
+	}
+	rows, err := r.query(ctx, stmt, args...)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return 0, time.Time{}, nil
+	}
+
+	var n int
+	var lastStr string
+	if err := rows.Scan(&n, &lastStr); err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "failed to scan")
+	}
+	if n == 0 {
+		return 0, time.Time{}, nil
+	}
+	last, err := time.Parse("2006-01-02 15:04:05", lastStr)
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "failed to parse last attempt")
+	}
+	return n, last, nil
+}
+
+func (r *sqlRepository) ClaimLoginAttempt(ctx context.Context, kind, phoneNumber, ip string, since time.Time, check func(column string, n int, last time.Time) error) (string, error) {
+	attemptID := uuid.NewV4().String()
+	err := r.InTx(ctx, func(tx Repository) error {
+		t := tx.(*sqlRepository)
+		for _, c := range []struct{ column, value string }{{"phone_number", phoneNumber}, {"ip", ip}} {
+			if c.value == "" {
+				continue
+			}
+			n, last, err := t.countFailedLoginAttempts(ctx, kind, c.column, c.value, since, true)
+			if err != nil {
+				return errors.Wrapf(err, "failed to count by %s", c.column)
+			}
+			if err := check(c.column, n, last); err != nil {
+				return err
+			}
+		}
+		_, err := t.exec(ctx, `
+			INSERT INTO login_attempts (attempt_id, kind, phone_number, ip, success, created)
+			VALUES (?, ?, ?, ?, 0, NOW(6))
+		`, attemptID, kind, phoneNumber, ip)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return attemptID, nil
+}
+
+func (r *sqlRepository) SucceedLoginAttempt(ctx context.Context, attemptID string) error {
+	res, err := r.exec(ctx, `UPDATE login_attempts SET success=1 WHERE attempt_id=?`, attemptID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.Wrapf(ErrNotFound, "login attempt '%s'", attemptID)
+	}
+	return nil
+}
+
+func (r *sqlRepository) InsertLoginCode(ctx context.Context, phoneNumber, codeHash string, lifetime time.Duration) (string, error) {
+	now, err := r.Now(ctx)
+	if err != nil {
+		return "", err
+	}
+	codeID := uuid.NewV4().String()
+	_, err = r.exec(ctx, `
+		INSERT INTO login_codes (code_id, phone_number, code_hash, used, expires, created)
+		VALUES (?, ?, ?, 0, ?, NOW(6))
+	`, codeID, phoneNumber, codeHash, formatTime(now.Add(lifetime)))
+	return codeID, err
+}
+
+func (r *sqlRepository) GetLoginCode(ctx context.Context, codeID string) (*LoginCode, error) {
+	rows, err := r.query(ctx, `
+		SELECT code_id,phone_number,code_hash
+		FROM login_codes
+		WHERE code_id=? AND used=0 AND expires > NOW(6)`, codeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, errors.Wrapf(ErrNotFound, "login code '%s'", codeID)
+	}
+	code := &LoginCode{}
+	if err := rows.Scan(&code.CodeID, &code.PhoneNumber, &code.CodeHash); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+	return code, nil
+}
+
+func (r *sqlRepository) UseLoginCode(ctx context.Context, codeID string) error {
+	res, err := r.exec(ctx, `UPDATE login_codes SET used=1 WHERE code_id=? AND used=0`, codeID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.Wrapf(ErrNotFound, "unused login code '%s'", codeID)
+	}
+	return nil
+}
+
+func (r *sqlRepository) MissLoginCode(ctx context.Context, codeID string, maxMisses int) (bool, error) {
+	//used is set before attempts, mysql would otherwise see the new count
+	res, err := r.exec(ctx, `
+		UPDATE login_codes
+		SET used=CASE WHEN attempts+1 >= ? THEN 1 ELSE 0 END, attempts=attempts+1
+		WHERE code_id=? AND used=0`, maxMisses, codeID)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return false, errors.Wrapf(ErrNotFound, "unused login code '%s'", codeID)
+	}
+	used := false
+	if err := r.queryRow(ctx, `SELECT used FROM login_codes WHERE code_id=?`, codeID).Scan(&used); err != nil {
+		return false, errors.Wrap(err, "failed to scan")
+	}
+	return used, nil
+}
+
+func (r *sqlRepository) InsertUserKey(ctx context.Context, k *UserKey) error {
+	_, err := r.exec(ctx, `
+		INSERT INTO user_keys (key_id, phone_number, master_key_id, wrapped_key, created)
+		VALUES (?, ?, ?, ?, NOW(6))
+	`, k.KeyID, k.PhoneNumber, k.MasterKeyID, k.WrappedKey)
+	return err
+}
+
+func (r *sqlRepository) GetUserKey(ctx context.Context, phoneNumber, keyID string) (*UserKey, error) {
+	k := &UserKey{KeyID: keyID, PhoneNumber: phoneNumber}
+	err := r.queryRow(ctx, `SELECT master_key_id, wrapped_key FROM user_keys WHERE key_id=? AND phone_number=?`, keyID, phoneNumber).
+		Scan(&k.MasterKeyID, &k.WrappedKey)
+	if err == sql.ErrNoRows {
+		return nil, errors.Wrapf(ErrNotFound, "data key '%s'", keyID)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+	return k, nil
+}
+
+func (r *sqlRepository) GetActiveUserKeyID(ctx context.Context, phoneNumber string) (string, error) {
+	keyID := ""
+	err := r.queryRow(ctx, `SELECT key_id FROM user_keys
+		WHERE phone_number=? AND retired IS NULL
+		ORDER BY created DESC LIMIT 1`, phoneNumber).Scan(&keyID)
+	if err != nil && err != sql.ErrNoRows {
+		return "", errors.Wrap(err, "failed to scan")
+	}
+	return keyID, nil
+}
+
+func (r *sqlRepository) ListUserKeysWrappedByOthers(ctx context.Context, masterKeyID string) ([]*UserKey, error) {
+	rows, err := r.query(ctx, `SELECT key_id, phone_number, master_key_id, wrapped_key FROM user_keys WHERE master_key_id<>?`, masterKeyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	keys := []*UserKey{}
+	for rows.Next() {
+		k := &UserKey{}
+		if err := rows.Scan(&k.KeyID, &k.PhoneNumber, &k.MasterKeyID, &k.WrappedKey); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (r *sqlRepository) UpdateUserKeyWrap(ctx context.Context, k *UserKey) error {
+	_, err := r.exec(ctx, `UPDATE user_keys SET master_key_id=?, wrapped_key=? WHERE key_id=?`, k.MasterKeyID, k.WrappedKey, k.KeyID)
+	return err
+}
+
+func (r *sqlRepository) RetireUserKeys(ctx context.Context, phoneNumber string) error {
+	_, err := r.exec(ctx, `UPDATE user_keys SET retired=NOW(6) WHERE phone_number=? AND retired IS NULL`, phoneNumber)
+	return err
+}
+
+func (r *sqlRepository) ListUnsealedRows(ctx context.Context, prefix, phoneNumber, inboundTo string, limit int) ([]*SealedRow, error) {
+	pattern := prefix + "%"
+	sealed := []*SealedRow{}
+	for _, t := range sealedColumns {
+		if len(sealed) >= limit {
+			break
+		}
+		pending := []string{}
+		args := []interface{}{}
+		for _, col := range t.cols {
+			pending = append(pending, fmt.Sprintf("(%[1]s <> '' AND %[1]s NOT LIKE ?)", col))
+			args = append(args, pattern)
+		}
+		clauses := []string{"(" + strings.Join(pending, " OR ") + ")"}
+		if phoneNumber != "" {
+			clauses = append(clauses, t.phoneCol+"=?")
+			args = append(args, phoneNumber)
+		}
+		if t.inbound {
+			clauses = append(clauses, "to_phone=?")
+			args = append(args, inboundTo)
+		}
+		selects := []string{}
+		for _, col := range t.cols {
+			selects = append(selects, "COALESCE("+col+", '')")
+		}
+
+		rows, err := r.query(ctx, fmt.Sprintf(`SELECT %s, %s, %s, %s FROM %s WHERE %s LIMIT %d`,
+			t.idCol, t.sealedCol, t.phoneCol, strings.Join(selects, ", "), t.table, strings.Join(clauses, " AND "), limit-len(sealed)), args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			row := &SealedRow{Table: t.table, Values: make([]string, len(t.cols)),
+				SealedTable: t.sealedTable, Columns: t.cols, Hashed: t.hashCol != ""}
+			dest := []interface{}{&row.ID, &row.SealedID, &row.PhoneNumber}
+			for i := range row.Values {
+				dest = append(dest, &row.Values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return nil, errors.Wrap(err, "failed to scan")
+			}
+			sealed = append(sealed, row)
+		}
+		rows.Close()
+	}
+	return sealed, nil
+}
+
+func (r *sqlRepository) UpdateSealedRow(ctx context.Context, row *SealedRow, sealed []string, hash string) error {
+	for _, t := range sealedColumns {
+		if t.table != row.Table {
+			continue
+		}
+		if len(row.Values) != len(t.cols) || len(sealed) != len(t.cols) {
+			return fmt.Errorf("%s has %d sealed columns, not %d", t.table, len(t.cols), len(sealed))
+		}
+		sets := []string{}
+		clauses := []string{t.idCol + "=?"}
+		args := []interface{}{}
+		for i, col := range t.cols {
+			sets = append(sets, col+"=?")
+			args = append(args, sealed[i])
+		}
+		if t.hashCol != "" {
+			sets = append(sets, t.hashCol+"=?")
+			args = append(args, hash)
+		}
+		args = append(args, row.ID)
+		for i, col := range t.cols {
+			clauses = append(clauses, "COALESCE("+col+", '')=?")
+			args = append(args, row.Values[i])
+		}
+		res, err := r.exec(ctx, fmt.Sprintf(`UPDATE %s SET %s WHERE %s`, t.table, strings.Join(sets, ", "), strings.Join(clauses, " AND ")), args...)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return errors.Wrapf(ErrNotFound, "unchanged %s '%s'", row.Table, row.ID)
+		}
+		return nil
+	}
+	return fmt.Errorf("table '%s' has no sealed columns", row.Table)
+}
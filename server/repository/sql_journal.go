@@ -0,0 +1,478 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+func (r *sqlRepository) InsertJournal(ctx context.Context, j *pb.Journal, contentHash string) error {
+	if j.JournalId == "" {
+		j.JournalId = uuid.NewV4().String()
+	}
+	created := sql.NullString{String: normalizeTime(j.Created), Valid: j.Created != ""}
+	_, err := r.exec(ctx, `
+		INSERT INTO journals (journal_id, comms_id, phone_number, title, entry, content_hash, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, IFNULL(?, NOW(6)), NOW(6))
+	`, j.JournalId, j.CommsId, j.PhoneNumber, j.Title, j.Entry, contentHash, created)
+	return err
+}
+
+// journalColumns are scanned by scanJournal.
+const journalColumns = `journal_id,comms_id,phone_number,title,entry,created,updated,IFNULL(deleted, '')`
+
+func scanJournal(rows *sql.Rows) (*pb.Journal, error) {
+	j := &pb.Journal{}
+	if err := rows.Scan(&j.JournalId, &j.CommsId, &j.PhoneNumber, &j.Title, &j.Entry, &j.Created, &j.Updated, &j.Deleted); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+	return j, nil
+}
+
+func (r *sqlRepository) listJournals(ctx context.Context, where string, args ...interface{}) ([]*pb.Journal, error) {
+	rows, err := r.query(ctx, `SELECT `+journalColumns+`
+		FROM journals
+		WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	journals := []*pb.Journal{}
+	for rows.Next() {
+		j, err := scanJournal(rows)
+		if err != nil {
+			return nil, err
+		}
+		journals = append(journals, j)
+	}
+	return journals, nil
+}
+
+func (r *sqlRepository) GetJournal(ctx context.Context, phoneNumber, journalID string) (*pb.Journal, error) {
+	journals, err := r.listJournals(ctx, `journal_id=? AND phone_number=? AND deleted IS NULL`, journalID, phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(journals) == 0 {
+		return nil, errors.Wrapf(ErrNotFound, "journal '%s'", journalID)
+	}
+	if err := r.attach(ctx, journals); err != nil {
+		return nil, err
+	}
+	return journals[0], nil
+}
+
+func (r *sqlRepository) LockJournal(ctx context.Context, phoneNumber, journalID string) (*pb.Journal, error) {
+	where := `journal_id=? AND phone_number=? AND deleted IS NULL`
+	if r.dialect.lockingReads {
+		where += ` FOR UPDATE`
+	}
+	journals, err := r.listJournals(ctx, where, journalID, phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(journals) == 0 {
+		return nil, errors.Wrapf(ErrNotFound, "journal '%s'", journalID)
+	}
+	return journals[0], nil
+}
+
+func (r *sqlRepository) ListJournals(ctx context.Context, phoneNumber string, page *Page, tag string, terms []string) ([]*pb.Journal, string, error) {
+	clauses := []string{"phone_number=?", "deleted IS NULL"}
+	args := []interface{}{phoneNumber}
+	if tag != "" {
+		clauses = append(clauses, `journal_id IN (SELECT jt.journal_id FROM journal_tags jt, tags t
+			WHERE jt.tag_id=t.tag_id AND t.phone_number=? AND t.name=?)`)
+		args = append(args, phoneNumber, tag)
+	}
+	if terms = distinct(terms); len(terms) > 0 {
+		clauses = append(clauses, `journal_id IN (SELECT journal_id FROM journal_terms
+			WHERE phone_number=? AND term IN (`+placeholders(len(terms))+`)
+			GROUP BY journal_id HAVING COUNT(*)=?)`)
+		args = append(args, phoneNumber)
+		for _, t := range terms {
+			args = append(args, t)
+		}
+		args = append(args, len(terms))
+	}
+	clauses, args = page.where("created", "journal_id", clauses, args)
+
+	journals, err := r.listJournals(ctx, strings.Join(clauses, " AND ")+`
+		`+page.orderLimit("created", "journal_id"), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	nextCursor := ""
+	if len(journals) > page.Limit {
+		journals = journals[:page.Limit]
+		last := journals[len(journals)-1]
+		nextCursor = EncodeCursor(last.Created, last.JournalId)
+	}
+	if err := r.attach(ctx, journals); err != nil {
+		return nil, "", err
+	}
+	return journals, nextCursor, nil
+}
+
+// attach fills in the tags and attachments of each journal.
+func (r *sqlRepository) attach(ctx context.Context, journals []*pb.Journal) error {
+	if len(journals) == 0 {
+		return nil
+	}
+	byID := map[string]*pb.Journal{}
+	args := []interface{}{}
+	for _, j := range journals {
+		byID[j.JournalId] = j
+		args = append(args, j.JournalId)
+	}
+
+	rows, err := r.query(ctx, `SELECT DISTINCT jt.journal_id,t.name
+		FROM journal_tags jt, tags t
+		WHERE jt.tag_id=t.tag_id
+		AND jt.journal_id IN (`+placeholders(len(args))+`)
+		ORDER BY t.name`, args...)
+	if err != nil {
+		return errors.Wrap(err, "failed to get tags")
+	}
+	for rows.Next() {
+		journalID, name := "", ""
+		if err := rows.Scan(&journalID, &name); err != nil {
+			rows.Close()
+			return errors.Wrap(err, "failed to scan")
+		}
+		if j, ok := byID[journalID]; ok {
+			j.Tags = append(j.Tags, name)
+		}
+	}
+	rows.Close()
+
+	rows, err = r.query(ctx, `SELECT attachment_id,journal_id,content_type,size,created
+		FROM attachments
+		WHERE journal_id IN (`+placeholders(len(args))+`)
+		ORDER BY created`, args...)
+	if err != nil {
+		return errors.Wrap(err, "failed to get attachments")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a := &pb.Attachment{}
+		if err := rows.Scan(&a.AttachmentId, &a.JournalId, &a.ContentType, &a.Size, &a.Created); err != nil {
+			return errors.Wrap(err, "failed to scan")
+		}
+		if j, ok := byID[a.JournalId]; ok {
+			j.Attachments = append(j.Attachments, a)
+		}
+	}
+	return nil
+}
+
+func (r *sqlRepository) UpdateJournalEntry(ctx context.Context, j *pb.Journal, contentHash string) error {
+	_, err := r.exec(ctx, `
+		UPDATE journals SET updated=NOW(6), entry=?, content_hash=?
+		WHERE journal_id=? AND phone_number=?
+	`, j.Entry, contentHash, j.JournalId, j.PhoneNumber)
+	return err
+}
+
+func (r *sqlRepository) DeleteJournal(ctx context.Context, phoneNumber, journalID string) error {
+	_, err := r.exec(ctx, `
+		UPDATE journals SET deleted=NOW(6)
+		WHERE journal_id=? AND phone_number=? AND deleted IS NULL
+	`, journalID, phoneNumber)
+	return err
+}
+
+func (r *sqlRepository) RestoreJournal(ctx context.Context, phoneNumber, journalID string) error {
+	res, err := r.exec(ctx, `
+		UPDATE journals SET deleted=NULL
+		WHERE journal_id=? AND phone_number=? AND deleted IS NOT NULL
+	`, journalID, phoneNumber)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.Wrapf(ErrNotFound, "trashed journal '%s'", journalID)
+	}
+	return nil
+}
+
+func (r *sqlRepository) ListTrashedJournals(ctx context.Context, phoneNumber string) ([]*pb.Journal, error) {
+	return r.listJournals(ctx, `phone_number=? AND deleted IS NOT NULL
+		ORDER BY deleted DESC`, phoneNumber)
+}
+
+func (r *sqlRepository) JournalExists(ctx context.Context, phoneNumber, contentHash, created string) (bool, error) {
+	count := 0
+	err := r.queryRow(ctx, `SELECT COUNT(*) FROM journals
+		WHERE phone_number=? AND content_hash=? AND DATE(created)=DATE(?) AND deleted IS NULL
+	`, phoneNumber, contentHash, normalizeTime(created)).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to scan")
+	}
+	return count > 0, nil
+}
+
+func (r *sqlRepository) InsertJournalImport(ctx context.Context, phoneNumber, format string, report *pb.ImportReport) error {
+	_, err := r.exec(ctx, `
+		INSERT INTO journal_imports (import_id, phone_number, format, imported, duplicates, invalid, created)
+		VALUES (?, ?, ?, ?, ?, ?, NOW(6))
+	`, report.ImportId, phoneNumber, format, report.Imported, report.Duplicates, report.Invalid)
+	return err
+}
+
+// termBatchSize keeps a long journal's terms under the placeholder limits.
+const termBatchSize = 500
+
+func (r *sqlRepository) SetJournalTerms(ctx context.Context, phoneNumber, journalID string, terms map[string]int) error {
+	rows := []interface{}{}
+	for term, n := range terms {
+		rows = append(rows, journalID, phoneNumber, term, n)
+	}
+	return r.InTx(ctx, func(tx Repository) error {
+		sqlTx := tx.(*sqlRepository)
+		if _, err := sqlTx.exec(ctx, `DELETE FROM journal_terms WHERE journal_id=?`, journalID); err != nil {
+			return err
+		}
+		for len(rows) > 0 {
+			batch := rows
+			if len(batch) > termBatchSize*4 {
+				batch = batch[:termBatchSize*4]
+			}
+			rows = rows[len(batch):]
+			values := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?), ", len(batch)/4), ", ")
+			if _, err := sqlTx.exec(ctx, `INSERT INTO journal_terms (journal_id, phone_number, term, occurrences)
+				VALUES `+values, batch...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *sqlRepository) SearchJournals(ctx context.Context, phoneNumber string, terms []string, limit int) ([]*pb.Journal, error) {
+	terms = distinct(terms)
+	if len(terms) == 0 {
+		return []*pb.Journal{}, nil
+	}
+	args := []interface{}{phoneNumber}
+	for _, t := range terms {
+		args = append(args, t)
+	}
+	rows, err := r.query(ctx, `SELECT jt.journal_id
+		FROM journal_terms jt, journals j
+		WHERE jt.journal_id=j.journal_id AND j.deleted IS NULL
+		AND jt.phone_number=? AND jt.term IN (`+placeholders(len(terms))+`)
+		GROUP BY jt.journal_id
+		ORDER BY SUM(jt.occurrences) DESC
+		LIMIT `+strconv.Itoa(limit), args...)
+	if err != nil {
+		return nil, err
+	}
+	ids := []interface{}{}
+	for rows.Next() {
+		id := ""
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if len(ids) == 0 {
+		return []*pb.Journal{}, nil
+	}
+
+	journals, err := r.listJournals(ctx, `journal_id IN (`+placeholders(len(ids))+`)`, ids...)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.attach(ctx, journals); err != nil {
+		return nil, err
+	}
+	return journals, nil
+}
+
+func (r *sqlRepository) CountJournals(ctx context.Context, phoneNumber string) (int, error) {
+	count := 0
+	err := r.queryRow(ctx, `SELECT COUNT(*) FROM journals WHERE phone_number=? AND deleted IS NULL`, phoneNumber).Scan(&count)
+	return count, errors.Wrap(err, "failed to scan")
+}
+
+func (r *sqlRepository) InsertJournalRevision(ctx context.Context, phoneNumber, journalID string) error {
+	_, err := r.exec(ctx, `
+		INSERT INTO journal_revisions (revision_id, journal_id, phone_number, entry, created)
+		SELECT ?, journal_id, phone_number, entry, NOW(6)
+		FROM journals
+		WHERE journal_id=? AND phone_number=?
+	`, uuid.NewV4().String(), journalID, phoneNumber)
+	return err
+}
+
+func (r *sqlRepository) listJournalRevisions(ctx context.Context, where string, args ...interface{}) ([]*pb.JournalRevision, error) {
+	rows, err := r.query(ctx, `SELECT revision_id,journal_id,phone_number,entry,created
+		FROM journal_revisions
+		WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	revisions := []*pb.JournalRevision{}
+	for rows.Next() {
+		rev := &pb.JournalRevision{}
+		if err := rows.Scan(&rev.RevisionId, &rev.JournalId, &rev.PhoneNumber, &rev.Entry, &rev.Created); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, nil
+}
+
+func (r *sqlRepository) ListJournalRevisions(ctx context.Context, phoneNumber, journalID string) ([]*pb.JournalRevision, error) {
+	return r.listJournalRevisions(ctx, `journal_id=? AND phone_number=?
+		ORDER BY created DESC, revision_id DESC`, journalID, phoneNumber)
+}
+
+func (r *sqlRepository) GetJournalRevision(ctx context.Context, phoneNumber, revisionID string) (*pb.JournalRevision, error) {
+	revisions, err := r.listJournalRevisions(ctx, `revision_id=? AND phone_number=?`, revisionID, phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, errors.Wrapf(ErrNotFound, "revision '%s'", revisionID)
+	}
+	return revisions[0], nil
+}
+
+func (r *sqlRepository) getOrInsertTag(ctx context.Context, phoneNumber, name string) (string, error) {
+	if _, err := r.exec(ctx, `
+		INSERT IGNORE INTO tags (tag_id, phone_number, name, created)
+		VALUES (?, ?, ?, NOW(6))
+	`, uuid.NewV4().String(), phoneNumber, name); err != nil {
+		return "", err
+	}
+	tagID := ""
+	if err := r.queryRow(ctx, `SELECT tag_id FROM tags WHERE phone_number=? AND name=?`, phoneNumber, name).Scan(&tagID); err != nil {
+		return "", errors.Wrap(err, "failed to scan")
+	}
+	return tagID, nil
+}
+
+func (r *sqlRepository) AddJournalTags(ctx context.Context, phoneNumber, journalID string, tags []string, source string) error {
+	for _, tag := range tags {
+		tagID, err := r.getOrInsertTag(ctx, phoneNumber, tag)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get tag '%s'", tag)
+		}
+		if _, err := r.exec(ctx, `
+			INSERT IGNORE INTO journal_tags (journal_id, tag_id, source, created)
+			VALUES (?, ?, ?, NOW(6))
+		`, journalID, tagID, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqlRepository) DeleteJournalTags(ctx context.Context, journalID, source string) error {
+	_, err := r.exec(ctx, `
+		DELETE FROM journal_tags
+		WHERE journal_id=? AND source=?
+	`, journalID, source)
+	return err
+}
+
+func (r *sqlRepository) ListTagCounts(ctx context.Context, phoneNumber string) ([]*pb.TagCount, error) {
+	rows, err := r.query(ctx, `SELECT t.name, COUNT(DISTINCT j.journal_id)
+		FROM tags t, journal_tags jt, journals j
+		WHERE t.phone_number=?
+		AND t.tag_id=jt.tag_id
+		AND jt.journal_id=j.journal_id
+		AND j.deleted IS NULL
+		GROUP BY t.name
+		ORDER BY t.name`, phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := []*pb.TagCount{}
+	for rows.Next() {
+		c := &pb.TagCount{}
+		if err := rows.Scan(&c.Name, &c.Count); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		counts = append(counts, c)
+	}
+	return counts, nil
+}
+
+func (r *sqlRepository) InsertAttachment(ctx context.Context, phoneNumber string, a *pb.Attachment) error {
+	_, err := r.exec(ctx, `
+		INSERT INTO attachments (attachment_id, journal_id, phone_number, content_type, size, created)
+		VALUES (?, ?, ?, ?, ?, NOW(6))
+	`, a.AttachmentId, a.JournalId, phoneNumber, a.ContentType, a.Size)
+	return err
+}
+
+func (r *sqlRepository) GetAttachment(ctx context.Context, phoneNumber, attachmentID string) (*pb.Attachment, error) {
+	rows, err := r.query(ctx, `SELECT a.attachment_id,a.journal_id,a.content_type,a.size,a.created
+		FROM attachments a, journals j
+		WHERE a.attachment_id=? AND a.phone_number=?
+		AND a.journal_id=j.journal_id AND j.deleted IS NULL`, attachmentID, phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, errors.Wrapf(ErrNotFound, "attachment '%s'", attachmentID)
+	}
+	a := &pb.Attachment{}
+	if err := rows.Scan(&a.AttachmentId, &a.JournalId, &a.ContentType, &a.Size, &a.Created); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+	return a, nil
+}
+
+func (r *sqlRepository) ListPurgeableAttachments(ctx context.Context, before time.Time) ([]string, error) {
+	rows, err := r.query(ctx, `SELECT a.attachment_id
+		FROM attachments a, journals j
+		WHERE a.journal_id=j.journal_id AND j.deleted < ?`, formatTime(before))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []string{}
+	for rows.Next() {
+		id := ""
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r *sqlRepository) PurgeTrash(ctx context.Context, before time.Time) error {
+	queries := []string{
+		`DELETE FROM attachments WHERE journal_id IN (SELECT journal_id FROM journals WHERE deleted < ?)`,
+		`DELETE FROM journal_tags WHERE journal_id IN (SELECT journal_id FROM journals WHERE deleted < ?)`,
+		`DELETE FROM journal_revisions WHERE journal_id IN (SELECT journal_id FROM journals WHERE deleted < ?)`,
+		`DELETE FROM journal_terms WHERE journal_id IN (SELECT journal_id FROM journals WHERE deleted < ?)`,
+		`DELETE FROM journals WHERE deleted < ?`,
+		`DELETE FROM user_notifications WHERE deleted < ?`,
+	}
+	cutoff := formatTime(before)
+	return r.InTx(ctx, func(tx Repository) error {
+		for _, query := range queries {
+			if _, err := tx.(*sqlRepository).exec(ctx, query, cutoff); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	pb "github.com/mikerjacobi/notify-app/server/rpc"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+func (r *sqlRepository) GetNotification(ctx context.Context, notificationID string) (*pb.Notification, error) {
+	rows, err := r.query(ctx, `
+		SELECT name,type,template,default_tag
+		FROM notifications
+		WHERE notification_id=?`, notificationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, errors.Wrapf(ErrNotFound, "notification id '%s'", notificationID)
+	}
+	notification := &pb.Notification{NotificationId: notificationID}
+	if err := rows.Scan(&notification.Name, &notification.Type, &notification.Template, &notification.DefaultTag); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+	return notification, nil
+}
+
+func (r *sqlRepository) ListNotifications(ctx context.Context) ([]*pb.Notification, error) {
+	rows, err := r.query(ctx, `
+		SELECT notification_id,name,type,template,default_tag
+		FROM notifications
+		ORDER BY created, notification_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	notifications := []*pb.Notification{}
+	for rows.Next() {
+		p := &pb.Notification{}
+		if err := rows.Scan(&p.NotificationId, &p.Name, &p.Type, &p.Template, &p.DefaultTag); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		notifications = append(notifications, p)
+	}
+	return notifications, nil
+}
+
+func (r *sqlRepository) InsertNotification(ctx context.Context, n *pb.Notification) error {
+	n.NotificationId = uuid.NewV4().String()
+	_, err := r.exec(ctx, `
+		INSERT INTO notifications (notification_id, name, type, template, default_tag, created, updated)
+		VALUES (?, ?, ?, ?, ?, NOW(6), NOW(6))
+	`, n.NotificationId, n.Name, n.Type, n.Template, n.DefaultTag)
+	return err
+}
+
+func (r *sqlRepository) UpdateNotification(ctx context.Context, n *pb.Notification) error {
+	_, err := r.exec(ctx, `
+		UPDATE notifications SET name=?, template=?, default_tag=?, updated=NOW(6)
+		WHERE notification_id=?
+	`, n.Name, n.Template, n.DefaultTag, n.NotificationId)
+	return err
+}
+
+func (r *sqlRepository) GetMostRecentPrompt(ctx context.Context, phoneNumber string) (*pb.Notification, error) {
+	rows, err := r.query(ctx, `
+		SELECT n.notification_id, n.template, n.default_tag
+		FROM communications c, notifications n
+		WHERE c.to_phone=?
+		AND c.notification_id = n.notification_id
+		AND c.status='sent'
+		AND n.type='prompt'
+		ORDER BY c.created DESC LIMIT 1`, phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, errors.Wrapf(ErrNotFound, "sent message to %s", phoneNumber)
+	}
+	notification := &pb.Notification{}
+	if err := rows.Scan(&notification.NotificationId, &notification.Template, &notification.DefaultTag); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+	return notification, nil
+}
+
+func (r *sqlRepository) InsertUserNotification(ctx context.Context, up *pb.UserNotification) error {
+	//a trashed copy would collide with the primary key, the new one replaces it
+	if _, err := r.exec(ctx, `
+		DELETE FROM user_notifications
+		WHERE phone_number=?
+		AND notification_id=?
+		AND deleted IS NOT NULL`, up.PhoneNumber, up.NotificationId); err != nil {
+		return errors.Wrap(err, "failed to purge trashed notification")
+	}
+	_, err := r.exec(ctx, `
+		INSERT INTO user_notifications (notification_id, phone_number, next_notification_time, frequency, created, updated)
+		VALUES (?, ?, ?, ?, NOW(6), NOW(6))
+	`, up.NotificationId, up.PhoneNumber, normalizeTime(up.NextNotificationTime), up.Frequency)
+	return err
+}
+
+// userNotificationColumns are scanned by scanUserNotification.
+const userNotificationColumns = `up.notification_id,up.phone_number,up.next_notification_time,up.frequency,IFNULL(up.deleted, ''),p.template,p.type,p.name`
+
+func scanUserNotification(rows *sql.Rows) (*pb.UserNotification, error) {
+	up := &pb.UserNotification{Notification: &pb.Notification{}}
+	if err := rows.Scan(&up.NotificationId, &up.PhoneNumber, &up.NextNotificationTime, &up.Frequency, &up.Deleted, &up.Notification.Template, &up.Notification.Type, &up.Notification.Name); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+	up.Notification.NotificationId = up.NotificationId
+	return up, nil
+}
+
+func (r *sqlRepository) listUserNotifications(ctx context.Context, where string, args ...interface{}) ([]*pb.UserNotification, error) {
+	rows, err := r.query(ctx, `
+		SELECT `+userNotificationColumns+`
+		FROM user_notifications up, notifications p
+		WHERE up.notification_id=p.notification_id
+		AND `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	userNotifications := []*pb.UserNotification{}
+	for rows.Next() {
+		up, err := scanUserNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		userNotifications = append(userNotifications, up)
+	}
+	return userNotifications, nil
+}
+
+func (r *sqlRepository) GetUserNotification(ctx context.Context, phoneNumber, notificationID string) (*pb.UserNotification, error) {
+	userNotifications, err := r.listUserNotifications(ctx, `up.phone_number=? AND up.notification_id=? AND up.deleted IS NULL`, phoneNumber, notificationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(userNotifications) == 0 {
+		return nil, errors.Wrapf(ErrNotFound, "user notification '%s'", notificationID)
+	}
+	return userNotifications[0], nil
+}
+
+func (r *sqlRepository) ListUserNotifications(ctx context.Context, phoneNumber string) ([]*pb.UserNotification, error) {
+	return r.listUserNotifications(ctx, `up.phone_number=? AND up.deleted IS NULL
+		ORDER BY up.next_notification_time, up.notification_id`, phoneNumber)
+}
+
+func (r *sqlRepository) ListDueUserNotifications(ctx context.Context, before time.Time) ([]*pb.UserNotification, error) {
+	return r.listUserNotifications(ctx, `up.next_notification_time <= ? AND up.deleted IS NULL
+		ORDER BY up.next_notification_time, up.notification_id`, formatTime(before))
+}
+
+func (r *sqlRepository) ListTrashedUserNotifications(ctx context.Context, phoneNumber string) ([]*pb.UserNotification, error) {
+	return r.listUserNotifications(ctx, `up.phone_number=? AND up.deleted IS NOT NULL
+		ORDER BY up.deleted DESC`, phoneNumber)
+}
+
+func (r *sqlRepository) AdvanceUserNotification(ctx context.Context, phoneNumber, notificationID string, due, next time.Time) error {
+	stmt := `
+		UPDATE user_notifications
+		SET updated=NOW(6), next_notification_time=?
+		WHERE phone_number=?
+		AND notification_id=?
+		AND next_notification_time=? AND deleted IS NULL`
+	args := []interface{}{formatTime(next), phoneNumber, notificationID, formatTime(due)}
+	if next.IsZero() {
+		stmt = `
+		DELETE FROM user_notifications
+		WHERE phone_number=?
+		AND notification_id=?
+		AND next_notification_time=? AND deleted IS NULL`
+		args = args[1:]
+	}
+	res, err := r.exec(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.Wrapf(ErrNotFound, "user notification '%s' due at %s", notificationID, formatTime(due))
+	}
+	return nil
+}
+
+func (r *sqlRepository) UpdateUserNotificationSchedule(ctx context.Context, up *pb.UserNotification) error {
+	_, err := r.exec(ctx, `
+		UPDATE user_notifications
+		SET updated=NOW(6), next_notification_time=?, frequency=?
+		WHERE phone_number=?
+		AND notification_id=?`, normalizeTime(up.NextNotificationTime), up.Frequency, up.PhoneNumber, up.NotificationId)
+	return err
+}
+
+func (r *sqlRepository) DeleteUserNotification(ctx context.Context, phoneNumber, notificationID string) error {
+	_, err := r.exec(ctx, `
+		UPDATE user_notifications SET deleted=NOW(6)
+		WHERE phone_number=?
+		AND notification_id=?
+		AND deleted IS NULL
+	`, phoneNumber, notificationID)
+	return err
+}
+
+func (r *sqlRepository) RestoreUserNotification(ctx context.Context, phoneNumber, notificationID string) error {
+	res, err := r.exec(ctx, `
+		UPDATE user_notifications SET deleted=NULL, updated=NOW(6)
+		WHERE notification_id=? AND phone_number=? AND deleted IS NOT NULL
+	`, notificationID, phoneNumber)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.Wrapf(ErrNotFound, "trashed user notification '%s'", notificationID)
+	}
+	return nil
+}
+
+func (r *sqlRepository) InsertCommunication(ctx context.Context, comm *pb.Communication) error {
+	if comm.CommsId == "" {
+		comm.CommsId = uuid.NewV4().String()
+	}
+	if comm.Status == "" {
+		comm.Status = CommsSent
+	}
+	_, err := r.exec(ctx, `
+		INSERT INTO communications (comms_id, notification_id, from_phone, to_phone, message, status, error, created)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NOW(6))
+	`, comm.CommsId, comm.NotificationId, comm.From, comm.To, comm.Message, comm.Status, comm.Error)
+	return err
+}
+
+// commsColumns are scanned by scanCommunication.
+const commsColumns = `comms_id,notification_id,from_phone,to_phone,message,status,COALESCE(error, ''),created`
+
+func scanCommunication(rows *sql.Rows) (*pb.Communication, error) {
+	c := &pb.Communication{}
+	if err := rows.Scan(&c.CommsId, &c.NotificationId, &c.From, &c.To, &c.Message, &c.Status, &c.Error, &c.Created); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+	return c, nil
+}
+
+func (r *sqlRepository) listCommunications(ctx context.Context, where string, args ...interface{}) ([]*pb.Communication, error) {
+	rows, err := r.query(ctx, `SELECT `+commsColumns+`
+		FROM communications
+		WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	comms := []*pb.Communication{}
+	for rows.Next() {
+		c, err := scanCommunication(rows)
+		if err != nil {
+			return nil, err
+		}
+		comms = append(comms, c)
+	}
+	return comms, nil
+}
+
+func (r *sqlRepository) ListCommunications(ctx context.Context, phoneNumber string, page *Page, notificationID string) ([]*pb.Communication, string, error) {
+	clauses := []string{"(to_phone=? OR from_phone=?)"}
+	args := []interface{}{phoneNumber, phoneNumber}
+	if notificationID != "" {
+		clauses = append(clauses, "notification_id=?")
+		args = append(args, notificationID)
+	}
+	clauses, args = page.where("created", "comms_id", clauses, args)
+
+	comms, err := r.listCommunications(ctx, strings.Join(clauses, " AND ")+`
+		`+page.orderLimit("created", "comms_id"), args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(comms) > page.Limit {
+		comms = comms[:page.Limit]
+		last := comms[len(comms)-1]
+		nextCursor = EncodeCursor(last.Created, last.CommsId)
+	}
+	return comms, nextCursor, nil
+}
+
+func (r *sqlRepository) ListDeadLetters(ctx context.Context, phoneNumber string) ([]*pb.Communication, error) {
+	clauses := []string{"status=?"}
+	args := []interface{}{CommsFailed}
+	if phoneNumber != "" {
+		clauses = append(clauses, "to_phone=?")
+		args = append(args, phoneNumber)
+	}
+	return r.listCommunications(ctx, strings.Join(clauses, " AND ")+`
+		ORDER BY created, comms_id`, args...)
+}
+
+func (r *sqlRepository) ClaimDeadLetter(ctx context.Context, commsID string) (*pb.Communication, error) {
+	var claimed *pb.Communication
+	err := r.InTx(ctx, func(tx Repository) error {
+		sqlTx := tx.(*sqlRepository)
+		res, err := sqlTx.exec(ctx, `UPDATE communications SET status=? WHERE comms_id=? AND status=?`, CommsReplayed, commsID, CommsFailed)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return errors.Wrapf(ErrNotFound, "dead letter '%s'", commsID)
+		}
+		comms, err := sqlTx.listCommunications(ctx, `comms_id=?`, commsID)
+		if err != nil {
+			return err
+		}
+		if len(comms) == 0 {
+			return errors.Wrapf(ErrNotFound, "dead letter '%s'", commsID)
+		}
+		claimed = comms[0]
+		return nil
+	})
+	return claimed, err
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite"
+)
+
+// sqlite keeps timestamps as text in TimeFormat, so they sort and compare
+// like mysql's DATETIME(6).  strftime's %f only has milliseconds.  it has
+// no named locks, the file is only ever opened by one server.
+var sqliteDialect = dialect{name: "sqlite", rewrite: strings.NewReplacer(
+	"NOW(6)", "(strftime('%Y-%m-%d %H:%M:%f', 'now') || '000')",
+	"INSERT IGNORE", "INSERT OR IGNORE",
+)}
+
+// OpenSQLite opens, creating if need be, the sqlite database file at path.
+// it uses the pure go driver, so it needs no cgo or running database.  the
+// schema comes from migrating it up.  transactions begin immediate, so one
+// that reads before it writes waits for the write lock instead of failing
+// when another commits first.
+func OpenSQLite(path string) (Repository, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s", path)
+	}
+	return newSQLRepository(db, sqliteDialect), nil
+}
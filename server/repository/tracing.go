@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/mikerjacobi/notify-app/server/repository")
+
+// startSpan starts a span for one statement, named by its verb, eg sql
+// SELECT.  the args aren't recorded, they're user data.
+func (r *sqlRepository) startSpan(ctx context.Context, statement string) (context.Context, trace.Span) {
+	statement = strings.Join(strings.Fields(statement), " ")
+	name := "sql"
+	if verb := strings.SplitN(statement, " ", 2)[0]; verb != "" {
+		name += " " + strings.ToUpper(verb)
+	}
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", r.dialect.name),
+		attribute.String("db.statement", statement),
+	))
+}
+
+// endSpan ends span, marking it failed if err isn't nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -13,8 +13,33 @@ It has these top-level messages:
 	CreateAccountResp
 	UserNotification
 	Notification
+	NotificationList
+	ListUserNotificationsReq
+	UserNotificationList
 	Communication
+	ListCommunicationsReq
+	CommunicationList
 	Journal
+	Attachment
+	ListJournalsReq
+	JournalList
+	SearchJournalsReq
+	SearchHit
+	SearchJournalsResp
+	ExportReq
+	ExportResp
+	ApiToken
+	ImportReq
+	ImportRow
+	ImportReport
+	JournalRevision
+	JournalRevisionList
+	ListTrashReq
+	Trash
+	ListTagsReq
+	TagCount
+	TagList
+	RotateUserKeyReq
 */
 package server
 
@@ -41,6 +66,9 @@ type User struct {
 	Birthday    string `protobuf:"bytes,4,opt,name=birthday" json:"birthday,omitempty"`
 	Verified    bool   `protobuf:"varint,5,opt,name=verified" json:"verified,omitempty"`
 	SessionId   string `protobuf:"bytes,6,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	Role        string `protobuf:"bytes,7,opt,name=role" json:"role,omitempty"`
+	CsrfToken   string `protobuf:"bytes,8,opt,name=csrf_token,json=csrfToken" json:"csrf_token,omitempty"`
+	TwoFactor   bool   `protobuf:"varint,9,opt,name=two_factor,json=twoFactor" json:"two_factor,omitempty"`
 }
 
 func (m *User) Reset()                    { *m = User{} }
@@ -90,9 +118,31 @@ func (m *User) GetSessionId() string {
 	return ""
 }
 
+func (m *User) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+func (m *User) GetCsrfToken() string {
+	if m != nil {
+		return m.CsrfToken
+	}
+	return ""
+}
+
+func (m *User) GetTwoFactor() bool {
+	if m != nil {
+		return m.TwoFactor
+	}
+	return false
+}
+
 type CreateAccountReq struct {
 	User           *User  `protobuf:"bytes,1,opt,name=user" json:"user,omitempty"`
 	PasswordRepeat string `protobuf:"bytes,2,opt,name=password_repeat,json=passwordRepeat" json:"password_repeat,omitempty"`
+	Region         string `protobuf:"bytes,3,opt,name=region" json:"region,omitempty"`
 }
 
 func (m *CreateAccountReq) Reset()                    { *m = CreateAccountReq{} }
@@ -114,6 +164,13 @@ func (m *CreateAccountReq) GetPasswordRepeat() string {
 	return ""
 }
 
+func (m *CreateAccountReq) GetRegion() string {
+	if m != nil {
+		return m.Region
+	}
+	return ""
+}
+
 type CreateAccountResp struct {
 	Success bool `protobuf:"varint,1,opt,name=success" json:"success,omitempty"`
 }
@@ -136,6 +193,7 @@ type UserNotification struct {
 	NextNotificationTime string        `protobuf:"bytes,3,opt,name=next_notification_time,json=nextNotificationTime" json:"next_notification_time,omitempty"`
 	Frequency            string        `protobuf:"bytes,4,opt,name=frequency" json:"frequency,omitempty"`
 	Notification         *Notification `protobuf:"bytes,5,opt,name=notification" json:"notification,omitempty"`
+	Deleted              string        `protobuf:"bytes,6,opt,name=deleted" json:"deleted,omitempty"`
 }
 
 func (m *UserNotification) Reset()                    { *m = UserNotification{} }
@@ -178,11 +236,19 @@ func (m *UserNotification) GetNotification() *Notification {
 	return nil
 }
 
+func (m *UserNotification) GetDeleted() string {
+	if m != nil {
+		return m.Deleted
+	}
+	return ""
+}
+
 type Notification struct {
 	NotificationId string `protobuf:"bytes,1,opt,name=notification_id,json=notificationId" json:"notification_id,omitempty"`
 	Name           string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
 	Type           string `protobuf:"bytes,3,opt,name=type" json:"type,omitempty"`
 	Template       string `protobuf:"bytes,4,opt,name=template" json:"template,omitempty"`
+	DefaultTag     string `protobuf:"bytes,5,opt,name=default_tag,json=defaultTag" json:"default_tag,omitempty"`
 }
 
 func (m *Notification) Reset()                    { *m = Notification{} }
@@ -218,18 +284,76 @@ func (m *Notification) GetTemplate() string {
 	return ""
 }
 
+func (m *Notification) GetDefaultTag() string {
+	if m != nil {
+		return m.DefaultTag
+	}
+	return ""
+}
+
+type NotificationList struct {
+	Notifications []*Notification `protobuf:"bytes,1,rep,name=notifications" json:"notifications,omitempty"`
+}
+
+func (m *NotificationList) Reset()                    { *m = NotificationList{} }
+func (m *NotificationList) String() string            { return proto.CompactTextString(m) }
+func (*NotificationList) ProtoMessage()               {}
+func (*NotificationList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *NotificationList) GetNotifications() []*Notification {
+	if m != nil {
+		return m.Notifications
+	}
+	return nil
+}
+
+type ListUserNotificationsReq struct {
+	PhoneNumber string `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+}
+
+func (m *ListUserNotificationsReq) Reset()                    { *m = ListUserNotificationsReq{} }
+func (m *ListUserNotificationsReq) String() string            { return proto.CompactTextString(m) }
+func (*ListUserNotificationsReq) ProtoMessage()               {}
+func (*ListUserNotificationsReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *ListUserNotificationsReq) GetPhoneNumber() string {
+	if m != nil {
+		return m.PhoneNumber
+	}
+	return ""
+}
+
+type UserNotificationList struct {
+	UserNotifications []*UserNotification `protobuf:"bytes,1,rep,name=user_notifications,json=userNotifications" json:"user_notifications,omitempty"`
+}
+
+func (m *UserNotificationList) Reset()                    { *m = UserNotificationList{} }
+func (m *UserNotificationList) String() string            { return proto.CompactTextString(m) }
+func (*UserNotificationList) ProtoMessage()               {}
+func (*UserNotificationList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *UserNotificationList) GetUserNotifications() []*UserNotification {
+	if m != nil {
+		return m.UserNotifications
+	}
+	return nil
+}
+
 type Communication struct {
 	CommsId        string `protobuf:"bytes,1,opt,name=comms_id,json=commsId" json:"comms_id,omitempty"`
 	From           string `protobuf:"bytes,2,opt,name=from" json:"from,omitempty"`
 	To             string `protobuf:"bytes,3,opt,name=to" json:"to,omitempty"`
 	Message        string `protobuf:"bytes,4,opt,name=message" json:"message,omitempty"`
 	NotificationId string `protobuf:"bytes,5,opt,name=notification_id,json=notificationId" json:"notification_id,omitempty"`
+	Created        string `protobuf:"bytes,6,opt,name=created" json:"created,omitempty"`
+	Status         string `protobuf:"bytes,7,opt,name=status" json:"status,omitempty"`
+	Error          string `protobuf:"bytes,8,opt,name=error" json:"error,omitempty"`
 }
 
 func (m *Communication) Reset()                    { *m = Communication{} }
 func (m *Communication) String() string            { return proto.CompactTextString(m) }
 func (*Communication) ProtoMessage()               {}
-func (*Communication) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*Communication) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 func (m *Communication) GetCommsId() string {
 	if m != nil {
@@ -266,20 +390,124 @@ func (m *Communication) GetNotificationId() string {
 	return ""
 }
 
+func (m *Communication) GetCreated() string {
+	if m != nil {
+		return m.Created
+	}
+	return ""
+}
+
+func (m *Communication) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Communication) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type ListCommunicationsReq struct {
+	PhoneNumber    string `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+	PageSize       int32  `protobuf:"varint,2,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	Cursor         string `protobuf:"bytes,3,opt,name=cursor" json:"cursor,omitempty"`
+	CreatedAfter   string `protobuf:"bytes,4,opt,name=created_after,json=createdAfter" json:"created_after,omitempty"`
+	CreatedBefore  string `protobuf:"bytes,5,opt,name=created_before,json=createdBefore" json:"created_before,omitempty"`
+	NotificationId string `protobuf:"bytes,6,opt,name=notification_id,json=notificationId" json:"notification_id,omitempty"`
+}
+
+func (m *ListCommunicationsReq) Reset()                    { *m = ListCommunicationsReq{} }
+func (m *ListCommunicationsReq) String() string            { return proto.CompactTextString(m) }
+func (*ListCommunicationsReq) ProtoMessage()               {}
+func (*ListCommunicationsReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *ListCommunicationsReq) GetPhoneNumber() string {
+	if m != nil {
+		return m.PhoneNumber
+	}
+	return ""
+}
+
+func (m *ListCommunicationsReq) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListCommunicationsReq) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *ListCommunicationsReq) GetCreatedAfter() string {
+	if m != nil {
+		return m.CreatedAfter
+	}
+	return ""
+}
+
+func (m *ListCommunicationsReq) GetCreatedBefore() string {
+	if m != nil {
+		return m.CreatedBefore
+	}
+	return ""
+}
+
+func (m *ListCommunicationsReq) GetNotificationId() string {
+	if m != nil {
+		return m.NotificationId
+	}
+	return ""
+}
+
+type CommunicationList struct {
+	Communications []*Communication `protobuf:"bytes,1,rep,name=communications" json:"communications,omitempty"`
+	NextCursor     string           `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor" json:"next_cursor,omitempty"`
+}
+
+func (m *CommunicationList) Reset()                    { *m = CommunicationList{} }
+func (m *CommunicationList) String() string            { return proto.CompactTextString(m) }
+func (*CommunicationList) ProtoMessage()               {}
+func (*CommunicationList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *CommunicationList) GetCommunications() []*Communication {
+	if m != nil {
+		return m.Communications
+	}
+	return nil
+}
+
+func (m *CommunicationList) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
 type Journal struct {
-	JournalId   string `protobuf:"bytes,1,opt,name=journal_id,json=journalId" json:"journal_id,omitempty"`
-	CommsId     string `protobuf:"bytes,2,opt,name=comms_id,json=commsId" json:"comms_id,omitempty"`
-	PhoneNumber string `protobuf:"bytes,3,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
-	Title       string `protobuf:"bytes,4,opt,name=title" json:"title,omitempty"`
-	Entry       string `protobuf:"bytes,5,opt,name=entry" json:"entry,omitempty"`
-	Created     string `protobuf:"bytes,6,opt,name=created" json:"created,omitempty"`
-	Updated     string `protobuf:"bytes,7,opt,name=updated" json:"updated,omitempty"`
+	JournalId   string        `protobuf:"bytes,1,opt,name=journal_id,json=journalId" json:"journal_id,omitempty"`
+	CommsId     string        `protobuf:"bytes,2,opt,name=comms_id,json=commsId" json:"comms_id,omitempty"`
+	PhoneNumber string        `protobuf:"bytes,3,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+	Title       string        `protobuf:"bytes,4,opt,name=title" json:"title,omitempty"`
+	Entry       string        `protobuf:"bytes,5,opt,name=entry" json:"entry,omitempty"`
+	Created     string        `protobuf:"bytes,6,opt,name=created" json:"created,omitempty"`
+	Updated     string        `protobuf:"bytes,7,opt,name=updated" json:"updated,omitempty"`
+	Deleted     string        `protobuf:"bytes,8,opt,name=deleted" json:"deleted,omitempty"`
+	Tags        []string      `protobuf:"bytes,9,rep,name=tags" json:"tags,omitempty"`
+	Attachments []*Attachment `protobuf:"bytes,10,rep,name=attachments" json:"attachments,omitempty"`
 }
 
 func (m *Journal) Reset()                    { *m = Journal{} }
 func (m *Journal) String() string            { return proto.CompactTextString(m) }
 func (*Journal) ProtoMessage()               {}
-func (*Journal) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*Journal) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
 func (m *Journal) GetJournalId() string {
 	if m != nil {
@@ -330,55 +558,859 @@ func (m *Journal) GetUpdated() string {
 	return ""
 }
 
+func (m *Journal) GetDeleted() string {
+	if m != nil {
+		return m.Deleted
+	}
+	return ""
+}
+
+func (m *Journal) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *Journal) GetAttachments() []*Attachment {
+	if m != nil {
+		return m.Attachments
+	}
+	return nil
+}
+
+type Attachment struct {
+	AttachmentId string `protobuf:"bytes,1,opt,name=attachment_id,json=attachmentId" json:"attachment_id,omitempty"`
+	JournalId    string `protobuf:"bytes,2,opt,name=journal_id,json=journalId" json:"journal_id,omitempty"`
+	ContentType  string `protobuf:"bytes,3,opt,name=content_type,json=contentType" json:"content_type,omitempty"`
+	Size         int64  `protobuf:"varint,4,opt,name=size" json:"size,omitempty"`
+	Created      string `protobuf:"bytes,5,opt,name=created" json:"created,omitempty"`
+}
+
+func (m *Attachment) Reset()                    { *m = Attachment{} }
+func (m *Attachment) String() string            { return proto.CompactTextString(m) }
+func (*Attachment) ProtoMessage()               {}
+func (*Attachment) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *Attachment) GetAttachmentId() string {
+	if m != nil {
+		return m.AttachmentId
+	}
+	return ""
+}
+
+func (m *Attachment) GetJournalId() string {
+	if m != nil {
+		return m.JournalId
+	}
+	return ""
+}
+
+func (m *Attachment) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+func (m *Attachment) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *Attachment) GetCreated() string {
+	if m != nil {
+		return m.Created
+	}
+	return ""
+}
+
+type ListJournalsReq struct {
+	PhoneNumber   string `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+	PageSize      int32  `protobuf:"varint,2,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	Cursor        string `protobuf:"bytes,3,opt,name=cursor" json:"cursor,omitempty"`
+	CreatedAfter  string `protobuf:"bytes,4,opt,name=created_after,json=createdAfter" json:"created_after,omitempty"`
+	CreatedBefore string `protobuf:"bytes,5,opt,name=created_before,json=createdBefore" json:"created_before,omitempty"`
+	Title         string `protobuf:"bytes,6,opt,name=title" json:"title,omitempty"`
+	Tag           string `protobuf:"bytes,7,opt,name=tag" json:"tag,omitempty"`
+}
+
+func (m *ListJournalsReq) Reset()                    { *m = ListJournalsReq{} }
+func (m *ListJournalsReq) String() string            { return proto.CompactTextString(m) }
+func (*ListJournalsReq) ProtoMessage()               {}
+func (*ListJournalsReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ListJournalsReq) GetPhoneNumber() string {
+	if m != nil {
+		return m.PhoneNumber
+	}
+	return ""
+}
+
+func (m *ListJournalsReq) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListJournalsReq) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *ListJournalsReq) GetCreatedAfter() string {
+	if m != nil {
+		return m.CreatedAfter
+	}
+	return ""
+}
+
+func (m *ListJournalsReq) GetCreatedBefore() string {
+	if m != nil {
+		return m.CreatedBefore
+	}
+	return ""
+}
+
+func (m *ListJournalsReq) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+func (m *ListJournalsReq) GetTag() string {
+	if m != nil {
+		return m.Tag
+	}
+	return ""
+}
+
+type JournalList struct {
+	Journals   []*Journal `protobuf:"bytes,1,rep,name=journals" json:"journals,omitempty"`
+	NextCursor string     `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor" json:"next_cursor,omitempty"`
+}
+
+func (m *JournalList) Reset()                    { *m = JournalList{} }
+func (m *JournalList) String() string            { return proto.CompactTextString(m) }
+func (*JournalList) ProtoMessage()               {}
+func (*JournalList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *JournalList) GetJournals() []*Journal {
+	if m != nil {
+		return m.Journals
+	}
+	return nil
+}
+
+func (m *JournalList) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
+type SearchJournalsReq struct {
+	PhoneNumber string `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+	Query       string `protobuf:"bytes,2,opt,name=query" json:"query,omitempty"`
+	PageSize    int32  `protobuf:"varint,3,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+}
+
+func (m *SearchJournalsReq) Reset()                    { *m = SearchJournalsReq{} }
+func (m *SearchJournalsReq) String() string            { return proto.CompactTextString(m) }
+func (*SearchJournalsReq) ProtoMessage()               {}
+func (*SearchJournalsReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *SearchJournalsReq) GetPhoneNumber() string {
+	if m != nil {
+		return m.PhoneNumber
+	}
+	return ""
+}
+
+func (m *SearchJournalsReq) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *SearchJournalsReq) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+type SearchHit struct {
+	Journal *Journal `protobuf:"bytes,1,opt,name=journal" json:"journal,omitempty"`
+	Score   float64  `protobuf:"fixed64,2,opt,name=score" json:"score,omitempty"`
+	Snippet string   `protobuf:"bytes,3,opt,name=snippet" json:"snippet,omitempty"`
+}
+
+func (m *SearchHit) Reset()                    { *m = SearchHit{} }
+func (m *SearchHit) String() string            { return proto.CompactTextString(m) }
+func (*SearchHit) ProtoMessage()               {}
+func (*SearchHit) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *SearchHit) GetJournal() *Journal {
+	if m != nil {
+		return m.Journal
+	}
+	return nil
+}
+
+func (m *SearchHit) GetScore() float64 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
+}
+
+func (m *SearchHit) GetSnippet() string {
+	if m != nil {
+		return m.Snippet
+	}
+	return ""
+}
+
+type SearchJournalsResp struct {
+	Hits []*SearchHit `protobuf:"bytes,1,rep,name=hits" json:"hits,omitempty"`
+}
+
+func (m *SearchJournalsResp) Reset()                    { *m = SearchJournalsResp{} }
+func (m *SearchJournalsResp) String() string            { return proto.CompactTextString(m) }
+func (*SearchJournalsResp) ProtoMessage()               {}
+func (*SearchJournalsResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *SearchJournalsResp) GetHits() []*SearchHit {
+	if m != nil {
+		return m.Hits
+	}
+	return nil
+}
+
+type ExportReq struct {
+	PhoneNumber   string `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+	Format        string `protobuf:"bytes,2,opt,name=format" json:"format,omitempty"`
+	GroupBy       string `protobuf:"bytes,3,opt,name=group_by,json=groupBy" json:"group_by,omitempty"`
+	CreatedAfter  string `protobuf:"bytes,4,opt,name=created_after,json=createdAfter" json:"created_after,omitempty"`
+	CreatedBefore string `protobuf:"bytes,5,opt,name=created_before,json=createdBefore" json:"created_before,omitempty"`
+}
+
+func (m *ExportReq) Reset()                    { *m = ExportReq{} }
+func (m *ExportReq) String() string            { return proto.CompactTextString(m) }
+func (*ExportReq) ProtoMessage()               {}
+func (*ExportReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *ExportReq) GetPhoneNumber() string {
+	if m != nil {
+		return m.PhoneNumber
+	}
+	return ""
+}
+
+func (m *ExportReq) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *ExportReq) GetGroupBy() string {
+	if m != nil {
+		return m.GroupBy
+	}
+	return ""
+}
+
+func (m *ExportReq) GetCreatedAfter() string {
+	if m != nil {
+		return m.CreatedAfter
+	}
+	return ""
+}
+
+func (m *ExportReq) GetCreatedBefore() string {
+	if m != nil {
+		return m.CreatedBefore
+	}
+	return ""
+}
+
+type ExportResp struct {
+	Filename    string `protobuf:"bytes,1,opt,name=filename" json:"filename,omitempty"`
+	ContentType string `protobuf:"bytes,2,opt,name=content_type,json=contentType" json:"content_type,omitempty"`
+	Data        []byte `protobuf:"bytes,3,opt,name=data" json:"data,omitempty"`
+	DownloadUrl string `protobuf:"bytes,4,opt,name=download_url,json=downloadUrl" json:"download_url,omitempty"`
+}
+
+func (m *ExportResp) Reset()                    { *m = ExportResp{} }
+func (m *ExportResp) String() string            { return proto.CompactTextString(m) }
+func (*ExportResp) ProtoMessage()               {}
+func (*ExportResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *ExportResp) GetFilename() string {
+	if m != nil {
+		return m.Filename
+	}
+	return ""
+}
+
+func (m *ExportResp) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+func (m *ExportResp) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *ExportResp) GetDownloadUrl() string {
+	if m != nil {
+		return m.DownloadUrl
+	}
+	return ""
+}
+
+type ApiToken struct {
+	TokenId     string `protobuf:"bytes,1,opt,name=token_id,json=tokenId" json:"token_id,omitempty"`
+	PhoneNumber string `protobuf:"bytes,2,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+	Name        string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	Created     string `protobuf:"bytes,4,opt,name=created" json:"created,omitempty"`
+	LastUsed    string `protobuf:"bytes,5,opt,name=last_used,json=lastUsed" json:"last_used,omitempty"`
+}
+
+func (m *ApiToken) Reset()                    { *m = ApiToken{} }
+func (m *ApiToken) String() string            { return proto.CompactTextString(m) }
+func (*ApiToken) ProtoMessage()               {}
+func (*ApiToken) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *ApiToken) GetTokenId() string {
+	if m != nil {
+		return m.TokenId
+	}
+	return ""
+}
+
+func (m *ApiToken) GetPhoneNumber() string {
+	if m != nil {
+		return m.PhoneNumber
+	}
+	return ""
+}
+
+func (m *ApiToken) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ApiToken) GetCreated() string {
+	if m != nil {
+		return m.Created
+	}
+	return ""
+}
+
+func (m *ApiToken) GetLastUsed() string {
+	if m != nil {
+		return m.LastUsed
+	}
+	return ""
+}
+
+type ImportReq struct {
+	PhoneNumber string `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+	Format      string `protobuf:"bytes,2,opt,name=format" json:"format,omitempty"`
+	Data        []byte `protobuf:"bytes,3,opt,name=data" json:"data,omitempty"`
+	DryRun      bool   `protobuf:"varint,4,opt,name=dry_run,json=dryRun" json:"dry_run,omitempty"`
+}
+
+func (m *ImportReq) Reset()                    { *m = ImportReq{} }
+func (m *ImportReq) String() string            { return proto.CompactTextString(m) }
+func (*ImportReq) ProtoMessage()               {}
+func (*ImportReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *ImportReq) GetPhoneNumber() string {
+	if m != nil {
+		return m.PhoneNumber
+	}
+	return ""
+}
+
+func (m *ImportReq) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
+func (m *ImportReq) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *ImportReq) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type ImportRow struct {
+	Row       int32  `protobuf:"varint,1,opt,name=row" json:"row,omitempty"`
+	Title     string `protobuf:"bytes,2,opt,name=title" json:"title,omitempty"`
+	Created   string `protobuf:"bytes,3,opt,name=created" json:"created,omitempty"`
+	Status    string `protobuf:"bytes,4,opt,name=status" json:"status,omitempty"`
+	Error     string `protobuf:"bytes,5,opt,name=error" json:"error,omitempty"`
+	JournalId string `protobuf:"bytes,6,opt,name=journal_id,json=journalId" json:"journal_id,omitempty"`
+}
+
+func (m *ImportRow) Reset()                    { *m = ImportRow{} }
+func (m *ImportRow) String() string            { return proto.CompactTextString(m) }
+func (*ImportRow) ProtoMessage()               {}
+func (*ImportRow) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *ImportRow) GetRow() int32 {
+	if m != nil {
+		return m.Row
+	}
+	return 0
+}
+
+func (m *ImportRow) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+func (m *ImportRow) GetCreated() string {
+	if m != nil {
+		return m.Created
+	}
+	return ""
+}
+
+func (m *ImportRow) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ImportRow) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *ImportRow) GetJournalId() string {
+	if m != nil {
+		return m.JournalId
+	}
+	return ""
+}
+
+type ImportReport struct {
+	ImportId   string       `protobuf:"bytes,1,opt,name=import_id,json=importId" json:"import_id,omitempty"`
+	DryRun     bool         `protobuf:"varint,2,opt,name=dry_run,json=dryRun" json:"dry_run,omitempty"`
+	Imported   int32        `protobuf:"varint,3,opt,name=imported" json:"imported,omitempty"`
+	Duplicates int32        `protobuf:"varint,4,opt,name=duplicates" json:"duplicates,omitempty"`
+	Invalid    int32        `protobuf:"varint,5,opt,name=invalid" json:"invalid,omitempty"`
+	Rows       []*ImportRow `protobuf:"bytes,6,rep,name=rows" json:"rows,omitempty"`
+}
+
+func (m *ImportReport) Reset()                    { *m = ImportReport{} }
+func (m *ImportReport) String() string            { return proto.CompactTextString(m) }
+func (*ImportReport) ProtoMessage()               {}
+func (*ImportReport) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *ImportReport) GetImportId() string {
+	if m != nil {
+		return m.ImportId
+	}
+	return ""
+}
+
+func (m *ImportReport) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+func (m *ImportReport) GetImported() int32 {
+	if m != nil {
+		return m.Imported
+	}
+	return 0
+}
+
+func (m *ImportReport) GetDuplicates() int32 {
+	if m != nil {
+		return m.Duplicates
+	}
+	return 0
+}
+
+func (m *ImportReport) GetInvalid() int32 {
+	if m != nil {
+		return m.Invalid
+	}
+	return 0
+}
+
+func (m *ImportReport) GetRows() []*ImportRow {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+type JournalRevision struct {
+	RevisionId  string `protobuf:"bytes,1,opt,name=revision_id,json=revisionId" json:"revision_id,omitempty"`
+	JournalId   string `protobuf:"bytes,2,opt,name=journal_id,json=journalId" json:"journal_id,omitempty"`
+	PhoneNumber string `protobuf:"bytes,3,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+	Entry       string `protobuf:"bytes,4,opt,name=entry" json:"entry,omitempty"`
+	Created     string `protobuf:"bytes,5,opt,name=created" json:"created,omitempty"`
+}
+
+func (m *JournalRevision) Reset()                    { *m = JournalRevision{} }
+func (m *JournalRevision) String() string            { return proto.CompactTextString(m) }
+func (*JournalRevision) ProtoMessage()               {}
+func (*JournalRevision) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *JournalRevision) GetRevisionId() string {
+	if m != nil {
+		return m.RevisionId
+	}
+	return ""
+}
+
+func (m *JournalRevision) GetJournalId() string {
+	if m != nil {
+		return m.JournalId
+	}
+	return ""
+}
+
+func (m *JournalRevision) GetPhoneNumber() string {
+	if m != nil {
+		return m.PhoneNumber
+	}
+	return ""
+}
+
+func (m *JournalRevision) GetEntry() string {
+	if m != nil {
+		return m.Entry
+	}
+	return ""
+}
+
+func (m *JournalRevision) GetCreated() string {
+	if m != nil {
+		return m.Created
+	}
+	return ""
+}
+
+type JournalRevisionList struct {
+	Revisions []*JournalRevision `protobuf:"bytes,1,rep,name=revisions" json:"revisions,omitempty"`
+}
+
+func (m *JournalRevisionList) Reset()                    { *m = JournalRevisionList{} }
+func (m *JournalRevisionList) String() string            { return proto.CompactTextString(m) }
+func (*JournalRevisionList) ProtoMessage()               {}
+func (*JournalRevisionList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *JournalRevisionList) GetRevisions() []*JournalRevision {
+	if m != nil {
+		return m.Revisions
+	}
+	return nil
+}
+
+type ListTrashReq struct {
+	PhoneNumber string `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+}
+
+func (m *ListTrashReq) Reset()                    { *m = ListTrashReq{} }
+func (m *ListTrashReq) String() string            { return proto.CompactTextString(m) }
+func (*ListTrashReq) ProtoMessage()               {}
+func (*ListTrashReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *ListTrashReq) GetPhoneNumber() string {
+	if m != nil {
+		return m.PhoneNumber
+	}
+	return ""
+}
+
+type Trash struct {
+	Journals          []*Journal          `protobuf:"bytes,1,rep,name=journals" json:"journals,omitempty"`
+	UserNotifications []*UserNotification `protobuf:"bytes,2,rep,name=user_notifications,json=userNotifications" json:"user_notifications,omitempty"`
+}
+
+func (m *Trash) Reset()                    { *m = Trash{} }
+func (m *Trash) String() string            { return proto.CompactTextString(m) }
+func (*Trash) ProtoMessage()               {}
+func (*Trash) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *Trash) GetJournals() []*Journal {
+	if m != nil {
+		return m.Journals
+	}
+	return nil
+}
+
+func (m *Trash) GetUserNotifications() []*UserNotification {
+	if m != nil {
+		return m.UserNotifications
+	}
+	return nil
+}
+
+type ListTagsReq struct {
+	PhoneNumber string `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+}
+
+func (m *ListTagsReq) Reset()                    { *m = ListTagsReq{} }
+func (m *ListTagsReq) String() string            { return proto.CompactTextString(m) }
+func (*ListTagsReq) ProtoMessage()               {}
+func (*ListTagsReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *ListTagsReq) GetPhoneNumber() string {
+	if m != nil {
+		return m.PhoneNumber
+	}
+	return ""
+}
+
+type TagCount struct {
+	Name  string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Count int32  `protobuf:"varint,2,opt,name=count" json:"count,omitempty"`
+}
+
+func (m *TagCount) Reset()                    { *m = TagCount{} }
+func (m *TagCount) String() string            { return proto.CompactTextString(m) }
+func (*TagCount) ProtoMessage()               {}
+func (*TagCount) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *TagCount) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *TagCount) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type TagList struct {
+	Tags []*TagCount `protobuf:"bytes,1,rep,name=tags" json:"tags,omitempty"`
+}
+
+func (m *TagList) Reset()                    { *m = TagList{} }
+func (m *TagList) String() string            { return proto.CompactTextString(m) }
+func (*TagList) ProtoMessage()               {}
+func (*TagList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *TagList) GetTags() []*TagCount {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+type RotateUserKeyReq struct {
+	PhoneNumber string `protobuf:"bytes,1,opt,name=phone_number,json=phoneNumber" json:"phone_number,omitempty"`
+}
+
+func (m *RotateUserKeyReq) Reset()                    { *m = RotateUserKeyReq{} }
+func (m *RotateUserKeyReq) String() string            { return proto.CompactTextString(m) }
+func (*RotateUserKeyReq) ProtoMessage()               {}
+func (*RotateUserKeyReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *RotateUserKeyReq) GetPhoneNumber() string {
+	if m != nil {
+		return m.PhoneNumber
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*User)(nil), "notify.User")
 	proto.RegisterType((*CreateAccountReq)(nil), "notify.CreateAccountReq")
 	proto.RegisterType((*CreateAccountResp)(nil), "notify.CreateAccountResp")
 	proto.RegisterType((*UserNotification)(nil), "notify.UserNotification")
 	proto.RegisterType((*Notification)(nil), "notify.Notification")
+	proto.RegisterType((*NotificationList)(nil), "notify.NotificationList")
+	proto.RegisterType((*ListUserNotificationsReq)(nil), "notify.ListUserNotificationsReq")
+	proto.RegisterType((*UserNotificationList)(nil), "notify.UserNotificationList")
 	proto.RegisterType((*Communication)(nil), "notify.Communication")
+	proto.RegisterType((*ListCommunicationsReq)(nil), "notify.ListCommunicationsReq")
+	proto.RegisterType((*CommunicationList)(nil), "notify.CommunicationList")
 	proto.RegisterType((*Journal)(nil), "notify.Journal")
+	proto.RegisterType((*Attachment)(nil), "notify.Attachment")
+	proto.RegisterType((*ListJournalsReq)(nil), "notify.ListJournalsReq")
+	proto.RegisterType((*JournalList)(nil), "notify.JournalList")
+	proto.RegisterType((*SearchJournalsReq)(nil), "notify.SearchJournalsReq")
+	proto.RegisterType((*SearchHit)(nil), "notify.SearchHit")
+	proto.RegisterType((*SearchJournalsResp)(nil), "notify.SearchJournalsResp")
+	proto.RegisterType((*ExportReq)(nil), "notify.ExportReq")
+	proto.RegisterType((*ExportResp)(nil), "notify.ExportResp")
+	proto.RegisterType((*ApiToken)(nil), "notify.ApiToken")
+	proto.RegisterType((*ImportReq)(nil), "notify.ImportReq")
+	proto.RegisterType((*ImportRow)(nil), "notify.ImportRow")
+	proto.RegisterType((*ImportReport)(nil), "notify.ImportReport")
+	proto.RegisterType((*JournalRevision)(nil), "notify.JournalRevision")
+	proto.RegisterType((*JournalRevisionList)(nil), "notify.JournalRevisionList")
+	proto.RegisterType((*ListTrashReq)(nil), "notify.ListTrashReq")
+	proto.RegisterType((*Trash)(nil), "notify.Trash")
+	proto.RegisterType((*ListTagsReq)(nil), "notify.ListTagsReq")
+	proto.RegisterType((*TagCount)(nil), "notify.TagCount")
+	proto.RegisterType((*TagList)(nil), "notify.TagList")
+	proto.RegisterType((*RotateUserKeyReq)(nil), "notify.RotateUserKeyReq")
 }
 
 func init() { proto.RegisterFile("service.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 588 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xcd, 0x72, 0xd3, 0x3c,
-	0x14, 0x1d, 0xbb, 0x69, 0x7e, 0x6e, 0xd2, 0x7e, 0xfd, 0x44, 0xa6, 0xe3, 0x66, 0x60, 0xa6, 0x78,
-	0x43, 0x37, 0x64, 0x51, 0x58, 0xb0, 0x0d, 0xe5, 0x67, 0xc2, 0xa2, 0x0b, 0x4f, 0xd9, 0x30, 0xc3,
-	0x64, 0x1c, 0xfb, 0x26, 0x15, 0x13, 0x4b, 0xaa, 0x24, 0x17, 0x3c, 0x3c, 0x05, 0x6f, 0xc2, 0x53,
-	0xf0, 0x20, 0xac, 0x79, 0x08, 0x46, 0xb2, 0x95, 0xd8, 0x49, 0xb3, 0x61, 0xa7, 0x73, 0x8e, 0xac,
-	0x73, 0xef, 0xf5, 0x91, 0xe0, 0x48, 0xa1, 0xbc, 0xa7, 0x09, 0x8e, 0x85, 0xe4, 0x9a, 0x93, 0x36,
-	0xe3, 0x9a, 0x2e, 0x8a, 0x51, 0x1f, 0x33, 0xa1, 0x8b, 0x92, 0x0c, 0x7f, 0x7a, 0xd0, 0xfa, 0xa8,
-	0x50, 0x92, 0xa7, 0x30, 0x10, 0xb7, 0x9c, 0xe1, 0x8c, 0xe5, 0xd9, 0x1c, 0x65, 0xe0, 0x9d, 0x7b,
-	0x17, 0xbd, 0xa8, 0x6f, 0xb9, 0x6b, 0x4b, 0x91, 0x11, 0x74, 0x45, 0xac, 0xd4, 0x57, 0x2e, 0xd3,
-	0xc0, 0xb7, 0xf2, 0x1a, 0x13, 0x02, 0x2d, 0x16, 0x67, 0x18, 0x1c, 0x58, 0xde, 0xae, 0xcd, 0xfe,
-	0x39, 0x95, 0xfa, 0x36, 0x8d, 0x8b, 0xa0, 0x55, 0xee, 0x77, 0xd8, 0x68, 0xf7, 0x28, 0xe9, 0x82,
-	0x62, 0x1a, 0x1c, 0x9e, 0x7b, 0x17, 0xdd, 0x68, 0x8d, 0xc9, 0x13, 0x00, 0x85, 0x4a, 0x51, 0xce,
-	0x66, 0x34, 0x0d, 0xda, 0xf6, 0xcb, 0x5e, 0xc5, 0x4c, 0xd3, 0xf0, 0x33, 0x9c, 0x5c, 0x49, 0x8c,
-	0x35, 0x4e, 0x92, 0x84, 0xe7, 0x4c, 0x47, 0x78, 0x47, 0xce, 0xa1, 0x95, 0xab, 0xaa, 0xea, 0xfe,
-	0xe5, 0x60, 0x5c, 0xb6, 0x3a, 0x36, 0x9d, 0x45, 0x56, 0x21, 0xcf, 0xe0, 0x3f, 0x57, 0xec, 0x4c,
-	0xa2, 0xc0, 0x58, 0x57, 0x3d, 0x1c, 0x3b, 0x3a, 0xb2, 0x6c, 0xf8, 0x1c, 0xfe, 0xdf, 0x3a, 0x5e,
-	0x09, 0x12, 0x40, 0x47, 0xe5, 0x49, 0x82, 0x4a, 0x59, 0x8b, 0x6e, 0xe4, 0x60, 0xf8, 0xc7, 0x83,
-	0x13, 0x63, 0x73, 0x6d, 0x1c, 0x69, 0x12, 0x6b, 0xca, 0x99, 0x31, 0x63, 0x35, 0x6c, 0xda, 0x28,
-	0xe7, 0x79, 0x5c, 0xa7, 0xa7, 0xe9, 0xce, 0xd4, 0xfd, 0xdd, 0xa9, 0xbf, 0x84, 0x53, 0x86, 0xdf,
-	0xf4, 0xac, 0x71, 0xa0, 0xa6, 0xeb, 0x59, 0x0f, 0x8d, 0x5a, 0x77, 0xbf, 0xa1, 0x19, 0x92, 0xc7,
-	0xd0, 0x5b, 0x48, 0xbc, 0xcb, 0x91, 0x25, 0x6e, 0xf8, 0x1b, 0x82, 0xbc, 0x82, 0x41, 0xfd, 0x38,
-	0xfb, 0x07, 0xfa, 0x97, 0x43, 0x37, 0xb6, 0xfa, 0x69, 0x51, 0x63, 0x67, 0xf8, 0x1d, 0x06, 0xff,
-	0xd6, 0xa9, 0x0b, 0x88, 0x5f, 0x0b, 0x08, 0x81, 0x96, 0x2e, 0xc4, 0x3a, 0x34, 0x66, 0x6d, 0x82,
-	0xa1, 0x31, 0x13, 0xab, 0x58, 0xa3, 0x0b, 0x8d, 0xc3, 0xe1, 0x0f, 0x0f, 0x8e, 0xae, 0x78, 0x96,
-	0xe5, 0xcc, 0xd9, 0x9f, 0x41, 0x37, 0xe1, 0x59, 0xa6, 0x36, 0xbe, 0x1d, 0x8b, 0x4b, 0xc3, 0x85,
-	0xe4, 0x99, 0x33, 0x34, 0x6b, 0x72, 0x0c, 0xbe, 0xe6, 0x95, 0x9d, 0xaf, 0xb9, 0xf9, 0xad, 0x19,
-	0x2a, 0x15, 0x2f, 0x9d, 0x97, 0x83, 0x0f, 0xf5, 0x75, 0xf8, 0x50, 0x5f, 0xe1, 0x2f, 0x0f, 0x3a,
-	0x1f, 0x78, 0x2e, 0x59, 0xbc, 0x32, 0xc1, 0xfd, 0x52, 0x2e, 0x37, 0xf5, 0xf4, 0x2a, 0x66, 0x9a,
-	0x36, 0x8a, 0xf5, 0x9b, 0xc5, 0x6e, 0xe7, 0xe0, 0x60, 0x37, 0x07, 0x43, 0x38, 0xd4, 0x54, 0xaf,
-	0x5c, 0xa5, 0x25, 0x30, 0x2c, 0x32, 0x2d, 0x8b, 0xaa, 0xba, 0x12, 0x98, 0xbe, 0x12, 0x9b, 0x61,
-	0x77, 0x7d, 0x1c, 0x34, 0x4a, 0x2e, 0x52, 0xab, 0x74, 0x4a, 0xa5, 0x82, 0x97, 0xbf, 0x3d, 0xe8,
-	0xd9, 0x5f, 0x5b, 0x4c, 0x84, 0x20, 0x6f, 0xe0, 0xa8, 0x71, 0x0b, 0x48, 0xe0, 0xc2, 0xb1, 0x7d,
-	0xf7, 0x46, 0x67, 0x7b, 0x14, 0x25, 0xc8, 0x7b, 0x78, 0x34, 0x49, 0xd3, 0x9d, 0xeb, 0x11, 0xd4,
-	0xef, 0x67, 0x5d, 0x19, 0x9d, 0x8e, 0x97, 0x9c, 0x2f, 0x57, 0xd5, 0x93, 0x35, 0xcf, 0x17, 0xe3,
-	0xb7, 0xe6, 0xb1, 0x22, 0xef, 0x60, 0x78, 0x23, 0xe9, 0x72, 0xd9, 0xdc, 0xae, 0xc8, 0x9e, 0xfd,
-	0xfb, 0xce, 0x79, 0xdd, 0xfd, 0xd4, 0x36, 0x8f, 0x22, 0xca, 0x79, 0xdb, 0x2a, 0x2f, 0xfe, 0x06,
-	0x00, 0x00, 0xff, 0xff, 0x5c, 0xdc, 0xa4, 0x51, 0x25, 0x05, 0x00, 0x00,
+	// 1833 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x58, 0x4b, 0x8f, 0xe4, 0x48,
+	0x11, 0x96, 0xeb, 0xed, 0xa8, 0xc7, 0x74, 0xe7, 0xd4, 0xf6, 0x78, 0x6a, 0x76, 0xa1, 0xd7, 0xb0,
+	0x62, 0x11, 0xa2, 0x99, 0x6d, 0x66, 0xc5, 0xf2, 0x58, 0x89, 0x9e, 0xc7, 0x36, 0xbd, 0x0c, 0x7d,
+	0xf0, 0x54, 0x0b, 0x09, 0x0e, 0x25, 0xb7, 0x9d, 0xe5, 0x36, 0x54, 0x39, 0x3d, 0x99, 0xe9, 0xee,
+	0xad, 0x95, 0xb8, 0xec, 0x81, 0x3f, 0xc0, 0x0d, 0x71, 0x41, 0x70, 0x42, 0xe2, 0x47, 0xf0, 0x33,
+	0x38, 0x70, 0xe3, 0x87, 0xa0, 0x7c, 0xb9, 0x6c, 0x57, 0x99, 0xa9, 0x1e, 0xf5, 0x65, 0x2f, 0x56,
+	0x46, 0x44, 0x3e, 0x22, 0xbe, 0x78, 0x64, 0xa4, 0x61, 0xc8, 0x30, 0xbd, 0x8e, 0x03, 0x7c, 0x94,
+	0x52, 0xc2, 0x09, 0xea, 0x24, 0x84, 0xc7, 0xf3, 0xd5, 0xa4, 0x8f, 0x97, 0x29, 0x5f, 0x29, 0xa6,
+	0xfb, 0x55, 0x03, 0x5a, 0x17, 0x0c, 0x53, 0xf4, 0x3e, 0x0c, 0xd2, 0x2b, 0x92, 0xe0, 0x59, 0x92,
+	0x2d, 0x2f, 0x31, 0x75, 0xac, 0x43, 0xeb, 0x43, 0xdb, 0xeb, 0x4b, 0xde, 0xb9, 0x64, 0xa1, 0x09,
+	0xf4, 0x52, 0x9f, 0xb1, 0x1b, 0x42, 0x43, 0xa7, 0x21, 0xc5, 0x39, 0x8d, 0x10, 0xb4, 0x12, 0x7f,
+	0x89, 0x9d, 0xa6, 0xe4, 0xcb, 0xb1, 0x98, 0x7f, 0x19, 0x53, 0x7e, 0x15, 0xfa, 0x2b, 0xa7, 0xa5,
+	0xe6, 0x1b, 0x5a, 0xc8, 0xae, 0x31, 0x8d, 0xe7, 0x31, 0x0e, 0x9d, 0xf6, 0xa1, 0xf5, 0x61, 0xcf,
+	0xcb, 0x69, 0xf4, 0x1e, 0x00, 0xc3, 0x8c, 0xc5, 0x24, 0x99, 0xc5, 0xa1, 0xd3, 0x91, 0x2b, 0x6d,
+	0xcd, 0x39, 0x93, 0x47, 0x51, 0xb2, 0xc0, 0x4e, 0x57, 0x1d, 0x25, 0xc6, 0x62, 0x49, 0xc0, 0xe8,
+	0x7c, 0xc6, 0xc9, 0xef, 0x71, 0xe2, 0xf4, 0xd4, 0x12, 0xc1, 0x99, 0x0a, 0x86, 0x10, 0xf3, 0x1b,
+	0x32, 0x9b, 0xfb, 0x01, 0x27, 0xd4, 0xb1, 0xe5, 0x79, 0x36, 0xbf, 0x21, 0x9f, 0x49, 0x86, 0x9b,
+	0xc1, 0xde, 0x33, 0x8a, 0x7d, 0x8e, 0x4f, 0x82, 0x80, 0x64, 0x09, 0xf7, 0xf0, 0x6b, 0x74, 0x08,
+	0xad, 0x8c, 0x69, 0x1c, 0xfa, 0xc7, 0x83, 0x23, 0x05, 0xde, 0x91, 0xc0, 0xca, 0x93, 0x12, 0xf4,
+	0x1d, 0xb8, 0x67, 0xcc, 0x9f, 0x51, 0x9c, 0x62, 0x9f, 0x6b, 0x54, 0x46, 0x86, 0xed, 0x49, 0x2e,
+	0x3a, 0x80, 0x0e, 0xc5, 0x51, 0x4c, 0x12, 0x8d, 0x8e, 0xa6, 0xdc, 0xef, 0xc3, 0x7e, 0xe5, 0x58,
+	0x96, 0x22, 0x07, 0xba, 0x2c, 0x0b, 0x02, 0xcc, 0x98, 0x3c, 0xba, 0xe7, 0x19, 0xd2, 0xfd, 0x63,
+	0x03, 0xf6, 0xc4, 0xf1, 0xe7, 0x42, 0x93, 0x38, 0xf0, 0x79, 0x4c, 0x12, 0xa1, 0x44, 0x52, 0xa0,
+	0x05, 0x60, 0xca, 0x73, 0xa3, 0x22, 0xfb, 0x2c, 0xdc, 0xf0, 0x6f, 0x63, 0xd3, 0xbf, 0x4f, 0xe0,
+	0x20, 0xc1, 0x5f, 0xf0, 0x59, 0x69, 0x43, 0x1e, 0xe7, 0x5e, 0x1d, 0x0b, 0x69, 0xf1, 0xf4, 0x69,
+	0xbc, 0xc4, 0xe8, 0x5d, 0xb0, 0xe7, 0x14, 0xbf, 0xce, 0x70, 0x12, 0x18, 0x37, 0xaf, 0x19, 0xe8,
+	0x13, 0x18, 0x14, 0xb7, 0x93, 0xbe, 0xee, 0x1f, 0x8f, 0x0d, 0x9c, 0xc5, 0xdd, 0xbc, 0xd2, 0x4c,
+	0x01, 0x44, 0x88, 0x17, 0x98, 0x63, 0x13, 0x02, 0x86, 0x74, 0xff, 0x62, 0xc1, 0xe0, 0xed, 0x40,
+	0x30, 0x51, 0xda, 0x28, 0x44, 0x29, 0x82, 0x16, 0x5f, 0xa5, 0x79, 0xe4, 0x8a, 0xb1, 0x88, 0x4e,
+	0x8e, 0x97, 0xe9, 0xc2, 0xe7, 0xd8, 0x44, 0xae, 0xa1, 0xd1, 0x37, 0xa1, 0x1f, 0xe2, 0xb9, 0x9f,
+	0x2d, 0xf8, 0x8c, 0xfb, 0x91, 0x34, 0xc8, 0xf6, 0x40, 0xb3, 0xa6, 0x7e, 0xe4, 0x9e, 0xc3, 0x5e,
+	0x51, 0xbb, 0x97, 0x31, 0xe3, 0xe8, 0x27, 0x30, 0x2c, 0xaa, 0x22, 0x7c, 0xdb, 0xac, 0xc5, 0xa1,
+	0x3c, 0xd5, 0xfd, 0x14, 0x1c, 0xb1, 0x47, 0xd5, 0xf5, 0x4c, 0x44, 0xe9, 0x9b, 0xb3, 0xd6, 0x9d,
+	0xc1, 0xb8, 0xba, 0x54, 0xaa, 0x74, 0x0a, 0x48, 0x84, 0xf1, 0x6c, 0x9b, 0x5e, 0x4e, 0x31, 0xdc,
+	0x4b, 0xba, 0xed, 0x67, 0x55, 0x35, 0xdc, 0x7f, 0x5b, 0x30, 0x7c, 0x46, 0x96, 0xcb, 0x2c, 0x31,
+	0xfe, 0x78, 0x08, 0xbd, 0x80, 0x2c, 0x97, 0x6c, 0xed, 0x88, 0xae, 0xa4, 0x95, 0x07, 0xe6, 0x94,
+	0x2c, 0x8d, 0x07, 0xc4, 0x18, 0x8d, 0xa0, 0xc1, 0x89, 0xc6, 0xbf, 0xc1, 0x89, 0xf0, 0xfc, 0x12,
+	0x33, 0xe6, 0x47, 0x06, 0x7c, 0x43, 0x6e, 0x73, 0x74, 0x7b, 0xab, 0xa3, 0x1d, 0xe8, 0x06, 0x32,
+	0xb5, 0xf2, 0xe0, 0xd1, 0xa4, 0x48, 0x46, 0xc6, 0x7d, 0x9e, 0x31, 0x5d, 0x3f, 0x34, 0x85, 0xc6,
+	0xd0, 0xc6, 0x94, 0x12, 0xaa, 0x8b, 0x87, 0x22, 0xdc, 0xff, 0x5a, 0xf0, 0x8e, 0x40, 0xab, 0x64,
+	0xdf, 0x8e, 0xc8, 0xa3, 0x47, 0x60, 0xa7, 0x7e, 0x84, 0x67, 0x2c, 0xfe, 0x52, 0x85, 0x5c, 0x5b,
+	0x14, 0xcc, 0x08, 0xbf, 0x8a, 0xbf, 0xc4, 0x42, 0x8f, 0x20, 0xa3, 0x8c, 0x50, 0x53, 0x14, 0x14,
+	0x85, 0xbe, 0x05, 0x43, 0xad, 0xea, 0xcc, 0x9f, 0x73, 0x4c, 0x35, 0x04, 0x03, 0xcd, 0x3c, 0x11,
+	0x3c, 0xf4, 0x01, 0x8c, 0xcc, 0xa4, 0x4b, 0x3c, 0x27, 0x14, 0x6b, 0x18, 0xcc, 0xd2, 0xa7, 0x92,
+	0xb9, 0x0d, 0xae, 0xce, 0x36, 0xb8, 0x5c, 0x06, 0xfb, 0x25, 0x0b, 0x65, 0x80, 0x7c, 0x0a, 0xa3,
+	0xa0, 0x64, 0xb6, 0x0e, 0x8e, 0x77, 0x4c, 0x70, 0x94, 0x96, 0x78, 0x95, 0xc9, 0x22, 0x4f, 0x64,
+	0x35, 0xd1, 0x56, 0x2a, 0x87, 0x83, 0x60, 0x3d, 0x93, 0x1c, 0xf7, 0x1f, 0x0d, 0xe8, 0x7e, 0x4e,
+	0x32, 0x9a, 0xf8, 0x0b, 0x51, 0xa0, 0x7f, 0xa7, 0x86, 0xeb, 0x98, 0xb1, 0x35, 0xe7, 0x2c, 0x2c,
+	0x05, 0x54, 0xa3, 0x1c, 0x50, 0x55, 0x3f, 0x34, 0x37, 0xfd, 0x30, 0x86, 0x36, 0x8f, 0xf9, 0xc2,
+	0x44, 0x93, 0x22, 0x04, 0x17, 0x27, 0x9c, 0xae, 0x34, 0x74, 0x8a, 0xf8, 0x3f, 0x81, 0xe3, 0x40,
+	0x37, 0x4b, 0x43, 0x29, 0x51, 0x91, 0x63, 0xc8, 0x62, 0xa5, 0xea, 0x95, 0x2a, 0x95, 0xac, 0x2d,
+	0x7e, 0xc4, 0x1c, 0xfb, 0xb0, 0x29, 0x6b, 0x8b, 0x1f, 0x31, 0xf4, 0x04, 0xfa, 0x3e, 0xe7, 0x7e,
+	0x70, 0xb5, 0xc4, 0x09, 0x67, 0x0e, 0x48, 0x4c, 0x91, 0xc1, 0xf4, 0x24, 0x17, 0x79, 0xc5, 0x69,
+	0xee, 0x5f, 0x2d, 0x80, 0xb5, 0x4c, 0x44, 0xc9, 0x5a, 0xba, 0x86, 0x6c, 0xb0, 0x66, 0x9e, 0x85,
+	0x15, 0x50, 0x1b, 0x55, 0x50, 0xdf, 0x87, 0x41, 0x40, 0x12, 0x2e, 0x36, 0x28, 0x14, 0xc0, 0xbe,
+	0xe6, 0x4d, 0x45, 0x1d, 0x44, 0xd0, 0x92, 0xc1, 0x2b, 0x80, 0x6b, 0x7a, 0x72, 0x5c, 0x44, 0xa8,
+	0x5d, 0x42, 0xc8, 0xfd, 0x8f, 0x05, 0xf7, 0x44, 0xe4, 0x68, 0xa7, 0x7e, 0x6d, 0xd2, 0x24, 0x8f,
+	0x8f, 0x4e, 0x31, 0x3e, 0xf6, 0xa0, 0x29, 0xea, 0xbb, 0xf2, 0xb5, 0x18, 0xba, 0xbf, 0x85, 0xbe,
+	0x36, 0x4d, 0xe6, 0xc7, 0xf7, 0xa0, 0xa7, 0xc1, 0x34, 0x99, 0x71, 0xcf, 0x78, 0x51, 0x4f, 0xf3,
+	0xf2, 0x09, 0x6f, 0xce, 0x86, 0x18, 0xf6, 0x5f, 0x61, 0x9f, 0x06, 0x57, 0xb7, 0x44, 0x6f, 0x0c,
+	0xed, 0xd7, 0x19, 0xa6, 0x2b, 0xbd, 0xa5, 0x22, 0xca, 0x98, 0x36, 0xcb, 0x98, 0xba, 0x73, 0xb0,
+	0xd5, 0x51, 0xbf, 0x88, 0x39, 0xfa, 0x2e, 0x74, 0xb5, 0x92, 0xba, 0xd5, 0xd9, 0x30, 0xc2, 0xc8,
+	0xc5, 0x51, 0x2c, 0x10, 0x28, 0x8a, 0xa3, 0x2c, 0x4f, 0x11, 0xb2, 0x61, 0x49, 0xe2, 0x34, 0xc5,
+	0x5c, 0xbb, 0xc8, 0x90, 0xee, 0x4f, 0x01, 0x55, 0x4d, 0x62, 0x29, 0xfa, 0x00, 0x5a, 0x57, 0x31,
+	0x37, 0x90, 0xed, 0x9b, 0xd3, 0x72, 0x8d, 0x3c, 0x29, 0x76, 0xff, 0x69, 0x81, 0xfd, 0xe2, 0x8b,
+	0x94, 0x50, 0xbe, 0x23, 0x10, 0x07, 0xd0, 0x99, 0x13, 0xba, 0xcc, 0xbb, 0x30, 0x4d, 0x89, 0xda,
+	0x11, 0x51, 0x92, 0xa5, 0xb3, 0xcb, 0x95, 0x51, 0x50, 0xd2, 0x4f, 0x57, 0x77, 0x19, 0x44, 0xee,
+	0x57, 0x16, 0x80, 0xd1, 0x97, 0xa5, 0xa2, 0x83, 0x98, 0xc7, 0x0b, 0x2c, 0xbb, 0x0d, 0xa5, 0x6c,
+	0x4e, 0x6f, 0x24, 0x5e, 0x63, 0x6b, 0xe2, 0x85, 0x3e, 0xf7, 0xa5, 0xc2, 0x03, 0x4f, 0x8e, 0xc5,
+	0xb2, 0x90, 0xdc, 0x24, 0x0b, 0xe2, 0x87, 0xb3, 0x8c, 0x2e, 0xb4, 0xb2, 0x7d, 0xc3, 0xbb, 0xa0,
+	0x0b, 0xf7, 0x4f, 0x16, 0xf4, 0x4e, 0xd2, 0x58, 0x35, 0xbd, 0x0f, 0xa1, 0x27, 0xdb, 0xe1, 0xc2,
+	0x2d, 0x2c, 0xe9, 0xdd, 0x9a, 0xc1, 0x6d, 0x0d, 0x7d, 0x21, 0xf5, 0x5b, 0xe5, 0xe2, 0xf8, 0x08,
+	0xec, 0x85, 0xcf, 0xf8, 0x2c, 0x63, 0x79, 0x59, 0xe8, 0x09, 0xc6, 0x05, 0xc3, 0xe2, 0x76, 0xb1,
+	0xcf, 0x96, 0x77, 0xe0, 0xc9, 0x6d, 0xa0, 0x3c, 0x80, 0x6e, 0x48, 0x57, 0x33, 0x9a, 0x25, 0x52,
+	0xa5, 0x9e, 0xd7, 0x09, 0xe9, 0xca, 0xcb, 0x12, 0xf7, 0xcf, 0x56, 0x7e, 0x2a, 0xb9, 0x11, 0xc9,
+	0x4c, 0xc9, 0x8d, 0x3c, 0xac, 0xed, 0x89, 0xe1, 0x3a, 0xe9, 0x1b, 0xc5, 0xa4, 0x2f, 0x58, 0xd8,
+	0xac, 0xeb, 0x1b, 0x5a, 0xdb, 0xfb, 0x86, 0x76, 0xa1, 0x6f, 0xa8, 0x94, 0xde, 0x4e, 0xa5, 0xf4,
+	0xba, 0xff, 0xb2, 0x60, 0x60, 0x20, 0x11, 0x5f, 0x81, 0x5f, 0x2c, 0xe9, 0xb5, 0xb3, 0x7a, 0x8a,
+	0x71, 0x16, 0x16, 0x6d, 0x6c, 0x14, 0x6d, 0x14, 0x41, 0xa6, 0x26, 0x69, 0x75, 0xdb, 0x5e, 0x4e,
+	0xa3, 0x6f, 0x00, 0x84, 0x59, 0xba, 0x10, 0xd7, 0x31, 0x56, 0x3a, 0xb7, 0xbd, 0x02, 0x47, 0x58,
+	0x1a, 0x27, 0xd7, 0xfe, 0x42, 0xb7, 0x50, 0x6d, 0xcf, 0x90, 0x22, 0x41, 0x29, 0xb9, 0x61, 0x4e,
+	0xa7, 0x9c, 0xa0, 0x39, 0x98, 0x9e, 0x14, 0xbb, 0x7f, 0xb7, 0xe0, 0x9e, 0x29, 0x11, 0xf8, 0x3a,
+	0x66, 0x31, 0x49, 0x44, 0x95, 0xa3, 0x7a, 0xbc, 0x36, 0x04, 0x0c, 0x6b, 0xa7, 0x2b, 0x69, 0x87,
+	0xcb, 0x5c, 0x5d, 0xdb, 0xad, 0x9a, 0x6b, 0xbb, 0x72, 0x29, 0xbd, 0x84, 0xfb, 0x15, 0x2d, 0x65,
+	0xf1, 0xfe, 0x18, 0x6c, 0xa3, 0x96, 0x29, 0x45, 0x0f, 0xaa, 0x85, 0x4f, 0xcb, 0xbd, 0xf5, 0x4c,
+	0xf7, 0x23, 0x18, 0x88, 0xe5, 0x53, 0xea, 0xb3, 0xab, 0x1d, 0xfb, 0xef, 0x3f, 0x40, 0x5b, 0x4e,
+	0xbf, 0xdd, 0x7d, 0xb1, 0xbd, 0x3b, 0x6f, 0xdc, 0xbe, 0x3b, 0x7f, 0x0c, 0x7d, 0xa9, 0xb1, 0x1f,
+	0xed, 0xfa, 0x60, 0x78, 0x02, 0xbd, 0xa9, 0x1f, 0x3d, 0x23, 0x59, 0xc2, 0xf3, 0x2a, 0x60, 0x15,
+	0xaa, 0xc0, 0x18, 0xda, 0xf2, 0xb9, 0xaa, 0xef, 0x6a, 0x45, 0xb8, 0x3f, 0x80, 0xee, 0xd4, 0x8f,
+	0x24, 0xb6, 0xdf, 0xd6, 0x5d, 0x8f, 0x32, 0x72, 0xcf, 0x68, 0x6b, 0x36, 0x55, 0x7d, 0x90, 0xfb,
+	0x31, 0xec, 0x79, 0x84, 0xfb, 0x1c, 0x0b, 0x2b, 0x7e, 0x89, 0x57, 0xbb, 0x69, 0x77, 0xfc, 0xb7,
+	0x21, 0xd8, 0xd2, 0xc2, 0xd5, 0x49, 0x9a, 0xa2, 0xe7, 0x30, 0x2c, 0x3d, 0xa1, 0x51, 0x8e, 0x4d,
+	0xf5, 0x41, 0x3f, 0x79, 0x58, 0x23, 0x61, 0x29, 0x3a, 0x85, 0xfb, 0x27, 0x61, 0xb8, 0xf1, 0xb6,
+	0xae, 0xc5, 0x79, 0x72, 0x70, 0x14, 0x11, 0x12, 0x2d, 0xf4, 0x9f, 0x95, 0xcb, 0x6c, 0x7e, 0xf4,
+	0x42, 0xfc, 0x53, 0x41, 0x9f, 0xc1, 0x78, 0x4a, 0xe3, 0x28, 0xaa, 0x38, 0x01, 0xd5, 0xcc, 0xaf,
+	0xdd, 0xe7, 0x05, 0xec, 0x0b, 0x24, 0x77, 0xdb, 0xc4, 0xd9, 0xf6, 0x88, 0x94, 0x8e, 0xf8, 0x39,
+	0x20, 0x65, 0x6c, 0xc9, 0xac, 0xad, 0x8f, 0xce, 0xc9, 0x56, 0xae, 0xd8, 0xe1, 0x42, 0x76, 0xb9,
+	0x6f, 0xbd, 0xc3, 0xaf, 0xd5, 0x03, 0x6a, 0xe3, 0xf5, 0x8a, 0x0e, 0xcd, 0xf4, 0xba, 0xc7, 0xed,
+	0xe4, 0xdd, 0x3a, 0xfc, 0xa5, 0x71, 0x2f, 0xe1, 0x40, 0xa9, 0x76, 0x0b, 0xbf, 0xd5, 0x4a, 0xd0,
+	0xe7, 0x70, 0xf0, 0x5c, 0x36, 0xed, 0x77, 0x10, 0x05, 0xbf, 0x82, 0x07, 0x1e, 0x66, 0x9c, 0xd0,
+	0xbb, 0x51, 0xed, 0x67, 0xaa, 0xe6, 0x98, 0x26, 0x0a, 0x3d, 0x28, 0x02, 0x57, 0xe8, 0x16, 0x27,
+	0xf7, 0x2b, 0xe5, 0x44, 0xc2, 0x74, 0x04, 0x70, 0x8a, 0xcd, 0x34, 0x54, 0xad, 0x38, 0x93, 0x2a,
+	0x03, 0x7d, 0x64, 0x32, 0xea, 0x56, 0x4b, 0x94, 0x27, 0x76, 0x5f, 0xf2, 0x09, 0x0c, 0x15, 0xdc,
+	0xb5, 0x4b, 0xea, 0xc0, 0x7d, 0x0e, 0xe3, 0x82, 0xdd, 0xa6, 0x46, 0xb3, 0xcd, 0x0d, 0x1e, 0xd5,
+	0x94, 0x73, 0x89, 0xca, 0x53, 0x38, 0xd0, 0x2e, 0xaa, 0x48, 0x51, 0xdd, 0x2d, 0xb0, 0x69, 0xc3,
+	0x31, 0x8c, 0xca, 0x7b, 0xec, 0x60, 0xf7, 0x63, 0xb0, 0xf3, 0xfb, 0x63, 0x9d, 0x46, 0xc5, 0x2b,
+	0x65, 0x32, 0xcc, 0xeb, 0xa5, 0x9c, 0xf4, 0x18, 0x7a, 0xa6, 0x7e, 0xa3, 0xfb, 0xa5, 0x05, 0xaa,
+	0xa2, 0xaf, 0xcf, 0x30, 0xe5, 0xf7, 0x18, 0x46, 0xaf, 0x72, 0x8f, 0xcb, 0x75, 0x6f, 0xd6, 0xeb,
+	0x1c, 0xd0, 0xe6, 0x6f, 0x0e, 0xf4, 0x5e, 0xf1, 0xbc, 0x8d, 0x5f, 0x20, 0x85, 0x8a, 0xba, 0xf1,
+	0xef, 0xe0, 0x14, 0x46, 0xaa, 0xa1, 0xcf, 0xa3, 0xf6, 0x61, 0xb9, 0xd1, 0x2f, 0xc6, 0xed, 0xa4,
+	0x4e, 0xc4, 0x52, 0xf4, 0x23, 0x18, 0xa9, 0xae, 0x3a, 0xdf, 0x28, 0x6f, 0x48, 0xf2, 0xd7, 0xc1,
+	0x04, 0x55, 0x59, 0x2c, 0x45, 0x3f, 0x86, 0xd1, 0xd9, 0x72, 0xfb, 0xc2, 0xbc, 0x19, 0x9d, 0x8c,
+	0xab, 0x2c, 0xf1, 0x45, 0x27, 0x30, 0x2c, 0xdd, 0x4c, 0xeb, 0xac, 0xad, 0x5e, 0x58, 0x75, 0x51,
+	0xfa, 0xb4, 0xf7, 0x9b, 0x8e, 0xf8, 0xf9, 0x8e, 0xe9, 0x65, 0x47, 0x4a, 0x7e, 0xf8, 0xbf, 0x01,
+	0x00, 0xae, 0x1d, 0xa3, 0xbb, 0x8d, 0x17, 0x00, 0x00,
 }
@@ -40,6 +40,52 @@ type NotifyApp interface {
 	AddUserNotification(context.Context, *UserNotification) (*google_protobuf.Empty, error)
 
 	TriggerNotifications(context.Context, *google_protobuf.Empty) (*google_protobuf.Empty, error)
+
+	ListNotifications(context.Context, *google_protobuf.Empty) (*NotificationList, error)
+
+	CreateNotification(context.Context, *Notification) (*Notification, error)
+
+	UpdateNotification(context.Context, *Notification) (*Notification, error)
+
+	ListUserNotifications(context.Context, *ListUserNotificationsReq) (*UserNotificationList, error)
+
+	UpdateUserNotification(context.Context, *UserNotification) (*UserNotification, error)
+
+	DeleteUserNotification(context.Context, *UserNotification) (*google_protobuf.Empty, error)
+
+	RestoreUserNotification(context.Context, *UserNotification) (*UserNotification, error)
+
+	ListJournals(context.Context, *ListJournalsReq) (*JournalList, error)
+
+	GetJournal(context.Context, *Journal) (*Journal, error)
+
+	CreateJournal(context.Context, *Journal) (*Journal, error)
+
+	UpdateJournal(context.Context, *Journal) (*Journal, error)
+
+	DeleteJournal(context.Context, *Journal) (*google_protobuf.Empty, error)
+
+	ListJournalRevisions(context.Context, *Journal) (*JournalRevisionList, error)
+
+	RestoreJournalRevision(context.Context, *JournalRevision) (*Journal, error)
+
+	RestoreJournal(context.Context, *Journal) (*Journal, error)
+
+	ListTrash(context.Context, *ListTrashReq) (*Trash, error)
+
+	ListTags(context.Context, *ListTagsReq) (*TagList, error)
+
+	SetJournalTags(context.Context, *Journal) (*Journal, error)
+
+	ListCommunications(context.Context, *ListCommunicationsReq) (*CommunicationList, error)
+
+	SearchJournals(context.Context, *SearchJournalsReq) (*SearchJournalsResp, error)
+
+	ExportJournals(context.Context, *ExportReq) (*ExportResp, error)
+
+	ImportJournals(context.Context, *ImportReq) (*ImportReport, error)
+
+	RotateUserKey(context.Context, *RotateUserKeyReq) (*google_protobuf.Empty, error)
 }
 
 // =========================
@@ -81,6 +127,167 @@ func (c *notifyAppProtobufClient) TriggerNotifications(ctx context.Context, in *
 	return out, err
 }
 
+func (c *notifyAppProtobufClient) ListNotifications(ctx context.Context, in *google_protobuf.Empty) (*NotificationList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListNotifications"
+	out := new(NotificationList)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) CreateNotification(ctx context.Context, in *Notification) (*Notification, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "CreateNotification"
+	out := new(Notification)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) UpdateNotification(ctx context.Context, in *Notification) (*Notification, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "UpdateNotification"
+	out := new(Notification)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) ListUserNotifications(ctx context.Context, in *ListUserNotificationsReq) (*UserNotificationList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListUserNotifications"
+	out := new(UserNotificationList)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) UpdateUserNotification(ctx context.Context, in *UserNotification) (*UserNotification, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "UpdateUserNotification"
+	out := new(UserNotification)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) DeleteUserNotification(ctx context.Context, in *UserNotification) (*google_protobuf.Empty, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "DeleteUserNotification"
+	out := new(google_protobuf.Empty)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) RestoreUserNotification(ctx context.Context, in *UserNotification) (*UserNotification, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "RestoreUserNotification"
+	out := new(UserNotification)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) ListJournals(ctx context.Context, in *ListJournalsReq) (*JournalList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListJournals"
+	out := new(JournalList)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) GetJournal(ctx context.Context, in *Journal) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "GetJournal"
+	out := new(Journal)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) CreateJournal(ctx context.Context, in *Journal) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "CreateJournal"
+	out := new(Journal)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) UpdateJournal(ctx context.Context, in *Journal) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "UpdateJournal"
+	out := new(Journal)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) DeleteJournal(ctx context.Context, in *Journal) (*google_protobuf.Empty, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "DeleteJournal"
+	out := new(google_protobuf.Empty)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) ListJournalRevisions(ctx context.Context, in *Journal) (*JournalRevisionList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListJournalRevisions"
+	out := new(JournalRevisionList)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) RestoreJournalRevision(ctx context.Context, in *JournalRevision) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "RestoreJournalRevision"
+	out := new(Journal)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) RestoreJournal(ctx context.Context, in *Journal) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "RestoreJournal"
+	out := new(Journal)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) ListTrash(ctx context.Context, in *ListTrashReq) (*Trash, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListTrash"
+	out := new(Trash)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) ListTags(ctx context.Context, in *ListTagsReq) (*TagList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListTags"
+	out := new(TagList)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) SetJournalTags(ctx context.Context, in *Journal) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "SetJournalTags"
+	out := new(Journal)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) ListCommunications(ctx context.Context, in *ListCommunicationsReq) (*CommunicationList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListCommunications"
+	out := new(CommunicationList)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) SearchJournals(ctx context.Context, in *SearchJournalsReq) (*SearchJournalsResp, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "SearchJournals"
+	out := new(SearchJournalsResp)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) ExportJournals(ctx context.Context, in *ExportReq) (*ExportResp, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ExportJournals"
+	out := new(ExportResp)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) ImportJournals(ctx context.Context, in *ImportReq) (*ImportReport, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ImportJournals"
+	out := new(ImportReport)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppProtobufClient) RotateUserKey(ctx context.Context, in *RotateUserKeyReq) (*google_protobuf.Empty, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "RotateUserKey"
+	out := new(google_protobuf.Empty)
+	err := doProtoRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
 // =====================
 // NotifyApp JSON Client
 // =====================
@@ -120,6 +327,167 @@ func (c *notifyAppJSONClient) TriggerNotifications(ctx context.Context, in *goog
 	return out, err
 }
 
+func (c *notifyAppJSONClient) ListNotifications(ctx context.Context, in *google_protobuf.Empty) (*NotificationList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListNotifications"
+	out := new(NotificationList)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) CreateNotification(ctx context.Context, in *Notification) (*Notification, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "CreateNotification"
+	out := new(Notification)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) UpdateNotification(ctx context.Context, in *Notification) (*Notification, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "UpdateNotification"
+	out := new(Notification)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) ListUserNotifications(ctx context.Context, in *ListUserNotificationsReq) (*UserNotificationList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListUserNotifications"
+	out := new(UserNotificationList)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) UpdateUserNotification(ctx context.Context, in *UserNotification) (*UserNotification, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "UpdateUserNotification"
+	out := new(UserNotification)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) DeleteUserNotification(ctx context.Context, in *UserNotification) (*google_protobuf.Empty, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "DeleteUserNotification"
+	out := new(google_protobuf.Empty)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) RestoreUserNotification(ctx context.Context, in *UserNotification) (*UserNotification, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "RestoreUserNotification"
+	out := new(UserNotification)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) ListJournals(ctx context.Context, in *ListJournalsReq) (*JournalList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListJournals"
+	out := new(JournalList)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) GetJournal(ctx context.Context, in *Journal) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "GetJournal"
+	out := new(Journal)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) CreateJournal(ctx context.Context, in *Journal) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "CreateJournal"
+	out := new(Journal)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) UpdateJournal(ctx context.Context, in *Journal) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "UpdateJournal"
+	out := new(Journal)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) DeleteJournal(ctx context.Context, in *Journal) (*google_protobuf.Empty, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "DeleteJournal"
+	out := new(google_protobuf.Empty)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) ListJournalRevisions(ctx context.Context, in *Journal) (*JournalRevisionList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListJournalRevisions"
+	out := new(JournalRevisionList)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) RestoreJournalRevision(ctx context.Context, in *JournalRevision) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "RestoreJournalRevision"
+	out := new(Journal)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) RestoreJournal(ctx context.Context, in *Journal) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "RestoreJournal"
+	out := new(Journal)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) ListTrash(ctx context.Context, in *ListTrashReq) (*Trash, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListTrash"
+	out := new(Trash)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) ListTags(ctx context.Context, in *ListTagsReq) (*TagList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListTags"
+	out := new(TagList)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) SetJournalTags(ctx context.Context, in *Journal) (*Journal, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "SetJournalTags"
+	out := new(Journal)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) ListCommunications(ctx context.Context, in *ListCommunicationsReq) (*CommunicationList, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ListCommunications"
+	out := new(CommunicationList)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) SearchJournals(ctx context.Context, in *SearchJournalsReq) (*SearchJournalsResp, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "SearchJournals"
+	out := new(SearchJournalsResp)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) ExportJournals(ctx context.Context, in *ExportReq) (*ExportResp, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ExportJournals"
+	out := new(ExportResp)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) ImportJournals(ctx context.Context, in *ImportReq) (*ImportReport, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "ImportJournals"
+	out := new(ImportReport)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
+func (c *notifyAppJSONClient) RotateUserKey(ctx context.Context, in *RotateUserKeyReq) (*google_protobuf.Empty, error) {
+	url := c.urlBase + NotifyAppPathPrefix + "RotateUserKey"
+	out := new(google_protobuf.Empty)
+	err := doJSONRequest(ctx, c.client, url, in, out)
+	return out, err
+}
+
 // ========================
 // NotifyApp Server Handler
 // ========================
@@ -177,6 +545,75 @@ func (s *notifyAppServer) ServeHTTP(resp http.ResponseWriter, req *http.Request)
 	case "/twirp/notify.NotifyApp/TriggerNotifications":
 		s.serveTriggerNotifications(ctx, resp, req)
 		return
+	case "/twirp/notify.NotifyApp/ListNotifications":
+		s.serveListNotifications(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/CreateNotification":
+		s.serveCreateNotification(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/UpdateNotification":
+		s.serveUpdateNotification(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/ListUserNotifications":
+		s.serveListUserNotifications(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/UpdateUserNotification":
+		s.serveUpdateUserNotification(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/DeleteUserNotification":
+		s.serveDeleteUserNotification(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/RestoreUserNotification":
+		s.serveRestoreUserNotification(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/ListJournals":
+		s.serveListJournals(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/GetJournal":
+		s.serveGetJournal(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/CreateJournal":
+		s.serveCreateJournal(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/UpdateJournal":
+		s.serveUpdateJournal(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/DeleteJournal":
+		s.serveDeleteJournal(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/ListJournalRevisions":
+		s.serveListJournalRevisions(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/RestoreJournalRevision":
+		s.serveRestoreJournalRevision(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/RestoreJournal":
+		s.serveRestoreJournal(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/ListTrash":
+		s.serveListTrash(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/ListTags":
+		s.serveListTags(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/SetJournalTags":
+		s.serveSetJournalTags(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/ListCommunications":
+		s.serveListCommunications(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/SearchJournals":
+		s.serveSearchJournals(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/ExportJournals":
+		s.serveExportJournals(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/ImportJournals":
+		s.serveImportJournals(ctx, resp, req)
+		return
+	case "/twirp/notify.NotifyApp/RotateUserKey":
+		s.serveRotateUserKey(ctx, resp, req)
+		return
 	default:
 		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
 		err = badRouteError(msg, req.Method, req.URL.Path)
@@ -590,6 +1027,3111 @@ func (s *notifyAppServer) serveTriggerNotificationsProtobuf(ctx context.Context,
 	callResponseSent(ctx, s.hooks)
 }
 
+func (s *notifyAppServer) serveListNotifications(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveListNotificationsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListNotificationsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveListNotificationsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListNotifications")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(google_protobuf.Empty)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *NotificationList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListNotifications(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *NotificationList and nil error while calling ListNotifications. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListNotificationsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListNotifications")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(google_protobuf.Empty)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *NotificationList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListNotifications(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *NotificationList and nil error while calling ListNotifications. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveCreateNotification(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveCreateNotificationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveCreateNotificationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveCreateNotificationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateNotification")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(Notification)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Notification
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.CreateNotification(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Notification and nil error while calling CreateNotification. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveCreateNotificationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateNotification")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(Notification)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Notification
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.CreateNotification(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Notification and nil error while calling CreateNotification. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveUpdateNotification(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveUpdateNotificationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveUpdateNotificationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveUpdateNotificationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateNotification")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(Notification)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Notification
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.UpdateNotification(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Notification and nil error while calling UpdateNotification. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveUpdateNotificationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateNotification")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(Notification)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Notification
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.UpdateNotification(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Notification and nil error while calling UpdateNotification. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListUserNotifications(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveListUserNotificationsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListUserNotificationsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveListUserNotificationsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListUserNotifications")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(ListUserNotificationsReq)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *UserNotificationList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListUserNotifications(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UserNotificationList and nil error while calling ListUserNotifications. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListUserNotificationsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListUserNotifications")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(ListUserNotificationsReq)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *UserNotificationList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListUserNotifications(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UserNotificationList and nil error while calling ListUserNotifications. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveUpdateUserNotification(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveUpdateUserNotificationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveUpdateUserNotificationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveUpdateUserNotificationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateUserNotification")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(UserNotification)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *UserNotification
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.UpdateUserNotification(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UserNotification and nil error while calling UpdateUserNotification. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveUpdateUserNotificationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateUserNotification")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(UserNotification)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *UserNotification
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.UpdateUserNotification(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UserNotification and nil error while calling UpdateUserNotification. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveDeleteUserNotification(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveDeleteUserNotificationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDeleteUserNotificationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveDeleteUserNotificationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteUserNotification")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(UserNotification)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *google_protobuf.Empty
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.DeleteUserNotification(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *google_protobuf.Empty and nil error while calling DeleteUserNotification. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveDeleteUserNotificationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteUserNotification")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(UserNotification)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *google_protobuf.Empty
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.DeleteUserNotification(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *google_protobuf.Empty and nil error while calling DeleteUserNotification. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveRestoreUserNotification(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveRestoreUserNotificationJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveRestoreUserNotificationProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveRestoreUserNotificationJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RestoreUserNotification")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(UserNotification)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *UserNotification
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.RestoreUserNotification(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UserNotification and nil error while calling RestoreUserNotification. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveRestoreUserNotificationProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RestoreUserNotification")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(UserNotification)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *UserNotification
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.RestoreUserNotification(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *UserNotification and nil error while calling RestoreUserNotification. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListJournals(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveListJournalsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListJournalsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveListJournalsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListJournals")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(ListJournalsReq)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *JournalList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListJournals(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *JournalList and nil error while calling ListJournals. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListJournalsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListJournals")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(ListJournalsReq)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *JournalList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListJournals(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *JournalList and nil error while calling ListJournals. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveGetJournal(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveGetJournalJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveGetJournalProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveGetJournalJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetJournal")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(Journal)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.GetJournal(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling GetJournal. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveGetJournalProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GetJournal")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(Journal)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.GetJournal(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling GetJournal. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveCreateJournal(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveCreateJournalJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveCreateJournalProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveCreateJournalJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateJournal")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(Journal)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.CreateJournal(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling CreateJournal. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveCreateJournalProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateJournal")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(Journal)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.CreateJournal(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling CreateJournal. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveUpdateJournal(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveUpdateJournalJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveUpdateJournalProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveUpdateJournalJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateJournal")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(Journal)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.UpdateJournal(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling UpdateJournal. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveUpdateJournalProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "UpdateJournal")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(Journal)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.UpdateJournal(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling UpdateJournal. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveDeleteJournal(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveDeleteJournalJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDeleteJournalProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveDeleteJournalJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteJournal")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(Journal)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *google_protobuf.Empty
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.DeleteJournal(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *google_protobuf.Empty and nil error while calling DeleteJournal. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveDeleteJournalProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteJournal")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(Journal)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *google_protobuf.Empty
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.DeleteJournal(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *google_protobuf.Empty and nil error while calling DeleteJournal. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListJournalRevisions(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveListJournalRevisionsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListJournalRevisionsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveListJournalRevisionsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListJournalRevisions")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(Journal)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *JournalRevisionList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListJournalRevisions(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *JournalRevisionList and nil error while calling ListJournalRevisions. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListJournalRevisionsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListJournalRevisions")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(Journal)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *JournalRevisionList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListJournalRevisions(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *JournalRevisionList and nil error while calling ListJournalRevisions. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveRestoreJournalRevision(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveRestoreJournalRevisionJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveRestoreJournalRevisionProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveRestoreJournalRevisionJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RestoreJournalRevision")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(JournalRevision)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.RestoreJournalRevision(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling RestoreJournalRevision. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveRestoreJournalRevisionProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RestoreJournalRevision")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(JournalRevision)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.RestoreJournalRevision(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling RestoreJournalRevision. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveRestoreJournal(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveRestoreJournalJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveRestoreJournalProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveRestoreJournalJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RestoreJournal")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(Journal)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.RestoreJournal(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling RestoreJournal. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveRestoreJournalProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RestoreJournal")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(Journal)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.RestoreJournal(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling RestoreJournal. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListTrash(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveListTrashJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListTrashProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveListTrashJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListTrash")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(ListTrashReq)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Trash
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListTrash(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Trash and nil error while calling ListTrash. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListTrashProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListTrash")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(ListTrashReq)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Trash
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListTrash(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Trash and nil error while calling ListTrash. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListTags(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveListTagsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListTagsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveListTagsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListTags")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(ListTagsReq)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *TagList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListTags(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *TagList and nil error while calling ListTags. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListTagsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListTags")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(ListTagsReq)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *TagList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListTags(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *TagList and nil error while calling ListTags. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveSetJournalTags(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveSetJournalTagsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveSetJournalTagsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveSetJournalTagsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SetJournalTags")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(Journal)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.SetJournalTags(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling SetJournalTags. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveSetJournalTagsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SetJournalTags")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(Journal)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *Journal
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.SetJournalTags(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *Journal and nil error while calling SetJournalTags. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListCommunications(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveListCommunicationsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveListCommunicationsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveListCommunicationsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListCommunications")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(ListCommunicationsReq)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *CommunicationList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListCommunications(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CommunicationList and nil error while calling ListCommunications. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveListCommunicationsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ListCommunications")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(ListCommunicationsReq)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *CommunicationList
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ListCommunications(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CommunicationList and nil error while calling ListCommunications. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveSearchJournals(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveSearchJournalsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveSearchJournalsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveSearchJournalsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SearchJournals")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(SearchJournalsReq)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *SearchJournalsResp
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.SearchJournals(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SearchJournalsResp and nil error while calling SearchJournals. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveSearchJournalsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "SearchJournals")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(SearchJournalsReq)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *SearchJournalsResp
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.SearchJournals(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SearchJournalsResp and nil error while calling SearchJournals. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveExportJournals(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveExportJournalsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveExportJournalsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveExportJournalsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ExportJournals")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(ExportReq)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *ExportResp
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ExportJournals(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportResp and nil error while calling ExportJournals. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveExportJournalsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ExportJournals")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(ExportReq)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *ExportResp
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ExportJournals(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ExportResp and nil error while calling ExportJournals. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveImportJournals(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveImportJournalsJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveImportJournalsProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveImportJournalsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ImportJournals")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(ImportReq)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *ImportReport
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ImportJournals(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ImportReport and nil error while calling ImportJournals. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveImportJournalsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "ImportJournals")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(ImportReq)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *ImportReport
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.ImportJournals(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ImportReport and nil error while calling ImportJournals. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveRotateUserKey(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	switch req.Header.Get("Content-Type") {
+	case "application/json":
+		s.serveRotateUserKeyJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveRotateUserKeyProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *notifyAppServer) serveRotateUserKeyJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RotateUserKey")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	reqContent := new(RotateUserKeyReq)
+	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+	if err = unmarshaler.Unmarshal(req.Body, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request json")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *google_protobuf.Empty
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.RotateUserKey(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *google_protobuf.Empty and nil error while calling RotateUserKey. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	var buf bytes.Buffer
+	marshaler := &jsonpb.Marshaler{OrigName: true}
+	if err = marshaler.Marshal(&buf, respContent); err != nil {
+		err = wrapErr(err, "failed to marshal json response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(buf.Bytes()); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *notifyAppServer) serveRotateUserKeyProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "RotateUserKey")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	defer closebody(req.Body)
+	buf, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		err = wrapErr(err, "failed to read request body")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+	reqContent := new(RotateUserKeyReq)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		err = wrapErr(err, "failed to parse request proto")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	// Call service method
+	var respContent *google_protobuf.Empty
+	func() {
+		defer func() {
+			// In case of a panic, serve a 500 error and then panic.
+			if r := recover(); r != nil {
+				s.writeError(ctx, resp, twirp.InternalError("Internal service panic"))
+				panic(r)
+			}
+		}()
+		respContent, err = s.RotateUserKey(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *google_protobuf.Empty and nil error while calling RotateUserKey. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		err = wrapErr(err, "failed to marshal proto response")
+		s.writeError(ctx, resp, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.WriteHeader(http.StatusOK)
+	if _, err = resp.Write(respBytes); err != nil {
+		log.Printf("errored while writing response to client, but already sent response status code to 200: %s", err)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
 func (s *notifyAppServer) ServiceDescriptor() ([]byte, int) {
 	return twirpFileDescriptor0, 0
 }
@@ -999,42 +4541,120 @@ func callError(ctx context.Context, h *twirp.ServerHooks, err twirp.Error) conte
 }
 
 var twirpFileDescriptor0 = []byte{
-	// 588 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xcd, 0x72, 0xd3, 0x3c,
-	0x14, 0x1d, 0xbb, 0x69, 0x7e, 0x6e, 0xd2, 0x7e, 0xfd, 0x44, 0xa6, 0xe3, 0x66, 0x60, 0xa6, 0x78,
-	0x43, 0x37, 0x64, 0x51, 0x58, 0xb0, 0x0d, 0xe5, 0x67, 0xc2, 0xa2, 0x0b, 0x4f, 0xd9, 0x30, 0xc3,
-	0x64, 0x1c, 0xfb, 0x26, 0x15, 0x13, 0x4b, 0xaa, 0x24, 0x17, 0x3c, 0x3c, 0x05, 0x6f, 0xc2, 0x53,
-	0xf0, 0x20, 0xac, 0x79, 0x08, 0x46, 0xb2, 0x95, 0xd8, 0x49, 0xb3, 0x61, 0xa7, 0x73, 0x8e, 0xac,
-	0x73, 0xef, 0xf5, 0x91, 0xe0, 0x48, 0xa1, 0xbc, 0xa7, 0x09, 0x8e, 0x85, 0xe4, 0x9a, 0x93, 0x36,
-	0xe3, 0x9a, 0x2e, 0x8a, 0x51, 0x1f, 0x33, 0xa1, 0x8b, 0x92, 0x0c, 0x7f, 0x7a, 0xd0, 0xfa, 0xa8,
-	0x50, 0x92, 0xa7, 0x30, 0x10, 0xb7, 0x9c, 0xe1, 0x8c, 0xe5, 0xd9, 0x1c, 0x65, 0xe0, 0x9d, 0x7b,
-	0x17, 0xbd, 0xa8, 0x6f, 0xb9, 0x6b, 0x4b, 0x91, 0x11, 0x74, 0x45, 0xac, 0xd4, 0x57, 0x2e, 0xd3,
-	0xc0, 0xb7, 0xf2, 0x1a, 0x13, 0x02, 0x2d, 0x16, 0x67, 0x18, 0x1c, 0x58, 0xde, 0xae, 0xcd, 0xfe,
-	0x39, 0x95, 0xfa, 0x36, 0x8d, 0x8b, 0xa0, 0x55, 0xee, 0x77, 0xd8, 0x68, 0xf7, 0x28, 0xe9, 0x82,
-	0x62, 0x1a, 0x1c, 0x9e, 0x7b, 0x17, 0xdd, 0x68, 0x8d, 0xc9, 0x13, 0x00, 0x85, 0x4a, 0x51, 0xce,
-	0x66, 0x34, 0x0d, 0xda, 0xf6, 0xcb, 0x5e, 0xc5, 0x4c, 0xd3, 0xf0, 0x33, 0x9c, 0x5c, 0x49, 0x8c,
-	0x35, 0x4e, 0x92, 0x84, 0xe7, 0x4c, 0x47, 0x78, 0x47, 0xce, 0xa1, 0x95, 0xab, 0xaa, 0xea, 0xfe,
-	0xe5, 0x60, 0x5c, 0xb6, 0x3a, 0x36, 0x9d, 0x45, 0x56, 0x21, 0xcf, 0xe0, 0x3f, 0x57, 0xec, 0x4c,
-	0xa2, 0xc0, 0x58, 0x57, 0x3d, 0x1c, 0x3b, 0x3a, 0xb2, 0x6c, 0xf8, 0x1c, 0xfe, 0xdf, 0x3a, 0x5e,
-	0x09, 0x12, 0x40, 0x47, 0xe5, 0x49, 0x82, 0x4a, 0x59, 0x8b, 0x6e, 0xe4, 0x60, 0xf8, 0xc7, 0x83,
-	0x13, 0x63, 0x73, 0x6d, 0x1c, 0x69, 0x12, 0x6b, 0xca, 0x99, 0x31, 0x63, 0x35, 0x6c, 0xda, 0x28,
-	0xe7, 0x79, 0x5c, 0xa7, 0xa7, 0xe9, 0xce, 0xd4, 0xfd, 0xdd, 0xa9, 0xbf, 0x84, 0x53, 0x86, 0xdf,
-	0xf4, 0xac, 0x71, 0xa0, 0xa6, 0xeb, 0x59, 0x0f, 0x8d, 0x5a, 0x77, 0xbf, 0xa1, 0x19, 0x92, 0xc7,
-	0xd0, 0x5b, 0x48, 0xbc, 0xcb, 0x91, 0x25, 0x6e, 0xf8, 0x1b, 0x82, 0xbc, 0x82, 0x41, 0xfd, 0x38,
-	0xfb, 0x07, 0xfa, 0x97, 0x43, 0x37, 0xb6, 0xfa, 0x69, 0x51, 0x63, 0x67, 0xf8, 0x1d, 0x06, 0xff,
-	0xd6, 0xa9, 0x0b, 0x88, 0x5f, 0x0b, 0x08, 0x81, 0x96, 0x2e, 0xc4, 0x3a, 0x34, 0x66, 0x6d, 0x82,
-	0xa1, 0x31, 0x13, 0xab, 0x58, 0xa3, 0x0b, 0x8d, 0xc3, 0xe1, 0x0f, 0x0f, 0x8e, 0xae, 0x78, 0x96,
-	0xe5, 0xcc, 0xd9, 0x9f, 0x41, 0x37, 0xe1, 0x59, 0xa6, 0x36, 0xbe, 0x1d, 0x8b, 0x4b, 0xc3, 0x85,
-	0xe4, 0x99, 0x33, 0x34, 0x6b, 0x72, 0x0c, 0xbe, 0xe6, 0x95, 0x9d, 0xaf, 0xb9, 0xf9, 0xad, 0x19,
-	0x2a, 0x15, 0x2f, 0x9d, 0x97, 0x83, 0x0f, 0xf5, 0x75, 0xf8, 0x50, 0x5f, 0xe1, 0x2f, 0x0f, 0x3a,
-	0x1f, 0x78, 0x2e, 0x59, 0xbc, 0x32, 0xc1, 0xfd, 0x52, 0x2e, 0x37, 0xf5, 0xf4, 0x2a, 0x66, 0x9a,
-	0x36, 0x8a, 0xf5, 0x9b, 0xc5, 0x6e, 0xe7, 0xe0, 0x60, 0x37, 0x07, 0x43, 0x38, 0xd4, 0x54, 0xaf,
-	0x5c, 0xa5, 0x25, 0x30, 0x2c, 0x32, 0x2d, 0x8b, 0xaa, 0xba, 0x12, 0x98, 0xbe, 0x12, 0x9b, 0x61,
-	0x77, 0x7d, 0x1c, 0x34, 0x4a, 0x2e, 0x52, 0xab, 0x74, 0x4a, 0xa5, 0x82, 0x97, 0xbf, 0x3d, 0xe8,
-	0xd9, 0x5f, 0x5b, 0x4c, 0x84, 0x20, 0x6f, 0xe0, 0xa8, 0x71, 0x0b, 0x48, 0xe0, 0xc2, 0xb1, 0x7d,
-	0xf7, 0x46, 0x67, 0x7b, 0x14, 0x25, 0xc8, 0x7b, 0x78, 0x34, 0x49, 0xd3, 0x9d, 0xeb, 0x11, 0xd4,
-	0xef, 0x67, 0x5d, 0x19, 0x9d, 0x8e, 0x97, 0x9c, 0x2f, 0x57, 0xd5, 0x93, 0x35, 0xcf, 0x17, 0xe3,
-	0xb7, 0xe6, 0xb1, 0x22, 0xef, 0x60, 0x78, 0x23, 0xe9, 0x72, 0xd9, 0xdc, 0xae, 0xc8, 0x9e, 0xfd,
-	0xfb, 0xce, 0x79, 0xdd, 0xfd, 0xd4, 0x36, 0x8f, 0x22, 0xca, 0x79, 0xdb, 0x2a, 0x2f, 0xfe, 0x06,
-	0x00, 0x00, 0xff, 0xff, 0x5c, 0xdc, 0xa4, 0x51, 0x25, 0x05, 0x00, 0x00,
+	// 1833 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x58, 0x4b, 0x8f, 0xe4, 0x48,
+	0x11, 0x96, 0xeb, 0xed, 0xa8, 0xc7, 0x74, 0xe7, 0xd4, 0xf6, 0x78, 0x6a, 0x76, 0xa1, 0xd7, 0xb0,
+	0x62, 0x11, 0xa2, 0x99, 0x6d, 0x66, 0xc5, 0xf2, 0x58, 0x89, 0x9e, 0xc7, 0x36, 0xbd, 0x0c, 0x7d,
+	0xf0, 0x54, 0x0b, 0x09, 0x0e, 0x25, 0xb7, 0x9d, 0xe5, 0x36, 0x54, 0x39, 0x3d, 0x99, 0xe9, 0xee,
+	0xad, 0x95, 0xb8, 0xec, 0x81, 0x3f, 0xc0, 0x0d, 0x71, 0x41, 0x70, 0x42, 0xe2, 0x47, 0xf0, 0x33,
+	0x38, 0x70, 0xe3, 0x87, 0xa0, 0x7c, 0xb9, 0x6c, 0x57, 0x99, 0xa9, 0x1e, 0xf5, 0x65, 0x2f, 0x56,
+	0x46, 0x44, 0x3e, 0x22, 0xbe, 0x78, 0x64, 0xa4, 0x61, 0xc8, 0x30, 0xbd, 0x8e, 0x03, 0x7c, 0x94,
+	0x52, 0xc2, 0x09, 0xea, 0x24, 0x84, 0xc7, 0xf3, 0xd5, 0xa4, 0x8f, 0x97, 0x29, 0x5f, 0x29, 0xa6,
+	0xfb, 0x55, 0x03, 0x5a, 0x17, 0x0c, 0x53, 0xf4, 0x3e, 0x0c, 0xd2, 0x2b, 0x92, 0xe0, 0x59, 0x92,
+	0x2d, 0x2f, 0x31, 0x75, 0xac, 0x43, 0xeb, 0x43, 0xdb, 0xeb, 0x4b, 0xde, 0xb9, 0x64, 0xa1, 0x09,
+	0xf4, 0x52, 0x9f, 0xb1, 0x1b, 0x42, 0x43, 0xa7, 0x21, 0xc5, 0x39, 0x8d, 0x10, 0xb4, 0x12, 0x7f,
+	0x89, 0x9d, 0xa6, 0xe4, 0xcb, 0xb1, 0x98, 0x7f, 0x19, 0x53, 0x7e, 0x15, 0xfa, 0x2b, 0xa7, 0xa5,
+	0xe6, 0x1b, 0x5a, 0xc8, 0xae, 0x31, 0x8d, 0xe7, 0x31, 0x0e, 0x9d, 0xf6, 0xa1, 0xf5, 0x61, 0xcf,
+	0xcb, 0x69, 0xf4, 0x1e, 0x00, 0xc3, 0x8c, 0xc5, 0x24, 0x99, 0xc5, 0xa1, 0xd3, 0x91, 0x2b, 0x6d,
+	0xcd, 0x39, 0x93, 0x47, 0x51, 0xb2, 0xc0, 0x4e, 0x57, 0x1d, 0x25, 0xc6, 0x62, 0x49, 0xc0, 0xe8,
+	0x7c, 0xc6, 0xc9, 0xef, 0x71, 0xe2, 0xf4, 0xd4, 0x12, 0xc1, 0x99, 0x0a, 0x86, 0x10, 0xf3, 0x1b,
+	0x32, 0x9b, 0xfb, 0x01, 0x27, 0xd4, 0xb1, 0xe5, 0x79, 0x36, 0xbf, 0x21, 0x9f, 0x49, 0x86, 0x9b,
+	0xc1, 0xde, 0x33, 0x8a, 0x7d, 0x8e, 0x4f, 0x82, 0x80, 0x64, 0x09, 0xf7, 0xf0, 0x6b, 0x74, 0x08,
+	0xad, 0x8c, 0x69, 0x1c, 0xfa, 0xc7, 0x83, 0x23, 0x05, 0xde, 0x91, 0xc0, 0xca, 0x93, 0x12, 0xf4,
+	0x1d, 0xb8, 0x67, 0xcc, 0x9f, 0x51, 0x9c, 0x62, 0x9f, 0x6b, 0x54, 0x46, 0x86, 0xed, 0x49, 0x2e,
+	0x3a, 0x80, 0x0e, 0xc5, 0x51, 0x4c, 0x12, 0x8d, 0x8e, 0xa6, 0xdc, 0xef, 0xc3, 0x7e, 0xe5, 0x58,
+	0x96, 0x22, 0x07, 0xba, 0x2c, 0x0b, 0x02, 0xcc, 0x98, 0x3c, 0xba, 0xe7, 0x19, 0xd2, 0xfd, 0x63,
+	0x03, 0xf6, 0xc4, 0xf1, 0xe7, 0x42, 0x93, 0x38, 0xf0, 0x79, 0x4c, 0x12, 0xa1, 0x44, 0x52, 0xa0,
+	0x05, 0x60, 0xca, 0x73, 0xa3, 0x22, 0xfb, 0x2c, 0xdc, 0xf0, 0x6f, 0x63, 0xd3, 0xbf, 0x4f, 0xe0,
+	0x20, 0xc1, 0x5f, 0xf0, 0x59, 0x69, 0x43, 0x1e, 0xe7, 0x5e, 0x1d, 0x0b, 0x69, 0xf1, 0xf4, 0x69,
+	0xbc, 0xc4, 0xe8, 0x5d, 0xb0, 0xe7, 0x14, 0xbf, 0xce, 0x70, 0x12, 0x18, 0x37, 0xaf, 0x19, 0xe8,
+	0x13, 0x18, 0x14, 0xb7, 0x93, 0xbe, 0xee, 0x1f, 0x8f, 0x0d, 0x9c, 0xc5, 0xdd, 0xbc, 0xd2, 0x4c,
+	0x01, 0x44, 0x88, 0x17, 0x98, 0x63, 0x13, 0x02, 0x86, 0x74, 0xff, 0x62, 0xc1, 0xe0, 0xed, 0x40,
+	0x30, 0x51, 0xda, 0x28, 0x44, 0x29, 0x82, 0x16, 0x5f, 0xa5, 0x79, 0xe4, 0x8a, 0xb1, 0x88, 0x4e,
+	0x8e, 0x97, 0xe9, 0xc2, 0xe7, 0xd8, 0x44, 0xae, 0xa1, 0xd1, 0x37, 0xa1, 0x1f, 0xe2, 0xb9, 0x9f,
+	0x2d, 0xf8, 0x8c, 0xfb, 0x91, 0x34, 0xc8, 0xf6, 0x40, 0xb3, 0xa6, 0x7e, 0xe4, 0x9e, 0xc3, 0x5e,
+	0x51, 0xbb, 0x97, 0x31, 0xe3, 0xe8, 0x27, 0x30, 0x2c, 0xaa, 0x22, 0x7c, 0xdb, 0xac, 0xc5, 0xa1,
+	0x3c, 0xd5, 0xfd, 0x14, 0x1c, 0xb1, 0x47, 0xd5, 0xf5, 0x4c, 0x44, 0xe9, 0x9b, 0xb3, 0xd6, 0x9d,
+	0xc1, 0xb8, 0xba, 0x54, 0xaa, 0x74, 0x0a, 0x48, 0x84, 0xf1, 0x6c, 0x9b, 0x5e, 0x4e, 0x31, 0xdc,
+	0x4b, 0xba, 0xed, 0x67, 0x55, 0x35, 0xdc, 0x7f, 0x5b, 0x30, 0x7c, 0x46, 0x96, 0xcb, 0x2c, 0x31,
+	0xfe, 0x78, 0x08, 0xbd, 0x80, 0x2c, 0x97, 0x6c, 0xed, 0x88, 0xae, 0xa4, 0x95, 0x07, 0xe6, 0x94,
+	0x2c, 0x8d, 0x07, 0xc4, 0x18, 0x8d, 0xa0, 0xc1, 0x89, 0xc6, 0xbf, 0xc1, 0x89, 0xf0, 0xfc, 0x12,
+	0x33, 0xe6, 0x47, 0x06, 0x7c, 0x43, 0x6e, 0x73, 0x74, 0x7b, 0xab, 0xa3, 0x1d, 0xe8, 0x06, 0x32,
+	0xb5, 0xf2, 0xe0, 0xd1, 0xa4, 0x48, 0x46, 0xc6, 0x7d, 0x9e, 0x31, 0x5d, 0x3f, 0x34, 0x85, 0xc6,
+	0xd0, 0xc6, 0x94, 0x12, 0xaa, 0x8b, 0x87, 0x22, 0xdc, 0xff, 0x5a, 0xf0, 0x8e, 0x40, 0xab, 0x64,
+	0xdf, 0x8e, 0xc8, 0xa3, 0x47, 0x60, 0xa7, 0x7e, 0x84, 0x67, 0x2c, 0xfe, 0x52, 0x85, 0x5c, 0x5b,
+	0x14, 0xcc, 0x08, 0xbf, 0x8a, 0xbf, 0xc4, 0x42, 0x8f, 0x20, 0xa3, 0x8c, 0x50, 0x53, 0x14, 0x14,
+	0x85, 0xbe, 0x05, 0x43, 0xad, 0xea, 0xcc, 0x9f, 0x73, 0x4c, 0x35, 0x04, 0x03, 0xcd, 0x3c, 0x11,
+	0x3c, 0xf4, 0x01, 0x8c, 0xcc, 0xa4, 0x4b, 0x3c, 0x27, 0x14, 0x6b, 0x18, 0xcc, 0xd2, 0xa7, 0x92,
+	0xb9, 0x0d, 0xae, 0xce, 0x36, 0xb8, 0x5c, 0x06, 0xfb, 0x25, 0x0b, 0x65, 0x80, 0x7c, 0x0a, 0xa3,
+	0xa0, 0x64, 0xb6, 0x0e, 0x8e, 0x77, 0x4c, 0x70, 0x94, 0x96, 0x78, 0x95, 0xc9, 0x22, 0x4f, 0x64,
+	0x35, 0xd1, 0x56, 0x2a, 0x87, 0x83, 0x60, 0x3d, 0x93, 0x1c, 0xf7, 0x1f, 0x0d, 0xe8, 0x7e, 0x4e,
+	0x32, 0x9a, 0xf8, 0x0b, 0x51, 0xa0, 0x7f, 0xa7, 0x86, 0xeb, 0x98, 0xb1, 0x35, 0xe7, 0x2c, 0x2c,
+	0x05, 0x54, 0xa3, 0x1c, 0x50, 0x55, 0x3f, 0x34, 0x37, 0xfd, 0x30, 0x86, 0x36, 0x8f, 0xf9, 0xc2,
+	0x44, 0x93, 0x22, 0x04, 0x17, 0x27, 0x9c, 0xae, 0x34, 0x74, 0x8a, 0xf8, 0x3f, 0x81, 0xe3, 0x40,
+	0x37, 0x4b, 0x43, 0x29, 0x51, 0x91, 0x63, 0xc8, 0x62, 0xa5, 0xea, 0x95, 0x2a, 0x95, 0xac, 0x2d,
+	0x7e, 0xc4, 0x1c, 0xfb, 0xb0, 0x29, 0x6b, 0x8b, 0x1f, 0x31, 0xf4, 0x04, 0xfa, 0x3e, 0xe7, 0x7e,
+	0x70, 0xb5, 0xc4, 0x09, 0x67, 0x0e, 0x48, 0x4c, 0x91, 0xc1, 0xf4, 0x24, 0x17, 0x79, 0xc5, 0x69,
+	0xee, 0x5f, 0x2d, 0x80, 0xb5, 0x4c, 0x44, 0xc9, 0x5a, 0xba, 0x86, 0x6c, 0xb0, 0x66, 0x9e, 0x85,
+	0x15, 0x50, 0x1b, 0x55, 0x50, 0xdf, 0x87, 0x41, 0x40, 0x12, 0x2e, 0x36, 0x28, 0x14, 0xc0, 0xbe,
+	0xe6, 0x4d, 0x45, 0x1d, 0x44, 0xd0, 0x92, 0xc1, 0x2b, 0x80, 0x6b, 0x7a, 0x72, 0x5c, 0x44, 0xa8,
+	0x5d, 0x42, 0xc8, 0xfd, 0x8f, 0x05, 0xf7, 0x44, 0xe4, 0x68, 0xa7, 0x7e, 0x6d, 0xd2, 0x24, 0x8f,
+	0x8f, 0x4e, 0x31, 0x3e, 0xf6, 0xa0, 0x29, 0xea, 0xbb, 0xf2, 0xb5, 0x18, 0xba, 0xbf, 0x85, 0xbe,
+	0x36, 0x4d, 0xe6, 0xc7, 0xf7, 0xa0, 0xa7, 0xc1, 0x34, 0x99, 0x71, 0xcf, 0x78, 0x51, 0x4f, 0xf3,
+	0xf2, 0x09, 0x6f, 0xce, 0x86, 0x18, 0xf6, 0x5f, 0x61, 0x9f, 0x06, 0x57, 0xb7, 0x44, 0x6f, 0x0c,
+	0xed, 0xd7, 0x19, 0xa6, 0x2b, 0xbd, 0xa5, 0x22, 0xca, 0x98, 0x36, 0xcb, 0x98, 0xba, 0x73, 0xb0,
+	0xd5, 0x51, 0xbf, 0x88, 0x39, 0xfa, 0x2e, 0x74, 0xb5, 0x92, 0xba, 0xd5, 0xd9, 0x30, 0xc2, 0xc8,
+	0xc5, 0x51, 0x2c, 0x10, 0x28, 0x8a, 0xa3, 0x2c, 0x4f, 0x11, 0xb2, 0x61, 0x49, 0xe2, 0x34, 0xc5,
+	0x5c, 0xbb, 0xc8, 0x90, 0xee, 0x4f, 0x01, 0x55, 0x4d, 0x62, 0x29, 0xfa, 0x00, 0x5a, 0x57, 0x31,
+	0x37, 0x90, 0xed, 0x9b, 0xd3, 0x72, 0x8d, 0x3c, 0x29, 0x76, 0xff, 0x69, 0x81, 0xfd, 0xe2, 0x8b,
+	0x94, 0x50, 0xbe, 0x23, 0x10, 0x07, 0xd0, 0x99, 0x13, 0xba, 0xcc, 0xbb, 0x30, 0x4d, 0x89, 0xda,
+	0x11, 0x51, 0x92, 0xa5, 0xb3, 0xcb, 0x95, 0x51, 0x50, 0xd2, 0x4f, 0x57, 0x77, 0x19, 0x44, 0xee,
+	0x57, 0x16, 0x80, 0xd1, 0x97, 0xa5, 0xa2, 0x83, 0x98, 0xc7, 0x0b, 0x2c, 0xbb, 0x0d, 0xa5, 0x6c,
+	0x4e, 0x6f, 0x24, 0x5e, 0x63, 0x6b, 0xe2, 0x85, 0x3e, 0xf7, 0xa5, 0xc2, 0x03, 0x4f, 0x8e, 0xc5,
+	0xb2, 0x90, 0xdc, 0x24, 0x0b, 0xe2, 0x87, 0xb3, 0x8c, 0x2e, 0xb4, 0xb2, 0x7d, 0xc3, 0xbb, 0xa0,
+	0x0b, 0xf7, 0x4f, 0x16, 0xf4, 0x4e, 0xd2, 0x58, 0x35, 0xbd, 0x0f, 0xa1, 0x27, 0xdb, 0xe1, 0xc2,
+	0x2d, 0x2c, 0xe9, 0xdd, 0x9a, 0xc1, 0x6d, 0x0d, 0x7d, 0x21, 0xf5, 0x5b, 0xe5, 0xe2, 0xf8, 0x08,
+	0xec, 0x85, 0xcf, 0xf8, 0x2c, 0x63, 0x79, 0x59, 0xe8, 0x09, 0xc6, 0x05, 0xc3, 0xe2, 0x76, 0xb1,
+	0xcf, 0x96, 0x77, 0xe0, 0xc9, 0x6d, 0xa0, 0x3c, 0x80, 0x6e, 0x48, 0x57, 0x33, 0x9a, 0x25, 0x52,
+	0xa5, 0x9e, 0xd7, 0x09, 0xe9, 0xca, 0xcb, 0x12, 0xf7, 0xcf, 0x56, 0x7e, 0x2a, 0xb9, 0x11, 0xc9,
+	0x4c, 0xc9, 0x8d, 0x3c, 0xac, 0xed, 0x89, 0xe1, 0x3a, 0xe9, 0x1b, 0xc5, 0xa4, 0x2f, 0x58, 0xd8,
+	0xac, 0xeb, 0x1b, 0x5a, 0xdb, 0xfb, 0x86, 0x76, 0xa1, 0x6f, 0xa8, 0x94, 0xde, 0x4e, 0xa5, 0xf4,
+	0xba, 0xff, 0xb2, 0x60, 0x60, 0x20, 0x11, 0x5f, 0x81, 0x5f, 0x2c, 0xe9, 0xb5, 0xb3, 0x7a, 0x8a,
+	0x71, 0x16, 0x16, 0x6d, 0x6c, 0x14, 0x6d, 0x14, 0x41, 0xa6, 0x26, 0x69, 0x75, 0xdb, 0x5e, 0x4e,
+	0xa3, 0x6f, 0x00, 0x84, 0x59, 0xba, 0x10, 0xd7, 0x31, 0x56, 0x3a, 0xb7, 0xbd, 0x02, 0x47, 0x58,
+	0x1a, 0x27, 0xd7, 0xfe, 0x42, 0xb7, 0x50, 0x6d, 0xcf, 0x90, 0x22, 0x41, 0x29, 0xb9, 0x61, 0x4e,
+	0xa7, 0x9c, 0xa0, 0x39, 0x98, 0x9e, 0x14, 0xbb, 0x7f, 0xb7, 0xe0, 0x9e, 0x29, 0x11, 0xf8, 0x3a,
+	0x66, 0x31, 0x49, 0x44, 0x95, 0xa3, 0x7a, 0xbc, 0x36, 0x04, 0x0c, 0x6b, 0xa7, 0x2b, 0x69, 0x87,
+	0xcb, 0x5c, 0x5d, 0xdb, 0xad, 0x9a, 0x6b, 0xbb, 0x72, 0x29, 0xbd, 0x84, 0xfb, 0x15, 0x2d, 0x65,
+	0xf1, 0xfe, 0x18, 0x6c, 0xa3, 0x96, 0x29, 0x45, 0x0f, 0xaa, 0x85, 0x4f, 0xcb, 0xbd, 0xf5, 0x4c,
+	0xf7, 0x23, 0x18, 0x88, 0xe5, 0x53, 0xea, 0xb3, 0xab, 0x1d, 0xfb, 0xef, 0x3f, 0x40, 0x5b, 0x4e,
+	0xbf, 0xdd, 0x7d, 0xb1, 0xbd, 0x3b, 0x6f, 0xdc, 0xbe, 0x3b, 0x7f, 0x0c, 0x7d, 0xa9, 0xb1, 0x1f,
+	0xed, 0xfa, 0x60, 0x78, 0x02, 0xbd, 0xa9, 0x1f, 0x3d, 0x23, 0x59, 0xc2, 0xf3, 0x2a, 0x60, 0x15,
+	0xaa, 0xc0, 0x18, 0xda, 0xf2, 0xb9, 0xaa, 0xef, 0x6a, 0x45, 0xb8, 0x3f, 0x80, 0xee, 0xd4, 0x8f,
+	0x24, 0xb6, 0xdf, 0xd6, 0x5d, 0x8f, 0x32, 0x72, 0xcf, 0x68, 0x6b, 0x36, 0x55, 0x7d, 0x90, 0xfb,
+	0x31, 0xec, 0x79, 0x84, 0xfb, 0x1c, 0x0b, 0x2b, 0x7e, 0x89, 0x57, 0xbb, 0x69, 0x77, 0xfc, 0xb7,
+	0x21, 0xd8, 0xd2, 0xc2, 0xd5, 0x49, 0x9a, 0xa2, 0xe7, 0x30, 0x2c, 0x3d, 0xa1, 0x51, 0x8e, 0x4d,
+	0xf5, 0x41, 0x3f, 0x79, 0x58, 0x23, 0x61, 0x29, 0x3a, 0x85, 0xfb, 0x27, 0x61, 0xb8, 0xf1, 0xb6,
+	0xae, 0xc5, 0x79, 0x72, 0x70, 0x14, 0x11, 0x12, 0x2d, 0xf4, 0x9f, 0x95, 0xcb, 0x6c, 0x7e, 0xf4,
+	0x42, 0xfc, 0x53, 0x41, 0x9f, 0xc1, 0x78, 0x4a, 0xe3, 0x28, 0xaa, 0x38, 0x01, 0xd5, 0xcc, 0xaf,
+	0xdd, 0xe7, 0x05, 0xec, 0x0b, 0x24, 0x77, 0xdb, 0xc4, 0xd9, 0xf6, 0x88, 0x94, 0x8e, 0xf8, 0x39,
+	0x20, 0x65, 0x6c, 0xc9, 0xac, 0xad, 0x8f, 0xce, 0xc9, 0x56, 0xae, 0xd8, 0xe1, 0x42, 0x76, 0xb9,
+	0x6f, 0xbd, 0xc3, 0xaf, 0xd5, 0x03, 0x6a, 0xe3, 0xf5, 0x8a, 0x0e, 0xcd, 0xf4, 0xba, 0xc7, 0xed,
+	0xe4, 0xdd, 0x3a, 0xfc, 0xa5, 0x71, 0x2f, 0xe1, 0x40, 0xa9, 0x76, 0x0b, 0xbf, 0xd5, 0x4a, 0xd0,
+	0xe7, 0x70, 0xf0, 0x5c, 0x36, 0xed, 0x77, 0x10, 0x05, 0xbf, 0x82, 0x07, 0x1e, 0x66, 0x9c, 0xd0,
+	0xbb, 0x51, 0xed, 0x67, 0xaa, 0xe6, 0x98, 0x26, 0x0a, 0x3d, 0x28, 0x02, 0x57, 0xe8, 0x16, 0x27,
+	0xf7, 0x2b, 0xe5, 0x44, 0xc2, 0x74, 0x04, 0x70, 0x8a, 0xcd, 0x34, 0x54, 0xad, 0x38, 0x93, 0x2a,
+	0x03, 0x7d, 0x64, 0x32, 0xea, 0x56, 0x4b, 0x94, 0x27, 0x76, 0x5f, 0xf2, 0x09, 0x0c, 0x15, 0xdc,
+	0xb5, 0x4b, 0xea, 0xc0, 0x7d, 0x0e, 0xe3, 0x82, 0xdd, 0xa6, 0x46, 0xb3, 0xcd, 0x0d, 0x1e, 0xd5,
+	0x94, 0x73, 0x89, 0xca, 0x53, 0x38, 0xd0, 0x2e, 0xaa, 0x48, 0x51, 0xdd, 0x2d, 0xb0, 0x69, 0xc3,
+	0x31, 0x8c, 0xca, 0x7b, 0xec, 0x60, 0xf7, 0x63, 0xb0, 0xf3, 0xfb, 0x63, 0x9d, 0x46, 0xc5, 0x2b,
+	0x65, 0x32, 0xcc, 0xeb, 0xa5, 0x9c, 0xf4, 0x18, 0x7a, 0xa6, 0x7e, 0xa3, 0xfb, 0xa5, 0x05, 0xaa,
+	0xa2, 0xaf, 0xcf, 0x30, 0xe5, 0xf7, 0x18, 0x46, 0xaf, 0x72, 0x8f, 0xcb, 0x75, 0x6f, 0xd6, 0xeb,
+	0x1c, 0xd0, 0xe6, 0x6f, 0x0e, 0xf4, 0x5e, 0xf1, 0xbc, 0x8d, 0x5f, 0x20, 0x85, 0x8a, 0xba, 0xf1,
+	0xef, 0xe0, 0x14, 0x46, 0xaa, 0xa1, 0xcf, 0xa3, 0xf6, 0x61, 0xb9, 0xd1, 0x2f, 0xc6, 0xed, 0xa4,
+	0x4e, 0xc4, 0x52, 0xf4, 0x23, 0x18, 0xa9, 0xae, 0x3a, 0xdf, 0x28, 0x6f, 0x48, 0xf2, 0xd7, 0xc1,
+	0x04, 0x55, 0x59, 0x2c, 0x45, 0x3f, 0x86, 0xd1, 0xd9, 0x72, 0xfb, 0xc2, 0xbc, 0x19, 0x9d, 0x8c,
+	0xab, 0x2c, 0xf1, 0x45, 0x27, 0x30, 0x2c, 0xdd, 0x4c, 0xeb, 0xac, 0xad, 0x5e, 0x58, 0x75, 0x51,
+	0xfa, 0xb4, 0xf7, 0x9b, 0x8e, 0xf8, 0xf9, 0x8e, 0xe9, 0x65, 0x47, 0x4a, 0x7e, 0xf8, 0xbf, 0x01,
+	0x00, 0xae, 0x1d, 0xa3, 0xbb, 0x8d, 0x17, 0x00, 0x00,
 }
@@ -2,13 +2,17 @@ Feature: add user notification
     Scenario: postive
         Given all test data is cleared
         Given the users table has data
-            | phone_number | name | birthday   | hashword                                                                         | verified | created                    | updated                    |
-            | 0005551234   | mike | 1989-07-04 | JDJhJDEwJERodnJnR2t1Y1AuaWJwazdTQUZPR2V1R2FoS2ljemFWT2UzZkpndkMxTmFRaVNaaU00Zm5x | 1        | 2018-01-30 03:49:55.971300 | 2018-01-30 03:49:55.971300 |
+            | phone_number | name | birthday   | hashword                                                                         | verified | role  | created                    | updated                    |
+            | +0005551234  | mike | 1989-07-04 | JDJhJDEwJERodnJnR2t1Y1AuaWJwazdTQUZPR2V1R2FoS2ljemFWT2UzZkpndkMxTmFRaVNaaU00Zm5x | 1        | admin | 2018-01-30 03:49:55.971300 | 2018-01-30 03:49:55.971300 |
+        Given the api_tokens table has data
+            | token_id                             | phone_number | name | token_hash                                                       |
+            | 5b0c1e6e-53ba-4c3b-9f4e-2f3f1b1d7a10 | +0005551234  | test | c9e4fd9156d96d97b3715c51bf81968274e456fc0f753ecfdfb54c7e19f416aa |
+        Given we use the api token "0005551234-test-token"
         When we issue an http POST to "%(base)s/twirp/notify.NotifyApp/AddUserNotification" with data
         """
         {
             "notification_id": "7b1ced70-a2a0-40c5-8aa5-1cc5cff3b04b",
-            "phone_number": "0005551234",
+            "phone_number": "+0005551234",
             "frequency": "24h",
             "next_notification_time": "2018-01-29 20:30:00"
         }
@@ -19,7 +23,42 @@ Feature: add user notification
         And the most recent communications row has data like
         """
         {
-            "to_phone": "0005551234",
+            "to_phone": "+0005551234",
             "message": "What did you have for lunch?"
         }
         """
+
+    Scenario: missing api token
+        Given all test data is cleared
+        When we issue an http POST to "%(base)s/twirp/notify.NotifyApp/AddUserNotification" with data
+        """
+        {
+            "notification_id": "7b1ced70-a2a0-40c5-8aa5-1cc5cff3b04b",
+            "phone_number": "+0005551234",
+            "frequency": "24h",
+            "next_notification_time": "2018-01-29 20:30:00"
+        }
+        """
+        Then we receive an http 401
+
+    Scenario: another user's number
+        Given all test data is cleared
+        Given the users table has data
+            | phone_number | name | birthday   | hashword                                                                         | verified | role | created                    | updated                    |
+            | +0005551234  | mike | 1989-07-04 | JDJhJDEwJERodnJnR2t1Y1AuaWJwazdTQUZPR2V1R2FoS2ljemFWT2UzZkpndkMxTmFRaVNaaU00Zm5x | 1        | user | 2018-01-30 03:49:55.971300 | 2018-01-30 03:49:55.971300 |
+        Given the api_tokens table has data
+            | token_id                             | phone_number | name | token_hash                                                       |
+            | 5b0c1e6e-53ba-4c3b-9f4e-2f3f1b1d7a10 | +0005551234  | test | c9e4fd9156d96d97b3715c51bf81968274e456fc0f753ecfdfb54c7e19f416aa |
+        Given we use the api token "0005551234-test-token"
+        When we issue an http POST to "%(base)s/twirp/notify.NotifyApp/AddUserNotification" with data
+        """
+        {
+            "notification_id": "7b1ced70-a2a0-40c5-8aa5-1cc5cff3b04b",
+            "phone_number": "+0005559999",
+            "frequency": "24h",
+            "next_notification_time": "2018-01-29 20:30:00"
+        }
+        """
+        Then we receive an http 403
+        When we issue an http POST to "%(base)s/twirp/notify.NotifyApp/TriggerNotifications"
+        Then we receive an http 403
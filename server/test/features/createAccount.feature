@@ -5,7 +5,7 @@ Feature: create and register account
         """
         {
           "user": {
-            "phone_number": "0004451322",
+            "phone_number": "+0004451322",
             "password": "abcdef",
             "name": "mike",
             "birthday": "1989-07-04"
@@ -40,6 +40,8 @@ Feature: create and register account
         Examples:
             | phone_number  | password | name | birthday   | repeat |
             | 1             | abcdef   | mike | 1989-07-04 | abcdef |
+            | 5551234       | abcdef   | mike | 1989-07-04 | abcdef |
+            | +44123        | abcdef   | mike | 1989-07-04 | abcdef |
             | 0004254451322 |          | mike | 1989-07-04 | abcdef |
             | 0004254451322 | abcdef   |      | 1989-07-04 | abcdef |
             | 0004251322    | abcdef   | mike | 19890704   | abcdef |
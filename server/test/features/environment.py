@@ -4,7 +4,9 @@ import MySQLdb
 def before_all(ctx):
     ctx.debug = ctx.config.userdata.getbool("DEBUG")
     ctx.config = {
-        "base": "http://localhost:8080"
+        "base": "http://localhost:8080",
+        "metrics": "http://localhost:9090",
+        "twilio_token": os.popen('cat /etc/secrets/twilio.json | grep password | cut -d\'"\' -f4').read().strip(),
     }
     config = {
         'user': 'dbuser',
@@ -0,0 +1,62 @@
+Feature: journal api
+    Scenario: postive
+        Given all test data is cleared
+        Given the users table has data
+            | phone_number | name | birthday   | hashword                                                                         | verified | role | created                    | updated                    |
+            | +0005551234  | mike | 1989-07-04 | JDJhJDEwJERodnJnR2t1Y1AuaWJwazdTQUZPR2V1R2FoS2ljemFWT2UzZkpndkMxTmFRaVNaaU00Zm5x | 1        | user | 2018-01-30 03:49:55.971300 | 2018-01-30 03:49:55.971300 |
+        Given the api_tokens table has data
+            | token_id                             | phone_number | name | token_hash                                                       |
+            | 5b0c1e6e-53ba-4c3b-9f4e-2f3f1b1d7a10 | +0005551234  | test | c9e4fd9156d96d97b3715c51bf81968274e456fc0f753ecfdfb54c7e19f416aa |
+        Given the journals table has data
+            | journal_id                           | comms_id | phone_number | title | entry       | created                    | updated                    |
+            | 9a7f3c52-0d1b-4f4e-8a61-3b2c1d0e9f87 |          | +0005551234  | lunch | a sandwich  | 2018-01-30 03:49:55.971300 | 2018-01-30 03:49:55.971300 |
+        Given we use the api token "0005551234-test-token"
+        When we issue an http POST to "%(base)s/twirp/notify.NotifyApp/GetJournal" with data
+        """
+        {"journal_id": "9a7f3c52-0d1b-4f4e-8a61-3b2c1d0e9f87"}
+        """
+        Then we receive an http 200 with data
+        """
+        {"title": "lunch", "entry": "a sandwich"}
+        """
+        When we issue an http POST to "%(base)s/twirp/notify.NotifyApp/UpdateJournal" with data
+        """
+        {"journal_id": "9a7f3c52-0d1b-4f4e-8a61-3b2c1d0e9f87", "entry": "a burrito"}
+        """
+        Then we receive an http 200 with data
+        """
+        {"entry": "a burrito"}
+        """
+        When we issue an http POST to "%(base)s/twirp/notify.NotifyApp/CreateJournal" with data
+        """
+        {"title": "dinner", "entry": "pasta"}
+        """
+        Then we receive an http 200 with data
+        """
+        {"phone_number": "+0005551234", "title": "dinner", "entry": "pasta"}
+        """
+        When we issue an http POST to "%(base)s/twirp/notify.NotifyApp/DeleteJournal" with data
+        """
+        {"journal_id": "9a7f3c52-0d1b-4f4e-8a61-3b2c1d0e9f87"}
+        """
+        Then we receive an http 200
+        When we issue an http POST to "%(base)s/twirp/notify.NotifyApp/GetJournal" with data
+        """
+        {"journal_id": "9a7f3c52-0d1b-4f4e-8a61-3b2c1d0e9f87"}
+        """
+        Then we receive an http 404
+
+    Scenario: another user's journal
+        Given all test data is cleared
+        Given the users table has data
+            | phone_number | name | birthday   | hashword                                                                         | verified | role | created                    | updated                    |
+            | +0005551234  | mike | 1989-07-04 | JDJhJDEwJERodnJnR2t1Y1AuaWJwazdTQUZPR2V1R2FoS2ljemFWT2UzZkpndkMxTmFRaVNaaU00Zm5x | 1        | user | 2018-01-30 03:49:55.971300 | 2018-01-30 03:49:55.971300 |
+        Given the api_tokens table has data
+            | token_id                             | phone_number | name | token_hash                                                       |
+            | 5b0c1e6e-53ba-4c3b-9f4e-2f3f1b1d7a10 | +0005551234  | test | c9e4fd9156d96d97b3715c51bf81968274e456fc0f753ecfdfb54c7e19f416aa |
+        Given we use the api token "0005551234-test-token"
+        When we issue an http POST to "%(base)s/twirp/notify.NotifyApp/ListJournals" with data
+        """
+        {"phone_number": "+0005559999"}
+        """
+        Then we receive an http 403
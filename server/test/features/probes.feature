@@ -0,0 +1,23 @@
+Feature: probes
+    Scenario: healthz
+        When we issue an http GET to "%(base)s/healthz"
+        Then we receive an http 200
+
+    Scenario: readyz
+        When we issue an http GET to "%(base)s/readyz"
+        Then we receive an http 200 with data
+        """
+        {"ready": true, "checks": {"store": "ok", "templates": "ok", "twilio": "ok"}}
+        """
+
+    Scenario: operator endpoints are off the public listener
+        When we issue an http GET to "%(base)s/metrics"
+        Then we receive an http 404
+
+    Scenario: scheduler status
+        When we issue an http GET to "%(metrics)s/status/scheduler"
+        Then we receive an http 200
+
+    Scenario: metrics
+        When we issue an http GET to "%(metrics)s/metrics"
+        Then we receive an http 200
@@ -2,13 +2,17 @@ Feature: respond to prompt
     Scenario: postive
         Given all test data is cleared
         Given the users table has data
-            | phone_number | name | birthday   | hashword                                                                         | verified | created                    | updated                    |
-            | 0005551234   | mike | 1989-07-04 | JDJhJDEwJERodnJnR2t1Y1AuaWJwazdTQUZPR2V1R2FoS2ljemFWT2UzZkpndkMxTmFRaVNaaU00Zm5x | 1        | 2018-01-30 03:49:55.971300 | 2018-01-30 03:49:55.971300 |
+            | phone_number | name | birthday   | hashword                                                                         | verified | role  | created                    | updated                    |
+            | +0005551234  | mike | 1989-07-04 | JDJhJDEwJERodnJnR2t1Y1AuaWJwazdTQUZPR2V1R2FoS2ljemFWT2UzZkpndkMxTmFRaVNaaU00Zm5x | 1        | admin | 2018-01-30 03:49:55.971300 | 2018-01-30 03:49:55.971300 |
+        Given the api_tokens table has data
+            | token_id                             | phone_number | name | token_hash                                                       |
+            | 5b0c1e6e-53ba-4c3b-9f4e-2f3f1b1d7a10 | +0005551234  | test | c9e4fd9156d96d97b3715c51bf81968274e456fc0f753ecfdfb54c7e19f416aa |
+        Given we use the api token "0005551234-test-token"
         When we issue an http POST to "%(base)s/twirp/notify.NotifyApp/AddUserNotification" with data
         """
         {
             "notification_id": "7b1ced70-a2a0-40c5-8aa5-1cc5cff3b04b",
-            "phone_number": "0005551234",
+            "phone_number": "+0005551234",
             "frequency": "24h",
             "next_notification_time": "2018-01-29 20:30:00"
         }
@@ -19,19 +23,26 @@ Feature: respond to prompt
         And the most recent communications row has data like
         """
         {
-            "to_phone": "0005551234",
+            "to_phone": "+0005551234",
             "message": "What did you have for lunch?"
         }
         """
         When we send a text message to the server
             | from       | message     |
-            | 0005551234 | hello world |
+            | +0005551234 | hello world |
         Then we receive an http 200
+        And the most recent communications row has data like
+        """
+        {
+            "from_phone": "+0005551234",
+            "message": "enc:v2:"
+        }
+        """
         And the most recent journals row has data like
         """
         {
-            "phone_number": "0005551234",
-            "notification": "What did you have for lunch?",
-            "entry": "hello world"
+            "phone_number": "+0005551234",
+            "title": "enc:v2:",
+            "entry": "enc:v2:"
         }
         """
@@ -1,4 +1,7 @@
 from behave import *
+import base64
+import hashlib
+import hmac
 import json
 import requests
 
@@ -10,22 +13,31 @@ def wanthave(a, b):
 @step("all test data is cleared")
 def clear_test_data(ctx):
     cursor = ctx.db.cursor()
-    stmt = "DELETE FROM users WHERE phone_number LIKE '000%'"
+    stmt = "DELETE FROM users WHERE phone_number LIKE '+000%'"
     cursor.execute(stmt)
-    stmt = "DELETE FROM user_notifications WHERE phone_number LIKE '000%'"
+    stmt = "DELETE FROM user_notifications WHERE phone_number LIKE '+000%'"
     cursor.execute(stmt)
-    stmt = "DELETE FROM communications WHERE to_phone LIKE '000%'"
+    stmt = "DELETE FROM communications WHERE to_phone LIKE '+000%'"
     cursor.execute(stmt)
-    stmt = "DELETE FROM communications WHERE from_phone LIKE '000%'"
+    stmt = "DELETE FROM communications WHERE from_phone LIKE '+000%'"
     cursor.execute(stmt)
-    stmt = "DELETE FROM journals WHERE phone_number LIKE '000%'"
+    stmt = "DELETE FROM journals WHERE phone_number LIKE '+000%'"
     cursor.execute(stmt)
+    stmt = "DELETE FROM journal_terms WHERE phone_number LIKE '+000%'"
+    cursor.execute(stmt)
+    stmt = "DELETE FROM api_tokens WHERE phone_number LIKE '+000%'"
+    cursor.execute(stmt)
+    stmt = "DELETE FROM user_keys WHERE phone_number LIKE '+000%'"
+    cursor.execute(stmt)
+    ctx.token = None
     ctx.db.commit()
 
 @step('we issue an http (.*) to "(.*)"')
 @step('we issue an http (.*) to "(.*)" with data')
 def issue_api_call(ctx, method, url):
     headers={"Content-Type": "application/json"}
+    if getattr(ctx, "token", None):
+        headers["Authorization"] = "Bearer %s"%ctx.token
 
     payload = '{}'
     if ctx.text:
@@ -45,6 +57,10 @@ def issue_api_call(ctx, method, url):
         )
     else:
         raise Exception("method not supported")
+@step('we use the api token "(.*)"')
+def use_api_token(ctx, token):
+    ctx.token = token
+
 @step("we send a text message to the server")
 def text_server(ctx):
     payload = {
@@ -52,11 +68,18 @@ def text_server(ctx):
         "Body": ctx.table[0]["message"]
     }
 
+    url = "%(base)s/twilio"%ctx.config
     ctx.resp = requests.post(
-        "%(base)s/twilio"%ctx.config,
-        data=payload
+        url,
+        data=payload,
+        headers={"X-Twilio-Signature": twilio_signature(ctx.config["twilio_token"], url, payload)}
     )
 
+def twilio_signature(token, url, params):
+    signed = url + "".join(k + params[k] for k in sorted(params))
+    mac = hmac.new(token.encode(), signed.encode(), hashlib.sha1)
+    return base64.b64encode(mac.digest()).decode()
+
 @step("we receive an http (.*)")
 @step("we receive an http (.*) with data")
 def check_response(ctx, code):
@@ -83,7 +106,7 @@ def insert_db_data(ctx, table):
 def check_db_data(ctx, table):
     table_keys = {
         "communications": ["comms_id", "from_phone", "to_phone", "message", "created"],
-        "journals": ["journal_id", "comms_id", "phone_number", "notification", "entry", "created", "updated"],
+        "journals": ["journal_id", "comms_id", "phone_number", "title", "entry", "created", "updated"],
     }
     want = json.loads(ctx.text)
     stmt = "SELECT %s FROM %s ORDER BY created DESC LIMIT 1"%(",".join(table_keys[table]), table)